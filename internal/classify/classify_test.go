@@ -14,6 +14,7 @@
 package classify
 
 import (
+	"crypto/sha256"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -158,3 +159,49 @@ func TestProcessFile_RegroupEnabled(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// rewritingHashFilter hashes the file then changes its content, so that a
+// second hash computation would give a different result.
+type rewritingHashFilter struct {
+	path   string
+	hashes *[][sha256.Size]byte
+}
+
+func (f *rewritingHashFilter) Match(ctx filter.Context) (bool, error) {
+	h, err := ctx.Hash()
+	if err != nil {
+		return false, err
+	}
+	*f.hashes = append(*f.hashes, h)
+	return false, os.WriteFile(f.path, []byte("changed"), 0o644)
+}
+
+func (f *rewritingHashFilter) Selector() string                        { return "rewriting_hash" }
+func (f *rewritingHashFilter) LoadConfig(map[string]interface{}) error { return nil }
+
+func TestProcessFile_HashSharedAcrossDestinations(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "src", "a.txt")
+	writeFile(t, src)
+
+	var hashes [][sha256.Size]byte
+	var dests []config.DestDir
+	for _, name := range []string{"first", "second"} {
+		dests = append(dests, config.DestDir{
+			Path:     filepath.Join(tmp, name),
+			Filters:  []filter.Filter{&rewritingHashFilter{path: src, hashes: &hashes}},
+			Strategy: &mockStrategy{dest: filepath.Join(tmp, name)},
+		})
+	}
+	c := &Classifier{cfg: config.Config{DestDirs: dests}, stats: &stats.Stats{}}
+
+	if err := c.processFile(filepath.Dir(src), src); err != nil {
+		t.Fatal(err)
+	}
+	if len(hashes) != 2 {
+		t.Fatalf("expected both destinations to hash the file, got %d hashes", len(hashes))
+	}
+	if hashes[0] != hashes[1] {
+		t.Fatal("the file was hashed again for the second destination")
+	}
+}
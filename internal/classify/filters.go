@@ -21,16 +21,25 @@ import (
 	"github.com/polocto/FolderFlow/pkg/ffplugin/filter"
 )
 
-// matchFile checks if a file matches all the rules in DestDir.
-func matchFile(file filehandler.Context, filters []filter.Filter) (bool, error) {
+// matchFile checks if a file matches all the rules in DestDir, and returns
+// the attributes the filters attached to the match.
+// onInvalid, when not nil, receives the failures reported by validation filters.
+func matchFile(
+	file filehandler.Context,
+	filters []filter.Filter,
+	onInvalid internalfilter.InvalidFunc,
+) (bool, map[string]string, error) {
 	// If no filters are provided, match all files
 	if len(filters) == 0 {
-		return true, nil
+		return true, nil, nil
 	}
 
 	ctx, err := internalfilter.NewContextFilter(file)
 	if err != nil {
-		return false, err
+		return false, nil, err
+	}
+	if onInvalid != nil {
+		internalfilter.OnInvalid(ctx, onInvalid)
 	}
 
 	// Run all filters
@@ -38,22 +47,29 @@ func matchFile(file filehandler.Context, filters []filter.Filter) (bool, error)
 		matched, err := f.Match(ctx)
 		if err != nil {
 			slog.Error("Filter error", "filter", f.Selector(), "path", file.Path(), "err", err)
-			return false, err
+			return false, nil, err
 		}
 		if !matched {
-			return false, nil
+			return false, nil, nil
 		}
 	}
-	slog.Debug("File matched", "path", file.Path(), "filers", filters)
-	return true, nil
+	attrs := internalfilter.Attributes(ctx)
+	slog.Debug("File matched", "path", file.Path(), "filers", filters, "attributes", attrs)
+	return true, attrs, nil
 }
 
-func (c *Classifier) runFilters(path filehandler.Context, filters []filter.Filter) (bool, error) {
-	var ok bool
-	err := c.safeRun("filters", func() error {
+func (c *Classifier) runFilters(
+	path filehandler.Context,
+	filters []filter.Filter,
+) (ok bool, attrs map[string]string, err error) {
+	err = c.safeRun("filters", func() error {
 		var err error
-		ok, err = matchFile(path, filters)
+		ok, attrs, err = matchFile(path, filters, func(format, reason string) {
+			if c.stats.FileInvalid(path.Path(), format, reason) {
+				slog.Warn("File failed validation", "path", path.Path(), "format", format, "reason", reason)
+			}
+		})
 		return err
 	})
-	return ok, err
+	return ok, attrs, err
 }
@@ -49,7 +49,7 @@ func createContextFile(t *testing.T, content []byte) filehandler.Context {
 func TestMatchFile_NoFilters(t *testing.T) {
 	ctx := createContextFile(t, []byte("Hello"))
 
-	ok, err := matchFile(ctx, nil)
+	ok, _, err := matchFile(ctx, nil, nil)
 	require.NoError(t, err)
 	require.True(t, ok)
 }
@@ -58,7 +58,7 @@ func TestMatchFile_FilterRejects(t *testing.T) {
 	ctx := createContextFile(t, []byte("Hello"))
 
 	mf := &mockFilter{match: false}
-	ok, err := matchFile(ctx, []filter.Filter{mf})
+	ok, _, err := matchFile(ctx, []filter.Filter{mf}, nil)
 	require.NoError(t, err)
 	require.False(t, ok)
 }
@@ -67,7 +67,7 @@ func TestMatchFile_SingleFilterMatch(t *testing.T) {
 	ctx := createContextFile(t, []byte("Hello"))
 
 	f := &mockFilter{match: true}
-	ok, err := matchFile(ctx, []filter.Filter{f})
+	ok, _, err := matchFile(ctx, []filter.Filter{f}, nil)
 	require.NoError(t, err)
 	require.True(t, ok)
 }
@@ -79,7 +79,7 @@ func TestMatchFile_MultipleFilters_AllMatch(t *testing.T) {
 		&mockFilter{match: true},
 		&mockFilter{match: true},
 	}
-	ok, err := matchFile(ctx, filters)
+	ok, _, err := matchFile(ctx, filters, nil)
 	require.NoError(t, err)
 	require.True(t, ok)
 }
@@ -93,7 +93,7 @@ func TestMatchFile_StopsOnFirstNoMatch(t *testing.T) {
 		&mockFilter{match: true, called: &called2},
 	}
 
-	ok, err := matchFile(ctx, filters)
+	ok, _, err := matchFile(ctx, filters, nil)
 	require.NoError(t, err)
 	require.False(t, ok)
 	require.Equal(t, 1, called1)
@@ -106,7 +106,7 @@ func TestMatchFile_FilterError(t *testing.T) {
 	expectedErr := errors.New("filter error")
 	f := &mockFilter{err: expectedErr}
 
-	ok, err := matchFile(ctx, []filter.Filter{f})
+	ok, _, err := matchFile(ctx, []filter.Filter{f}, nil)
 	require.ErrorIs(t, err, expectedErr)
 	require.False(t, ok)
 }
@@ -121,8 +121,34 @@ func TestMatchFile_StopsOnError(t *testing.T) {
 		&mockFilter{match: true, called: &called},
 	}
 
-	ok, err := matchFile(ctx, filters)
+	ok, _, err := matchFile(ctx, filters, nil)
 	require.ErrorIs(t, err, expectedErr)
 	require.False(t, ok)
 	require.Equal(t, 0, called)
 }
+
+// attributeFilter sets an attribute on the files it matches.
+type attributeFilter struct {
+	name, value string
+}
+
+func (f *attributeFilter) Match(ctx filter.Context) (bool, error) {
+	ctx.SetAttribute(f.name, f.value)
+	return true, nil
+}
+
+func (f *attributeFilter) Selector() string                        { return "attribute" }
+func (f *attributeFilter) LoadConfig(map[string]interface{}) error { return nil }
+
+func TestMatchFile_ReturnsAttributes(t *testing.T) {
+	ctx := createContextFile(t, []byte("Hello"))
+
+	filters := []filter.Filter{
+		&attributeFilter{name: "regex.artist", value: "Queen"},
+		&attributeFilter{name: "exif.make", value: "Canon"},
+	}
+	ok, attrs, err := matchFile(ctx, filters, nil)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, map[string]string{"regex.artist": "Queen", "exif.make": "Canon"}, attrs)
+}
@@ -55,7 +55,7 @@ func TestDestPath_Success(t *testing.T) {
 		dest: filepath.Join(destDir, "subdir", "file.txt"),
 	}
 
-	out, err := destPath(fhCtx, srcDir, destDir, mockStrat)
+	out, err := destPath(fhCtx, srcDir, destDir, mockStrat, nil)
 	require.NoError(t, err)
 
 	expected := filepath.Join(destDir, "subdir", "file.txt")
@@ -72,7 +72,7 @@ func TestDestPath_StrategyError(t *testing.T) {
 
 	mockStrat := &mockStrategy{err: errors.New("boom")}
 
-	_, err = destPath(fhCtx, srcDir, destDir, mockStrat)
+	_, err = destPath(fhCtx, srcDir, destDir, mockStrat, nil)
 	require.Error(t, err)
 }
 
@@ -86,7 +86,7 @@ func TestDestPath_OutsideDestination(t *testing.T) {
 
 	mockStrat := &mockStrategy{dest: filepath.Join("/evil", "file.txt")}
 
-	_, err = destPath(fhCtx, srcDir, destDir, mockStrat)
+	_, err = destPath(fhCtx, srcDir, destDir, mockStrat, nil)
 	require.Error(t, err)
 }
 
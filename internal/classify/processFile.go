@@ -22,6 +22,12 @@ import (
 
 func (c *Classifier) processFile(sourceDir, filePath string) error {
 	defer c.stats.Time(&c.stats.Timing.Classify)()
+	// The context is shared by all destinations so that cached data such as
+	// the hash is computed at most once per file
+	file, err := filehandler.NewContextFile(filePath)
+	if err != nil {
+		return err
+	}
 	for _, dest := range c.cfg.DestDirs {
 		if sourceDir == dest.Path {
 			slog.Warn(
@@ -33,20 +39,15 @@ func (c *Classifier) processFile(sourceDir, filePath string) error {
 			)
 			continue
 		}
-		file, err := filehandler.NewContextFile(filePath)
-		if err != nil {
-			return err
-		}
-
 		// Check if file matches all filters for this DestDir
-		ok, err := c.runFilters(file, dest.Filters)
+		ok, attrs, err := c.runFilters(file, dest.Filters)
 		if err != nil || !ok {
 			continue
 		}
 		// File matched all filters for this DestDir
 		c.stats.FileMatched()
 
-		destinationPath, err := c.runStartegy(file, sourceDir, dest.Path, dest.Strategy)
+		destinationPath, err := c.runStartegy(file, sourceDir, dest.Path, dest.Strategy, attrs)
 		if err != nil {
 			return err
 		}
@@ -59,6 +60,7 @@ func (c *Classifier) processFile(sourceDir, filePath string) error {
 				sourceDir,
 				c.cfg.Regroup.Path,
 				c.cfg.Regroup.Strategy,
+				attrs,
 			)
 			if err != nil {
 				c.stats.Error(err)
@@ -25,6 +25,7 @@ func destPath(
 	file filehandler.Context,
 	sourceDir, destDir string,
 	strat strategy.Strategy,
+	attrs map[string]string,
 ) (string, error) {
 	ctx, err := internalstrategy.NewContextStrategy(file, sourceDir, destDir)
 	if err != nil {
@@ -35,6 +36,7 @@ func destPath(
 			err,
 		)
 	}
+	internalstrategy.SetAttributes(ctx, attrs)
 
 	finalDst, err := strat.FinalDirPath(ctx)
 	if err != nil {
@@ -60,9 +62,10 @@ func (c *Classifier) runStartegy(
 	file filehandler.Context,
 	sourceDir, destDir string,
 	strat strategy.Strategy,
+	attrs map[string]string,
 ) (finalDst string, err error) {
 	err = c.safeRun("strategy", func() (err error) {
-		finalDst, err = destPath(file, sourceDir, destDir, strat)
+		finalDst, err = destPath(file, sourceDir, destDir, strat, attrs)
 		return err
 	})
 	return finalDst, err
@@ -0,0 +1,25 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filehandler
+
+import "time"
+
+// BirthTime returns the creation time of the file when the platform and the
+// filesystem record it. Copies usually get a new birth time.
+func BirthTime(c Context) (time.Time, bool) {
+	if c.IsDeleted() {
+		panic("use of deleted ContextFile")
+	}
+	return birthTime(c.Path(), c)
+}
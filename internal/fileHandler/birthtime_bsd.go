@@ -0,0 +1,33 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//go:build darwin || freebsd || netbsd
+
+package filehandler
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+func birthTime(_ string, info fs.FileInfo) (time.Time, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, false
+	}
+	sec, nsec := st.Birthtimespec.Unix()
+	if sec <= 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(sec, nsec), true
+}
@@ -0,0 +1,25 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+//go:build !linux && !darwin && !freebsd && !netbsd && !windows
+
+package filehandler
+
+import (
+	"io/fs"
+	"time"
+)
+
+// birthTime is not supported: the platform does not expose a creation time.
+func birthTime(_ string, _ fs.FileInfo) (time.Time, bool) {
+	return time.Time{}, false
+}
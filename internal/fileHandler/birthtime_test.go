@@ -0,0 +1,38 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filehandler_test
+
+import (
+	"testing"
+	"time"
+
+	filehandler "github.com/polocto/FolderFlow/internal/fileHandler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBirthTime(t *testing.T) {
+	before := time.Now().Add(-time.Minute)
+	path := tempFile(t, t.TempDir(), "file.txt", []byte(helloWorld()))
+
+	ctx, err := filehandler.NewContextFile(path)
+	require.NoError(t, err)
+
+	born, ok := filehandler.BirthTime(ctx)
+	if !ok {
+		t.Skip("birth time not recorded by this filesystem")
+	}
+	assert.True(t, born.After(before), "birth time %v", born)
+	assert.True(t, born.Before(time.Now().Add(time.Minute)), "birth time %v", born)
+}
@@ -0,0 +1,354 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/polocto/FolderFlow/pkg/ffplugin/filter"
+)
+
+// Archive formats recognized by the archive filter.
+const (
+	archiveFormatZip   = "zip"
+	archiveFormatTar   = "tar"
+	archiveFormatTarGz = "tar.gz"
+)
+
+// defaultArchiveMaxRatio is the default uncompressed to compressed size ratio
+// above which an archive is considered a zip bomb.
+const defaultArchiveMaxRatio = 100
+
+var (
+	errNotArchive  = errors.New("not an archive")
+	errArchiveBomb = errors.New("compression ratio above the limit")
+)
+
+// ArchiveFilter matches ZIP and tar archives (optionally gzip compressed) on
+// the entries they contain. Entries are listed, never extracted.
+type ArchiveFilter struct {
+	Formats    []string `yaml:"formats"`
+	AnyOf      []string `yaml:"any_of"`
+	AllOf      []string `yaml:"all_of"`
+	Regex      bool     `yaml:"regex"`
+	MinEntries int      `yaml:"min_entries"`
+	MaxEntries int      `yaml:"max_entries"`
+	MinSize    int64    `yaml:"min_size"`
+	MaxSize    int64    `yaml:"max_size"`
+	Encrypted  *bool    `yaml:"encrypted"`
+	// MaxRatio is the uncompressed to compressed size ratio above which archives are refused
+	MaxRatio float64 `yaml:"max_ratio"`
+
+	anyOf []entryMatcher
+	allOf []entryMatcher
+}
+
+// entryMatcher matches the path of an archive entry.
+type entryMatcher func(name string) bool
+
+// archiveScan accumulates what is known about the entries of an archive.
+type archiveScan struct {
+	filter    *ArchiveFilter
+	format    string
+	entries   int
+	size      int64
+	encrypted bool
+	foundAny  bool
+	foundAll  []bool
+}
+
+func (f *ArchiveFilter) Match(ctx filter.Context) (bool, error) {
+	if ctx == nil {
+		return false, fmt.Errorf("context is nil")
+	}
+	if !ctx.Info().Mode().IsRegular() {
+		return false, nil
+	}
+
+	scan := &archiveScan{
+		filter:   f,
+		foundAny: len(f.anyOf) == 0,
+		foundAll: make([]bool, len(f.allOf)),
+	}
+	var listErr error
+	err := ctx.WithInput(func(r io.Reader) error {
+		ra, size, err := readerAt(r, ctx.Size())
+		if err != nil {
+			return err
+		}
+		listErr = scan.list(ra, size, ctx.BaseName())
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("cannot read archive %q: %w", ctx.BaseName(), err)
+	}
+
+	switch {
+	case errors.Is(listErr, errNotArchive):
+		return false, nil
+	case errors.Is(listErr, errArchiveBomb):
+		slog.Warn("Archive refused, possible zip bomb", "basename", ctx.BaseName(), "err", listErr)
+		return false, nil
+	case listErr != nil:
+		// Corrupt archives are selected with the valid filter
+		slog.Debug("Cannot list archive", "basename", ctx.BaseName(), "err", listErr)
+		return false, nil
+	}
+	return scan.matched(), nil
+}
+
+// list detects the archive format and adds every file entry to the scan.
+func (s *archiveScan) list(ra io.ReaderAt, size int64, name string) error {
+	head := make([]byte, 512)
+	n, _ := ra.ReadAt(head, 0)
+	head = head[:n]
+
+	lower := strings.ToLower(name)
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")), bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		s.format = archiveFormatZip
+	case isTarHeader(head), strings.HasSuffix(lower, ".tar"):
+		s.format = archiveFormatTar
+	case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+		s.format = archiveFormatTarGz
+	default:
+		return errNotArchive
+	}
+	if len(s.filter.Formats) > 0 && !slices.Contains(s.filter.Formats, s.format) {
+		return errNotArchive
+	}
+
+	switch s.format {
+	case archiveFormatZip:
+		return s.listZip(ra, size)
+	case archiveFormatTar:
+		return s.listTar(io.NewSectionReader(ra, 0, size))
+	default:
+		return s.listTarGz(io.NewSectionReader(ra, 0, size), size, lower)
+	}
+}
+
+// listZip reads the central directory. The declared sizes are checked against
+// the ratio limit before anything is decompressed.
+func (s *archiveScan) listZip(ra io.ReaderAt, size int64) error {
+	archive, err := zip.NewReader(ra, size)
+	if err != nil {
+		return err
+	}
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		s.add(file.Name, int64(file.UncompressedSize64), file.Flags&0x1 != 0)
+		if err := s.checkRatio(size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listTar reads the headers; entry data is skipped by seeking.
+func (s *archiveScan) listTar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag == tar.TypeReg {
+			s.add(header.Name, header.Size, false)
+		}
+	}
+}
+
+// listTarGz decompresses the stream to read the tar headers, refusing
+// archives that expand beyond the ratio limit.
+func (s *archiveScan) listTarGz(r io.Reader, size int64, name string) error {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = zr.Close() }()
+
+	limit := int64(s.filter.maxRatio() * float64(max(size, 1)))
+	counter := &countingReader{r: zr, limit: limit}
+	br := bufio.NewReader(counter)
+	head, _ := br.Peek(512)
+	if !isTarHeader(head) && !strings.HasSuffix(name, ".tar.gz") && !strings.HasSuffix(name, ".tgz") {
+		// A single compressed file
+		return errNotArchive
+	}
+	return s.listTar(br)
+}
+
+// countingReader fails once more than limit bytes were read.
+type countingReader struct {
+	r     io.Reader
+	read  int64
+	limit int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += int64(n)
+	if c.read > c.limit {
+		return n, fmt.Errorf("%w: more than %d bytes decompressed", errArchiveBomb, c.limit)
+	}
+	return n, err
+}
+
+func (s *archiveScan) checkRatio(size int64) error {
+	if ratio := float64(s.size) / float64(max(size, 1)); ratio > s.filter.maxRatio() {
+		return fmt.Errorf("%w: %.0f:1", errArchiveBomb, ratio)
+	}
+	return nil
+}
+
+func (s *archiveScan) add(name string, size int64, encrypted bool) {
+	s.entries++
+	s.size += size
+	s.encrypted = s.encrypted || encrypted
+
+	for i, m := range s.filter.allOf {
+		if !s.foundAll[i] && m(name) {
+			s.foundAll[i] = true
+		}
+	}
+	if !s.foundAny {
+		s.foundAny = slices.ContainsFunc(s.filter.anyOf, func(m entryMatcher) bool { return m(name) })
+	}
+}
+
+func (s *archiveScan) matched() bool {
+	f := s.filter
+	if !s.foundAny || slices.Contains(s.foundAll, false) {
+		return false
+	}
+	if !inIntRange(s.entries, f.MinEntries, f.MaxEntries) {
+		return false
+	}
+	if (f.MinSize > 0 && s.size < f.MinSize) || (f.MaxSize > 0 && s.size > f.MaxSize) {
+		return false
+	}
+	return f.Encrypted == nil || *f.Encrypted == s.encrypted
+}
+
+func (f *ArchiveFilter) maxRatio() float64 {
+	if f.MaxRatio > 0 {
+		return f.MaxRatio
+	}
+	return defaultArchiveMaxRatio
+}
+
+// newEntryMatcher compiles a pattern. Glob patterns without a slash match the
+// base name of entries, in any directory; other patterns match the whole path.
+func (f *ArchiveFilter) newEntryMatcher(pattern string) (entryMatcher, error) {
+	if f.Regex {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		return re.MatchString, nil
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	if !strings.Contains(pattern, "/") {
+		return func(name string) bool {
+			ok, _ := path.Match(pattern, path.Base(name))
+			return ok
+		}, nil
+	}
+	pattern = strings.TrimPrefix(pattern, "/")
+	return func(name string) bool {
+		ok, _ := path.Match(pattern, strings.TrimPrefix(name, "./"))
+		return ok
+	}, nil
+}
+
+func (f *ArchiveFilter) Selector() string {
+	return "archive"
+}
+
+func (f *ArchiveFilter) LoadConfig(config map[string]interface{}) error {
+	var cfg ArchiveFilter
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	if cfg.MinEntries < 0 || cfg.MaxEntries < 0 || cfg.MinSize < 0 || cfg.MaxSize < 0 || cfg.MaxRatio < 0 {
+		return fmt.Errorf("archive filter limits cannot be negative")
+	}
+	if cfg.MaxEntries > 0 && cfg.MinEntries > cfg.MaxEntries {
+		return fmt.Errorf("'min_entries' is greater than 'max_entries'")
+	}
+	if cfg.MaxSize > 0 && cfg.MinSize > cfg.MaxSize {
+		return fmt.Errorf("'min_size' is greater than 'max_size'")
+	}
+
+	for i, format := range cfg.Formats {
+		switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+		case "zip":
+			cfg.Formats[i] = archiveFormatZip
+		case "tar":
+			cfg.Formats[i] = archiveFormatTar
+		case "tar.gz", "tgz":
+			cfg.Formats[i] = archiveFormatTarGz
+		default:
+			return fmt.Errorf("invalid format %q, must be one of zip, tar, tar.gz", format)
+		}
+	}
+
+	for _, set := range []struct {
+		patterns []string
+		out      *[]entryMatcher
+	}{{cfg.AnyOf, &cfg.anyOf}, {cfg.AllOf, &cfg.allOf}} {
+		for _, pattern := range set.patterns {
+			if pattern == "" {
+				return fmt.Errorf("patterns cannot be empty")
+			}
+			m, err := cfg.newEntryMatcher(pattern)
+			if err != nil {
+				return err
+			}
+			*set.out = append(*set.out, m)
+		}
+	}
+
+	*f = cfg
+
+	slog.Debug("Loading archive was successful", "config", config)
+	return nil
+}
+
+func init() {
+	filter.RegisterFilter("archive", func() filter.Filter {
+		return &ArchiveFilter{}
+	})
+}
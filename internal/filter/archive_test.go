@@ -0,0 +1,135 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type archiveEntry struct {
+	name    string
+	content []byte
+}
+
+func zipArchive(t *testing.T, encrypted bool, entries ...archiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate}
+		if encrypted {
+			header.Flags |= 0x1
+		}
+		w, err := zw.CreateHeader(header)
+		require.NoError(t, err)
+		_, err = w.Write(entry.content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func tarArchive(t *testing.T, entries ...archiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "project/", Typeflag: tar.TypeDir, Mode: 0o755}))
+	for _, entry := range entries {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: entry.name, Mode: 0o644, Size: int64(len(entry.content))}))
+		_, err := tw.Write(entry.content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func TestArchiveFilter_Match(t *testing.T) {
+	design := zipArchive(t, false,
+		archiveEntry{"assets/logo.psd", bytes.Repeat([]byte("layer"), 20)},
+		archiveEntry{"README.txt", []byte("read me")},
+	)
+	locked := zipArchive(t, true, archiveEntry{"secret.txt", []byte("secret")})
+	module := tarArchive(t,
+		archiveEntry{"project/go.mod", []byte("module example.com/project\n")},
+		archiveEntry{"project/main.go", []byte("package main\n")},
+	)
+	moduleGz := gzipFile(t, module)
+	bomb := zipArchive(t, false, archiveEntry{"zeros.bin", make([]byte, 1<<20)})
+	bombGz := gzipFile(t, tarArchive(t, archiveEntry{"zeros.bin", make([]byte, 1<<20)}))
+
+	testCases := []struct {
+		name     string
+		config   map[string]interface{}
+		basename string
+		content  []byte
+		expected bool
+	}{
+		{"zip containing psd", map[string]interface{}{"any_of": []string{"*.psd"}}, "a.zip", design, true},
+		{"zip without pdf", map[string]interface{}{"any_of": []string{"*.pdf"}}, "a.zip", design, false},
+		{"path glob", map[string]interface{}{"any_of": []string{"assets/*.psd"}}, "a.zip", design, true},
+		{"path glob other dir", map[string]interface{}{"any_of": []string{"src/*.psd"}}, "a.zip", design, false},
+		{"all of", map[string]interface{}{"all_of": []string{"*.psd", "README*"}}, "a.zip", design, true},
+		{"all of missing", map[string]interface{}{"all_of": []string{"*.psd", "*.ai"}}, "a.zip", design, false},
+		{"regex", map[string]interface{}{"any_of": []string{`(^|/)go\.mod$`}, "regex": true}, "a.tar", module, true},
+		{"tar containing go.mod", map[string]interface{}{"any_of": []string{"go.mod"}}, "a.tar", module, true},
+		{"tar.gz containing go.mod", map[string]interface{}{"any_of": []string{"go.mod"}}, "a.tar.gz", moduleGz, true},
+		{"entries", map[string]interface{}{"min_entries": 2, "max_entries": 2}, "a.tar", module, true},
+		{"directories are not counted", map[string]interface{}{"max_entries": 1}, "a.tar", module, false},
+		{"size", map[string]interface{}{"min_size": 100, "max_size": 200}, "a.zip", design, true},
+		{"too large", map[string]interface{}{"max_size": 50}, "a.zip", design, false},
+		{"encrypted", map[string]interface{}{"encrypted": true}, "a.zip", locked, true},
+		{"not encrypted", map[string]interface{}{"encrypted": false}, "a.zip", locked, false},
+		{"format", map[string]interface{}{"formats": []string{"tgz"}}, "a.tar.gz", moduleGz, true},
+		{"other format", map[string]interface{}{"formats": []string{"zip"}}, "a.tar", module, false},
+		{"zip bomb", map[string]interface{}{}, "a.zip", bomb, false},
+		{"zip bomb allowed", map[string]interface{}{"max_ratio": 10000.0}, "a.zip", bomb, true},
+		{"tar.gz bomb", map[string]interface{}{}, "a.tgz", bombGz, false},
+		{"single gzip file", map[string]interface{}{}, "a.txt.gz", gzipFile(t, []byte("text")), false},
+		{"not an archive", map[string]interface{}{}, "a.txt", []byte("plain text"), false},
+		{"corrupt zip", map[string]interface{}{}, "a.zip", design[:len(design)-10], false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &ArchiveFilter{}
+			require.NoError(t, f.LoadConfig(tc.config))
+
+			ok, err := f.Match(&mockContext{tc.content, &mockFileInfo{NameVal: tc.basename, SizeVal: int64(len(tc.content))}})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ok)
+		})
+	}
+}
+
+func TestArchiveFilter_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"any_of": []string{""}},
+		{"any_of": []string{"[a-"}},
+		{"any_of": []string{"("}, "regex": true},
+		{"formats": []string{"rar"}},
+		{"min_entries": 5, "max_entries": 1},
+		{"max_size": -1},
+		{"max_ratio": -1.0},
+	}
+	for _, config := range invalid {
+		f := &ArchiveFilter{}
+		assert.Error(t, f.LoadConfig(config), "config %v", config)
+	}
+}
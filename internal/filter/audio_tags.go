@@ -0,0 +1,236 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/polocto/FolderFlow/internal/metadata"
+	"github.com/polocto/FolderFlow/pkg/ffplugin/filter"
+)
+
+// AudioTagsFilter matches audio files on the tags they embed
+// (ID3v1/v2, FLAC and Ogg Vorbis comments, MP4 ilst atoms).
+type AudioTagsFilter struct {
+	Artist           []string `yaml:"artist"`
+	ArtistRegex      string   `yaml:"artist_regex"`
+	AlbumArtist      []string `yaml:"album_artist"`
+	AlbumArtistRegex string   `yaml:"album_artist_regex"`
+	Album            []string `yaml:"album"`
+	AlbumRegex       string   `yaml:"album_regex"`
+	Title            []string `yaml:"title"`
+	TitleRegex       string   `yaml:"title_regex"`
+	Genre            []string `yaml:"genre"`
+	GenreRegex       string   `yaml:"genre_regex"`
+	Year             int      `yaml:"year"`
+	MinYear          int      `yaml:"min_year"`
+	MaxYear          int      `yaml:"max_year"`
+	HasTrack         *bool    `yaml:"has_track"`
+	MinDuration      string   `yaml:"min_duration"`
+	MaxDuration      string   `yaml:"max_duration"`
+	Formats          []string `yaml:"formats"`
+
+	text        []textCriterion
+	minDuration time.Duration
+	maxDuration time.Duration
+}
+
+// textCriterion matches one tag value on substrings and an optional regex.
+type textCriterion struct {
+	value    func(*metadata.AudioTags) string
+	contains []string
+	re       *regexp.Regexp
+}
+
+func (c textCriterion) match(tags *metadata.AudioTags) bool {
+	value := c.value(tags)
+	if len(c.contains) > 0 && (value == "" || !containsFold(c.contains, value)) {
+		return false
+	}
+	return c.re == nil || c.re.MatchString(value)
+}
+
+func (f *AudioTagsFilter) Match(ctx filter.Context) (bool, error) {
+	if ctx == nil {
+		return false, fmt.Errorf("context is nil")
+	}
+	if ctx.IsDir() {
+		return false, nil
+	}
+
+	var tags *metadata.AudioTags
+	err := ctx.WithInput(func(r io.Reader) error {
+		var err error
+		tags, err = metadata.ReadAudioTags(r, ctx.Size())
+		return err
+	})
+	if errors.Is(err, metadata.ErrNotAudio) {
+		slog.Debug("Not an audio file", "basename", ctx.BaseName())
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("cannot read audio tags of %q: %w", ctx.BaseName(), err)
+	}
+	if !f.matchTags(tags) {
+		return false, nil
+	}
+	attrs := map[string]string{
+		"format": tags.Format, "title": tags.Title, "artist": tags.Artist, "album_artist": tags.AlbumArtist,
+		"album": tags.Album, "genre": tags.Genre,
+	}
+	if tags.Year > 0 {
+		attrs["year"] = strconv.Itoa(tags.Year)
+	}
+	if tags.Track > 0 {
+		attrs["track"] = fmt.Sprintf("%02d", tags.Track)
+	}
+	if tags.Disc > 0 {
+		attrs["disc"] = strconv.Itoa(tags.Disc)
+	}
+	setAttributes(ctx, f.Selector(), attrs)
+	return true, nil
+}
+
+func (f *AudioTagsFilter) matchTags(tags *metadata.AudioTags) bool {
+	if len(f.Formats) > 0 && !containsFormat(f.Formats, tags.Format) {
+		return false
+	}
+	for _, c := range f.text {
+		if !c.match(tags) {
+			return false
+		}
+	}
+
+	if f.Year != 0 && tags.Year != f.Year {
+		return false
+	}
+	if !inIntRange(tags.Year, f.MinYear, f.MaxYear) || (tags.Year == 0 && (f.MinYear != 0 || f.MaxYear != 0)) {
+		return false
+	}
+	if f.HasTrack != nil && *f.HasTrack != (tags.Track > 0) {
+		return false
+	}
+
+	if f.minDuration != 0 || f.maxDuration != 0 {
+		if tags.Duration == 0 {
+			return false
+		}
+		if f.minDuration != 0 && tags.Duration < f.minDuration {
+			return false
+		}
+		if f.maxDuration != 0 && tags.Duration > f.maxDuration {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFormat(formats []string, format string) bool {
+	for _, f := range formats {
+		f = strings.ToLower(strings.TrimPrefix(f, "."))
+		if f == format || (f == "m4a" && format == metadata.FormatMP4) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *AudioTagsFilter) Selector() string {
+	return "audio_tags"
+}
+
+func (f *AudioTagsFilter) LoadConfig(config map[string]interface{}) error {
+	var cfg AudioTagsFilter
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	fields := []struct {
+		name     string
+		contains []string
+		pattern  string
+		value    func(*metadata.AudioTags) string
+	}{
+		{"artist", cfg.Artist, cfg.ArtistRegex, func(t *metadata.AudioTags) string { return t.Artist }},
+		{"album_artist", cfg.AlbumArtist, cfg.AlbumArtistRegex, func(t *metadata.AudioTags) string { return t.AlbumArtist }},
+		{"album", cfg.Album, cfg.AlbumRegex, func(t *metadata.AudioTags) string { return t.Album }},
+		{"title", cfg.Title, cfg.TitleRegex, func(t *metadata.AudioTags) string { return t.Title }},
+		{"genre", cfg.Genre, cfg.GenreRegex, func(t *metadata.AudioTags) string { return t.Genre }},
+	}
+	for _, field := range fields {
+		if len(field.contains) == 0 && field.pattern == "" {
+			continue
+		}
+		c := textCriterion{value: field.value, contains: field.contains}
+		if field.pattern != "" {
+			re, err := regexp.Compile(field.pattern)
+			if err != nil {
+				return fmt.Errorf("invalid '%s_regex' pattern %q: %w", field.name, field.pattern, err)
+			}
+			c.re = re
+		}
+		cfg.text = append(cfg.text, c)
+	}
+
+	if cfg.Year < 0 || cfg.MinYear < 0 || cfg.MaxYear < 0 {
+		return fmt.Errorf("years cannot be negative")
+	}
+	if cfg.MaxYear != 0 && cfg.MinYear > cfg.MaxYear {
+		return fmt.Errorf("'min_year' (%d) is greater than 'max_year' (%d)", cfg.MinYear, cfg.MaxYear)
+	}
+
+	var err error
+	if cfg.minDuration, err = parseDuration("min_duration", cfg.MinDuration); err != nil {
+		return err
+	}
+	if cfg.maxDuration, err = parseDuration("max_duration", cfg.MaxDuration); err != nil {
+		return err
+	}
+	if cfg.maxDuration != 0 && cfg.minDuration > cfg.maxDuration {
+		return fmt.Errorf("'min_duration' (%s) is greater than 'max_duration' (%s)", cfg.minDuration, cfg.maxDuration)
+	}
+
+	*f = cfg
+
+	slog.Debug("Loading audio_tags was successful", "config", config)
+	return nil
+}
+
+// parseDuration parses an optional Go duration such as "90s" or "1h30m".
+func parseDuration(key, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid '%s' %q: %w", key, value, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("'%s' cannot be negative", key)
+	}
+	return d, nil
+}
+
+func init() {
+	filter.RegisterFilter("audio_tags", func() filter.Filter {
+		return &AudioTagsFilter{}
+	})
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/polocto/FolderFlow/internal/metadata"
+	"github.com/polocto/FolderFlow/internal/metadata/metadatatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudioTagsFilter_Match(t *testing.T) {
+	album := metadatatest.FLAC("ARTIST=Daft Punk", "ALBUM=Discovery", "DATE=2001", "TRACKNUMBER=3", "GENRE=House")
+	podcast := metadatatest.FLAC("ARTIST=Some Show", "TITLE=Episode 42", "GENRE=Podcast")
+
+	testCases := []struct {
+		name     string
+		config   map[string]interface{}
+		content  []byte
+		expected bool
+	}{
+		{"artist", map[string]interface{}{"artist": []string{"daft punk"}}, album, true},
+		{"other artist", map[string]interface{}{"artist": []string{"Justice"}}, album, false},
+		{"album regex", map[string]interface{}{"album_regex": "^Disc"}, album, true},
+		{"title regex", map[string]interface{}{"title_regex": `^Episode \d+$`}, podcast, true},
+		{"genre", map[string]interface{}{"genre": []string{"podcast"}}, album, false},
+		{"year", map[string]interface{}{"year": 2001}, album, true},
+		{"year range", map[string]interface{}{"min_year": 1990, "max_year": 1999}, album, false},
+		{"missing year", map[string]interface{}{"min_year": 1990}, podcast, false},
+		{"has track", map[string]interface{}{"has_track": true}, album, true},
+		{"no track", map[string]interface{}{"has_track": false}, podcast, true},
+		{"duration", map[string]interface{}{"min_duration": "5s", "max_duration": "1m"}, album, true},
+		{"too short", map[string]interface{}{"min_duration": "30m"}, podcast, false},
+		{"format", map[string]interface{}{"formats": []string{"flac"}}, album, true},
+		{"other format", map[string]interface{}{"formats": []string{".mp3"}}, album, false},
+		{"not audio", map[string]interface{}{"artist": []string{"a"}}, []byte("hello"), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &AudioTagsFilter{}
+			require.NoError(t, f.LoadConfig(tc.config))
+
+			ok, err := f.Match(&mockContext{tc.content, &mockFileInfo{NameVal: "a.flac", SizeVal: int64(len(tc.content))}})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ok)
+		})
+	}
+}
+
+func TestAudioTagsFilter_Attributes(t *testing.T) {
+	f := &AudioTagsFilter{}
+	require.NoError(t, f.LoadConfig(map[string]interface{}{"genre": []string{"house"}}))
+
+	content := metadatatest.FLAC("ARTIST=Daft Punk", "ALBUM=Discovery", "DATE=2001", "TRACKNUMBER=3", "GENRE=House")
+	ctx := newAttributeContext(content, &mockFileInfo{NameVal: "a.flac", SizeVal: int64(len(content))})
+	ok, err := f.Match(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{
+		"audio_tags.format": "flac",
+		"audio_tags.artist": "Daft Punk",
+		"audio_tags.album":  "Discovery",
+		"audio_tags.genre":  "House",
+		"audio_tags.year":   "2001",
+		"audio_tags.track":  "03",
+	}, ctx.attributes)
+
+	// Files that do not match get no attribute
+	require.NoError(t, f.LoadConfig(map[string]interface{}{"genre": []string{"jazz"}}))
+	ctx = newAttributeContext(content, &mockFileInfo{NameVal: "a.flac", SizeVal: int64(len(content))})
+	ok, err = f.Match(ctx)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Empty(t, ctx.attributes)
+}
+
+func TestAudioTagsFilter_MissingDuration(t *testing.T) {
+	f := &AudioTagsFilter{}
+	require.NoError(t, f.LoadConfig(map[string]interface{}{"max_duration": "10m"}))
+
+	assert.False(t, f.matchTags(&metadata.AudioTags{Format: metadata.FormatMP3}))
+	assert.True(t, f.matchTags(&metadata.AudioTags{Format: metadata.FormatMP3, Duration: time.Minute}))
+}
+
+func TestAudioTagsFilter_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"artist_regex": "("},
+		{"min_year": 2020, "max_year": 2000},
+		{"min_duration": "ten minutes"},
+		{"min_duration": "10m", "max_duration": "1m"},
+		{"artists": []string{"typo"}},
+	}
+	for _, config := range invalid {
+		f := &AudioTagsFilter{}
+		assert.Error(t, f.LoadConfig(config), "config %v", config)
+	}
+}
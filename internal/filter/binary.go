@@ -0,0 +1,416 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/polocto/FolderFlow/pkg/ffplugin/filter"
+)
+
+// Formats recognized by the binary filter.
+const (
+	binaryFormatELF    = "elf"
+	binaryFormatPE     = "pe"
+	binaryFormatMachO  = "macho"
+	binaryFormatScript = "script"
+)
+
+// Kinds of binaries recognized by the binary filter.
+const (
+	binaryKindExecutable = "executable"
+	binaryKindLibrary    = "library"
+	binaryKindObject     = "object"
+	binaryKindCore       = "core"
+)
+
+// maxFatArches separates fat Mach-O files from Java class files,
+// which share the 0xcafebabe magic number.
+const maxFatArches = 30
+
+// archAliases maps common architecture names to the Go names used by the filter.
+var archAliases = map[string]string{
+	"x86_64":  "amd64",
+	"x64":     "amd64",
+	"x86-64":  "amd64",
+	"i386":    "386",
+	"i686":    "386",
+	"x86":     "386",
+	"aarch64": "arm64",
+	"armv7":   "arm",
+}
+
+// errNotBinary is returned when a file is not an executable, a library or an object file.
+var errNotBinary = errors.New("not a binary file")
+
+// BinaryFilter matches executables, shared libraries and object files
+// (ELF, PE and Mach-O) by content, as well as executable scripts starting with a shebang.
+type BinaryFilter struct {
+	Formats       []string `yaml:"formats"`
+	Kinds         []string `yaml:"kinds"`
+	Architectures []string `yaml:"architectures"`
+	Bits          int      `yaml:"bits"`
+}
+
+// binaryInfo describes a binary file. Fat Mach-O files hold several architectures.
+type binaryInfo struct {
+	format string
+	kind   string
+	arches []binaryArch
+}
+
+type binaryArch struct {
+	name string
+	bits int
+}
+
+func (f *BinaryFilter) Match(ctx filter.Context) (bool, error) {
+	if ctx == nil {
+		return false, fmt.Errorf("context is nil")
+	}
+	if !ctx.Info().Mode().IsRegular() {
+		return false, nil
+	}
+
+	var info *binaryInfo
+	err := ctx.WithInput(func(r io.Reader) error {
+		var err error
+		info, err = readBinaryInfo(r, ctx.Info().Mode().Perm()&0o111 != 0)
+		return err
+	})
+	if errors.Is(err, errNotBinary) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("cannot read binary header of %q: %w", ctx.BaseName(), err)
+	}
+	return f.matchInfo(info), nil
+}
+
+func (f *BinaryFilter) matchInfo(info *binaryInfo) bool {
+	if len(f.Formats) > 0 && !slices.Contains(f.Formats, info.format) {
+		return false
+	}
+	if len(f.Kinds) > 0 && !slices.Contains(f.Kinds, info.kind) {
+		return false
+	}
+	if len(f.Architectures) == 0 && f.Bits == 0 {
+		return true
+	}
+	// A fat binary matches if one of its architectures matches
+	return slices.ContainsFunc(info.arches, func(arch binaryArch) bool {
+		return (len(f.Architectures) == 0 || slices.Contains(f.Architectures, arch.name)) &&
+			(f.Bits == 0 || f.Bits == arch.bits)
+	})
+}
+
+// readBinaryInfo identifies a binary from its magic number and parses its headers.
+// Scripts are only recognized when executable is true.
+func readBinaryInfo(r io.Reader, executable bool) (*binaryInfo, error) {
+	ra, _, err := readerAt(r, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	magic := make([]byte, 8)
+	n, err := ra.ReadAt(magic, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	magic = magic[:n]
+
+	var info *binaryInfo
+	switch {
+	case bytes.HasPrefix(magic, []byte(elf.ELFMAG)):
+		info, err = readELF(ra)
+	case bytes.HasPrefix(magic, []byte("MZ")):
+		info, err = readPE(ra)
+	case isMachO(magic):
+		info, err = readMachO(ra)
+	case isFatMachO(magic):
+		info, err = readFatMachO(ra)
+	case executable && bytes.HasPrefix(magic, []byte("#!")):
+		return &binaryInfo{format: binaryFormatScript, kind: binaryKindExecutable}, nil
+	default:
+		return nil, errNotBinary
+	}
+	if err != nil {
+		// A truncated or corrupted header is not an error for the run
+		slog.Debug("Invalid binary header", "err", err)
+		return nil, errNotBinary
+	}
+	return info, nil
+}
+
+func readELF(ra io.ReaderAt) (*binaryInfo, error) {
+	file, err := elf.NewFile(ra)
+	if err != nil {
+		return nil, err
+	}
+
+	bits := 32
+	if file.Class == elf.ELFCLASS64 {
+		bits = 64
+	}
+	info := &binaryInfo{
+		format: binaryFormatELF,
+		arches: []binaryArch{{elfArch(file, bits), bits}},
+	}
+	switch file.Type {
+	case elf.ET_EXEC:
+		info.kind = binaryKindExecutable
+	case elf.ET_DYN:
+		// Position independent executables are shared objects with an interpreter
+		info.kind = binaryKindLibrary
+		if slices.ContainsFunc(file.Progs, func(p *elf.Prog) bool { return p.Type == elf.PT_INTERP }) {
+			info.kind = binaryKindExecutable
+		}
+	case elf.ET_REL:
+		info.kind = binaryKindObject
+	case elf.ET_CORE:
+		info.kind = binaryKindCore
+	default:
+		return nil, fmt.Errorf("unknown ELF type %s", file.Type)
+	}
+	return info, nil
+}
+
+func elfArch(file *elf.File, bits int) string {
+	little := file.Data == elf.ELFDATA2LSB
+	switch file.Machine {
+	case elf.EM_X86_64:
+		return "amd64"
+	case elf.EM_386:
+		return "386"
+	case elf.EM_ARM:
+		return "arm"
+	case elf.EM_AARCH64:
+		return "arm64"
+	case elf.EM_RISCV:
+		return fmt.Sprintf("riscv%d", bits)
+	case elf.EM_PPC:
+		return "ppc"
+	case elf.EM_PPC64:
+		if little {
+			return "ppc64le"
+		}
+		return "ppc64"
+	case elf.EM_MIPS:
+		name := "mips"
+		if bits == 64 {
+			name = "mips64"
+		}
+		if little {
+			name += "le"
+		}
+		return name
+	case elf.EM_S390:
+		return "s390x"
+	case elf.EM_LOONGARCH:
+		return "loong64"
+	default:
+		return strings.ToLower(strings.TrimPrefix(file.Machine.String(), "EM_"))
+	}
+}
+
+func readPE(ra io.ReaderAt) (*binaryInfo, error) {
+	file, err := pe.NewFile(ra)
+	if err != nil {
+		return nil, err
+	}
+
+	bits := 32
+	if _, ok := file.OptionalHeader.(*pe.OptionalHeader64); ok {
+		bits = 64
+	}
+	info := &binaryInfo{
+		format: binaryFormatPE,
+		arches: []binaryArch{{peArch(file.Machine), bits}},
+	}
+	switch {
+	case file.Characteristics&pe.IMAGE_FILE_DLL != 0:
+		info.kind = binaryKindLibrary
+	case file.Characteristics&pe.IMAGE_FILE_EXECUTABLE_IMAGE != 0:
+		info.kind = binaryKindExecutable
+	default:
+		info.kind = binaryKindObject
+	}
+	return info, nil
+}
+
+func peArch(machine uint16) string {
+	switch machine {
+	case pe.IMAGE_FILE_MACHINE_AMD64:
+		return "amd64"
+	case pe.IMAGE_FILE_MACHINE_I386:
+		return "386"
+	case pe.IMAGE_FILE_MACHINE_ARM64:
+		return "arm64"
+	case pe.IMAGE_FILE_MACHINE_ARM, pe.IMAGE_FILE_MACHINE_ARMNT, pe.IMAGE_FILE_MACHINE_THUMB:
+		return "arm"
+	case pe.IMAGE_FILE_MACHINE_IA64:
+		return "ia64"
+	case pe.IMAGE_FILE_MACHINE_RISCV64:
+		return "riscv64"
+	default:
+		return fmt.Sprintf("0x%04x", machine)
+	}
+}
+
+func isMachO(magic []byte) bool {
+	if len(magic) < 4 {
+		return false
+	}
+	switch binary.BigEndian.Uint32(magic) {
+	case macho.Magic32, macho.Magic64:
+		return true
+	}
+	switch binary.LittleEndian.Uint32(magic) {
+	case macho.Magic32, macho.Magic64:
+		return true
+	}
+	return false
+}
+
+func isFatMachO(magic []byte) bool {
+	if len(magic) < 8 || binary.BigEndian.Uint32(magic) != macho.MagicFat {
+		return false
+	}
+	// Java class files store their version where fat files store the number of architectures
+	return binary.BigEndian.Uint32(magic[4:]) <= maxFatArches
+}
+
+func readMachO(ra io.ReaderAt) (*binaryInfo, error) {
+	file, err := macho.NewFile(ra)
+	if err != nil {
+		return nil, err
+	}
+	return &binaryInfo{
+		format: binaryFormatMachO,
+		kind:   machOKind(file.Type),
+		arches: []binaryArch{machOArch(file.Cpu, file.Magic)},
+	}, nil
+}
+
+func readFatMachO(ra io.ReaderAt) (*binaryInfo, error) {
+	file, err := macho.NewFatFile(ra)
+	if err != nil {
+		return nil, err
+	}
+	info := &binaryInfo{format: binaryFormatMachO, kind: machOKind(file.Arches[0].Type)}
+	for _, arch := range file.Arches {
+		info.arches = append(info.arches, machOArch(arch.Cpu, arch.Magic))
+	}
+	return info, nil
+}
+
+func machOKind(t macho.Type) string {
+	switch t {
+	case macho.TypeExec:
+		return binaryKindExecutable
+	case macho.TypeDylib, macho.TypeBundle:
+		return binaryKindLibrary
+	case macho.TypeObj:
+		return binaryKindObject
+	default:
+		return ""
+	}
+}
+
+func machOArch(cpu macho.Cpu, magic uint32) binaryArch {
+	bits := 32
+	if magic == macho.Magic64 {
+		bits = 64
+	}
+	switch cpu {
+	case macho.CpuAmd64:
+		return binaryArch{"amd64", bits}
+	case macho.Cpu386:
+		return binaryArch{"386", bits}
+	case macho.CpuArm64:
+		return binaryArch{"arm64", bits}
+	case macho.CpuArm:
+		return binaryArch{"arm", bits}
+	case macho.CpuPpc64:
+		return binaryArch{"ppc64", bits}
+	case macho.CpuPpc:
+		return binaryArch{"ppc", bits}
+	default:
+		return binaryArch{fmt.Sprintf("0x%x", uint32(cpu)), bits}
+	}
+}
+
+func (f *BinaryFilter) Selector() string {
+	return "binary"
+}
+
+func (f *BinaryFilter) LoadConfig(config map[string]interface{}) error {
+	var cfg BinaryFilter
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	for i, format := range cfg.Formats {
+		format = strings.ReplaceAll(strings.ToLower(format), "-", "")
+		switch format {
+		case binaryFormatELF, binaryFormatPE, binaryFormatMachO, binaryFormatScript:
+			cfg.Formats[i] = format
+		default:
+			return fmt.Errorf("invalid format %q, must be one of elf, pe, macho, script", cfg.Formats[i])
+		}
+	}
+	for i, kind := range cfg.Kinds {
+		kind = strings.ToLower(kind)
+		switch kind {
+		case binaryKindExecutable, binaryKindLibrary, binaryKindObject, binaryKindCore:
+			cfg.Kinds[i] = kind
+		default:
+			return fmt.Errorf("invalid kind %q, must be one of executable, library, object, core", kind)
+		}
+	}
+	for i, arch := range cfg.Architectures {
+		arch = strings.ToLower(strings.TrimSpace(arch))
+		if arch == "" {
+			return fmt.Errorf("architectures cannot be empty")
+		}
+		if alias, ok := archAliases[arch]; ok {
+			arch = alias
+		}
+		cfg.Architectures[i] = arch
+	}
+	if cfg.Bits != 0 && cfg.Bits != 32 && cfg.Bits != 64 {
+		return fmt.Errorf("invalid bits %d, must be 32 or 64", cfg.Bits)
+	}
+
+	*f = cfg
+
+	slog.Debug("Loading binary was successful", "config", config)
+	return nil
+}
+
+func init() {
+	filter.RegisterFilter("binary", func() filter.Filter {
+		return &BinaryFilter{}
+	})
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/binary"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// elfFile builds a minimal little endian ELF file; interp adds a PT_INTERP program header.
+func elfFile(t *testing.T, class elf.Class, typ elf.Type, machine elf.Machine, interp bool) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	ident := [elf.EI_NIDENT]byte{0x7f, 'E', 'L', 'F', byte(class), byte(elf.ELFDATA2LSB), byte(elf.EV_CURRENT)}
+
+	if class == elf.ELFCLASS64 {
+		hdr := elf.Header64{
+			Ident: ident, Type: uint16(typ), Machine: uint16(machine), Version: uint32(elf.EV_CURRENT),
+			Ehsize: 64, Phentsize: 56,
+		}
+		if interp {
+			hdr.Phoff, hdr.Phnum = 64, 1
+		}
+		require.NoError(t, binary.Write(&buf, binary.LittleEndian, hdr))
+		if interp {
+			require.NoError(t, binary.Write(&buf, binary.LittleEndian, elf.Prog64{Type: uint32(elf.PT_INTERP)}))
+		}
+		return buf.Bytes()
+	}
+
+	hdr := elf.Header32{
+		Ident: ident, Type: uint16(typ), Machine: uint16(machine), Version: uint32(elf.EV_CURRENT),
+		Ehsize: 52, Phentsize: 32,
+	}
+	require.NoError(t, binary.Write(&buf, binary.LittleEndian, hdr))
+	return buf.Bytes()
+}
+
+// peFile builds a minimal PE image without sections.
+func peFile(t *testing.T, machine uint16, characteristics uint16, is64 bool) []byte {
+	t.Helper()
+	dos := make([]byte, 0x40)
+	copy(dos, "MZ")
+	binary.LittleEndian.PutUint32(dos[0x3c:], 0x40)
+
+	var optional any = &pe.OptionalHeader32{Magic: 0x10b, NumberOfRvaAndSizes: 16}
+	size := binary.Size(pe.OptionalHeader32{})
+	if is64 {
+		optional = &pe.OptionalHeader64{Magic: 0x20b, NumberOfRvaAndSizes: 16}
+		size = binary.Size(pe.OptionalHeader64{})
+	}
+
+	buf := bytes.NewBuffer(dos)
+	buf.WriteString("PE\x00\x00")
+	require.NoError(t, binary.Write(buf, binary.LittleEndian, pe.FileHeader{
+		Machine: machine, SizeOfOptionalHeader: uint16(size), Characteristics: characteristics,
+	}))
+	require.NoError(t, binary.Write(buf, binary.LittleEndian, optional))
+	return buf.Bytes()
+}
+
+// machOFile builds a minimal little endian Mach-O file without load commands.
+func machOFile(t *testing.T, cpu macho.Cpu, typ macho.Type, is64 bool) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	magic := uint32(macho.Magic32)
+	if is64 {
+		magic = macho.Magic64
+	}
+	require.NoError(t, binary.Write(&buf, binary.LittleEndian, macho.FileHeader{Magic: magic, Cpu: cpu, Type: typ}))
+	if is64 {
+		buf.Write(make([]byte, 4)) // reserved
+	}
+	return buf.Bytes()
+}
+
+// fatFile builds a fat Mach-O file holding the given slices.
+func fatFile(t *testing.T, slices ...[]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, binary.Write(&buf, binary.BigEndian, []uint32{macho.MagicFat, uint32(len(slices))}))
+
+	offset := uint32(8 + 20*len(slices))
+	for _, slice := range slices {
+		cpu := macho.Cpu(binary.LittleEndian.Uint32(slice[4:]))
+		require.NoError(t, binary.Write(&buf, binary.BigEndian, macho.FatArchHeader{
+			Cpu: cpu, Offset: offset, Size: uint32(len(slice)),
+		}))
+		offset += uint32(len(slice))
+	}
+	for _, slice := range slices {
+		buf.Write(slice)
+	}
+	return buf.Bytes()
+}
+
+func TestBinaryFilter_Match(t *testing.T) {
+	elfExec := elfFile(t, elf.ELFCLASS64, elf.ET_EXEC, elf.EM_X86_64, false)
+	elfPIE := elfFile(t, elf.ELFCLASS64, elf.ET_DYN, elf.EM_AARCH64, true)
+	elfLib := elfFile(t, elf.ELFCLASS64, elf.ET_DYN, elf.EM_X86_64, false)
+	elfARM := elfFile(t, elf.ELFCLASS32, elf.ET_EXEC, elf.EM_ARM, false)
+	peExe := peFile(t, pe.IMAGE_FILE_MACHINE_AMD64, pe.IMAGE_FILE_EXECUTABLE_IMAGE, true)
+	peDLL := peFile(t, pe.IMAGE_FILE_MACHINE_I386, pe.IMAGE_FILE_EXECUTABLE_IMAGE|pe.IMAGE_FILE_DLL, false)
+	machoExec := machOFile(t, macho.CpuArm64, macho.TypeExec, true)
+	machoLib := machOFile(t, macho.Cpu386, macho.TypeDylib, false)
+	universal := fatFile(t, machOFile(t, macho.CpuAmd64, macho.TypeExec, true), machoExec)
+	script := []byte("#!/bin/sh\necho hello\n")
+	javaClass := []byte{0xca, 0xfe, 0xba, 0xbe, 0x00, 0x00, 0x00, 0x41}
+
+	testCases := []struct {
+		name     string
+		config   map[string]interface{}
+		content  []byte
+		mode     fs.FileMode
+		expected bool
+	}{
+		{"any binary", map[string]interface{}{}, elfExec, 0o755, true},
+		{"elf executable", map[string]interface{}{"formats": []string{"elf"}, "kinds": []string{"executable"}}, elfExec, 0o755, true},
+		{"pie executable", map[string]interface{}{"kinds": []string{"executable"}, "architectures": []string{"aarch64"}}, elfPIE, 0o755, true},
+		{"shared library", map[string]interface{}{"kinds": []string{"library"}}, elfLib, 0o644, true},
+		{"library is not executable", map[string]interface{}{"kinds": []string{"executable"}}, elfLib, 0o644, false},
+		{"32 bit elf", map[string]interface{}{"bits": 32, "architectures": []string{"armv7"}}, elfARM, 0o755, true},
+		{"64 bit only", map[string]interface{}{"bits": 64}, elfARM, 0o755, false},
+		{"pe executable", map[string]interface{}{"formats": []string{"pe"}, "architectures": []string{"x86_64"}, "bits": 64}, peExe, 0o644, true},
+		{"pe dll", map[string]interface{}{"kinds": []string{"library"}, "architectures": []string{"i386"}, "bits": 32}, peDLL, 0o644, true},
+		{"pe is not elf", map[string]interface{}{"formats": []string{"elf"}}, peExe, 0o644, false},
+		{"mach-o executable", map[string]interface{}{"formats": []string{"mach-o"}, "architectures": []string{"arm64"}}, machoExec, 0o755, true},
+		{"mach-o dylib", map[string]interface{}{"kinds": []string{"library"}, "bits": 32}, machoLib, 0o644, true},
+		{"universal amd64", map[string]interface{}{"architectures": []string{"amd64"}}, universal, 0o755, true},
+		{"universal arm64", map[string]interface{}{"architectures": []string{"arm64"}, "bits": 64}, universal, 0o755, true},
+		{"universal 386", map[string]interface{}{"architectures": []string{"386"}}, universal, 0o755, false},
+		{"executable script", map[string]interface{}{"kinds": []string{"executable"}}, script, 0o755, true},
+		{"script format", map[string]interface{}{"formats": []string{"script"}}, script, 0o700, true},
+		{"script without +x", map[string]interface{}{}, script, 0o644, false},
+		{"script has no architecture", map[string]interface{}{"architectures": []string{"amd64"}}, script, 0o755, false},
+		{"java class", map[string]interface{}{}, javaClass, 0o644, false},
+		{"text", map[string]interface{}{}, []byte("MZ is not enough"), 0o644, false},
+		{"truncated elf", map[string]interface{}{}, elfExec[:20], 0o755, false},
+		{"empty", map[string]interface{}{}, []byte{}, 0o755, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &BinaryFilter{}
+			require.NoError(t, f.LoadConfig(tc.config))
+
+			ok, err := f.Match(&mockContext{tc.content, &mockFileInfo{NameVal: "a", ModeVal: tc.mode}})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ok)
+		})
+	}
+}
+
+func TestBinaryFilter_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"formats": []string{"coff"}},
+		{"kinds": []string{"driver"}},
+		{"architectures": []string{""}},
+		{"bits": 16},
+	}
+	for _, config := range invalid {
+		f := &BinaryFilter{}
+		assert.Error(t, f.LoadConfig(config), "config %v", config)
+	}
+}
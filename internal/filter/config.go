@@ -0,0 +1,112 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// decodeConfig decodes a raw plugin configuration into out.
+// Unknown keys are rejected so that typos do not silently disable a criterion.
+func decodeConfig(config map[string]interface{}, out interface{}) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseDate parses a configuration date. Dates without time zone are local.
+func parseDate(s string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD or RFC3339", s)
+}
+
+// dateRange is an optional [after, before) interval loaded from the configuration.
+type dateRange struct {
+	after  time.Time
+	before time.Time
+}
+
+func newDateRange(after, before string) (dateRange, error) {
+	var r dateRange
+	var err error
+	if after != "" {
+		if r.after, err = parseDate(after); err != nil {
+			return r, err
+		}
+	}
+	if before != "" {
+		if r.before, err = parseDate(before); err != nil {
+			return r, err
+		}
+	}
+	if !r.after.IsZero() && !r.before.IsZero() && !r.after.Before(r.before) {
+		return r, fmt.Errorf("date range is empty: %q is not before %q", after, before)
+	}
+	return r, nil
+}
+
+func (r dateRange) isSet() bool {
+	return !r.after.IsZero() || !r.before.IsZero()
+}
+
+func (r dateRange) contains(t time.Time) bool {
+	if !r.after.IsZero() && t.Before(r.after) {
+		return false
+	}
+	if !r.before.IsZero() && !t.Before(r.before) {
+		return false
+	}
+	return true
+}
+
+// containsFold reports whether one of the needles is a case-insensitive substring of value.
+// An empty needle list always matches.
+func containsFold(needles []string, value string) bool {
+	if len(needles) == 0 {
+		return true
+	}
+	value = strings.ToLower(value)
+	for _, n := range needles {
+		if strings.Contains(value, strings.ToLower(n)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,363 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/polocto/FolderFlow/pkg/ffplugin/filter"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+const (
+	// defaultContentMaxBytes bounds the amount of data scanned per file.
+	defaultContentMaxBytes = 1 << 20
+	// contentChunkSize matches the buffer pool of the filter context.
+	contentChunkSize = 4096
+	// binarySniffSize is the amount of data checked for NUL bytes, as done by git.
+	binarySniffSize = 8000
+	// regexOverlap is the amount of text kept between chunks for regular
+	// expressions: matches longer than this may be missed when they span chunks.
+	regexOverlap = 4096
+)
+
+// errStopScan stops ReadChunks once the result is known.
+var errStopScan = errors.New("content scan complete")
+
+// ContentFilter matches text files containing keywords or regular expressions.
+// The file is streamed chunk by chunk; keywords spanning two chunks are found.
+type ContentFilter struct {
+	AllOf      []string `yaml:"all_of"`
+	AnyOf      []string `yaml:"any_of"`
+	Regex      bool     `yaml:"regex"`
+	IgnoreCase bool     `yaml:"ignore_case"`
+	Encoding   string   `yaml:"encoding"`
+	MaxBytes   int64    `yaml:"max_bytes"`
+	SkipBinary *bool    `yaml:"skip_binary"`
+
+	allOf    []keywordMatcher
+	anyOf    []keywordMatcher
+	overlap  int
+	encoding encoding.Encoding
+	// wide is true for UTF-16 encodings
+	wide bool
+}
+
+// keywordMatcher finds a keyword or a regular expression in a text window.
+type keywordMatcher interface {
+	match(text []byte) bool
+}
+
+type literalMatcher []byte
+
+func (m literalMatcher) match(text []byte) bool {
+	return bytes.Contains(text, m)
+}
+
+type regexMatcher struct {
+	re *regexp.Regexp
+}
+
+func (m regexMatcher) match(text []byte) bool {
+	return m.re.Match(text)
+}
+
+func (f *ContentFilter) Match(ctx filter.Context) (bool, error) {
+	if ctx == nil {
+		return false, fmt.Errorf("context is nil")
+	}
+	if ctx.IsDir() {
+		return false, nil
+	}
+
+	scanner := newContentScanner(f)
+	var decoder *transform.Writer
+	var head []byte
+	var read int64
+	write := func(chunk []byte) error {
+		if decoder == nil {
+			enc, wide := f.encodingFor(chunk)
+			// NUL bytes are expected in UTF-16 text
+			if f.skipBinary() && !wide && isBinary(chunk) {
+				scanner.binary = true
+				return errStopScan
+			}
+			if enc == nil {
+				enc = encoding.Nop
+			}
+			decoder = transform.NewWriter(scanner, enc.NewDecoder())
+		}
+		read += int64(len(chunk))
+
+		if _, err := decoder.Write(chunk); err != nil && !errors.Is(err, errStopScan) {
+			return err
+		}
+		if scanner.matched() || read >= f.maxBytes() {
+			return errStopScan
+		}
+		return nil
+	}
+	err := ctx.ReadChunks(contentChunkSize, func(chunk []byte) error {
+		if remaining := f.maxBytes() - read - int64(len(head)); int64(len(chunk)) > remaining {
+			chunk = chunk[:remaining]
+		}
+		if decoder != nil {
+			return write(chunk)
+		}
+		// Collect the beginning of the file before sniffing binary content
+		head = append(head, chunk...)
+		if len(head) < binarySniffSize && int64(len(head)) < f.maxBytes() {
+			return nil
+		}
+		first := head
+		head = nil
+		return write(first)
+	})
+	if err == nil && len(head) > 0 {
+		// The file is shorter than binarySniffSize
+		err = write(head)
+	}
+	if decoder != nil && !scanner.matched() {
+		if closeErr := decoder.Close(); closeErr != nil && !errors.Is(closeErr, errStopScan) && err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil && !errors.Is(err, errStopScan) {
+		return false, fmt.Errorf("cannot read content of %q: %w", ctx.BaseName(), err)
+	}
+	if scanner.binary {
+		slog.Debug("Binary file skipped", "basename", ctx.BaseName())
+		return false, nil
+	}
+	return scanner.matched(), nil
+}
+
+// encodingFor returns the decoder to use, sniffing UTF-16 byte order marks,
+// and whether it is a UTF-16 encoding. It returns nil for UTF-8 input.
+func (f *ContentFilter) encodingFor(first []byte) (encoding.Encoding, bool) {
+	if f.encoding != nil {
+		return f.encoding, f.wide
+	}
+	if bytes.HasPrefix(first, []byte{0xFF, 0xFE}) || bytes.HasPrefix(first, []byte{0xFE, 0xFF}) {
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM), true
+	}
+	return nil, false
+}
+
+// isBinary reports whether the first binarySniffSize bytes of a file contain a NUL byte.
+func isBinary(first []byte) bool {
+	if len(first) > binarySniffSize {
+		first = first[:binarySniffSize]
+	}
+	return bytes.IndexByte(first, 0) >= 0
+}
+
+func (f *ContentFilter) maxBytes() int64 {
+	if f.MaxBytes > 0 {
+		return f.MaxBytes
+	}
+	return defaultContentMaxBytes
+}
+
+func (f *ContentFilter) skipBinary() bool {
+	return f.SkipBinary == nil || *f.SkipBinary
+}
+
+// contentScanner receives decoded UTF-8 text and keeps track of the keywords found.
+// A tail of each window is carried over so that matches spanning chunks are found.
+type contentScanner struct {
+	filter    *ContentFilter
+	foundAll  []bool
+	remaining int
+	foundAny  bool
+	binary    bool
+	carry     []byte
+	pending   []byte
+}
+
+func newContentScanner(f *ContentFilter) *contentScanner {
+	return &contentScanner{
+		filter:    f,
+		foundAll:  make([]bool, len(f.allOf)),
+		remaining: len(f.allOf),
+		foundAny:  len(f.anyOf) == 0,
+	}
+}
+
+func (s *contentScanner) Write(p []byte) (int, error) {
+	s.pending = append(s.pending, p...)
+	complete := len(s.pending) - incompleteRuneSuffix(s.pending)
+	text := s.pending[:complete]
+	if s.filter.IgnoreCase {
+		text = bytes.ToLower(text)
+	}
+
+	window := append(s.carry, text...)
+	s.scan(window)
+
+	s.carry = append([]byte(nil), runeAlignedTail(window, s.filter.overlap)...)
+	s.pending = append(s.pending[:0], s.pending[complete:]...)
+	if s.matched() {
+		return len(p), errStopScan
+	}
+	return len(p), nil
+}
+
+func (s *contentScanner) scan(window []byte) {
+	for i, m := range s.filter.allOf {
+		if !s.foundAll[i] && m.match(window) {
+			s.foundAll[i] = true
+			s.remaining--
+		}
+	}
+	if !s.foundAny {
+		for _, m := range s.filter.anyOf {
+			if m.match(window) {
+				s.foundAny = true
+				break
+			}
+		}
+	}
+}
+
+func (s *contentScanner) matched() bool {
+	return !s.binary && s.remaining == 0 && s.foundAny
+}
+
+// incompleteRuneSuffix returns the number of trailing bytes forming an incomplete rune.
+func incompleteRuneSuffix(b []byte) int {
+	for i := 1; i < utf8.UTFMax && i <= len(b); i++ {
+		if utf8.RuneStart(b[len(b)-i]) {
+			if utf8.FullRune(b[len(b)-i:]) {
+				return 0
+			}
+			return i
+		}
+	}
+	return 0
+}
+
+// runeAlignedTail returns at most n trailing bytes of b, starting on a rune boundary.
+func runeAlignedTail(b []byte, n int) []byte {
+	if len(b) <= n {
+		return b
+	}
+	start := len(b) - n
+	for start < len(b) && !utf8.RuneStart(b[start]) {
+		start++
+	}
+	return b[start:]
+}
+
+func (f *ContentFilter) Selector() string {
+	return "content"
+}
+
+func (f *ContentFilter) LoadConfig(config map[string]interface{}) error {
+	var cfg ContentFilter
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	if len(cfg.AllOf) == 0 && len(cfg.AnyOf) == 0 {
+		return fmt.Errorf("'all_of' or 'any_of' must be set")
+	}
+	if cfg.MaxBytes < 0 {
+		return fmt.Errorf("'max_bytes' cannot be negative")
+	}
+
+	enc, wide, err := parseTextEncoding(cfg.Encoding)
+	if err != nil {
+		return err
+	}
+	cfg.encoding, cfg.wide = enc, wide
+
+	for _, set := range []struct {
+		keywords []string
+		out      *[]keywordMatcher
+	}{{cfg.AllOf, &cfg.allOf}, {cfg.AnyOf, &cfg.anyOf}} {
+		for _, keyword := range set.keywords {
+			if keyword == "" {
+				return fmt.Errorf("keywords cannot be empty")
+			}
+			m, size, err := cfg.newMatcher(keyword)
+			if err != nil {
+				return err
+			}
+			*set.out = append(*set.out, m)
+			cfg.overlap = max(cfg.overlap, size)
+		}
+	}
+
+	*f = cfg
+
+	slog.Debug("Loading content was successful", "config", config)
+	return nil
+}
+
+// newMatcher compiles a keyword and returns the text overlap it needs between chunks.
+func (f *ContentFilter) newMatcher(keyword string) (keywordMatcher, int, error) {
+	if f.Regex {
+		pattern := keyword
+		if f.IgnoreCase {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid pattern %q: %w", keyword, err)
+		}
+		return regexMatcher{re}, regexOverlap, nil
+	}
+	if f.IgnoreCase {
+		keyword = strings.ToLower(keyword)
+	}
+	return literalMatcher(keyword), len(keyword) - 1, nil
+}
+
+// parseTextEncoding resolves an encoding hint and reports whether it is UTF-16.
+// UTF-8, the default, returns a nil encoding.
+func parseTextEncoding(name string) (encoding.Encoding, bool, error) {
+	switch strings.ToLower(strings.ReplaceAll(name, "_", "-")) {
+	case "", "utf-8", "utf8":
+		return nil, false, nil
+	case "utf-16", "utf16":
+		// Little endian unless a byte order mark says otherwise, as on Windows
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), true, nil
+	case "utf-16le":
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), true, nil
+	case "utf-16be":
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), true, nil
+	case "latin-1", "latin1", "iso-8859-1":
+		return charmap.ISO8859_1, false, nil
+	case "windows-1252", "cp1252":
+		return charmap.Windows1252, false, nil
+	default:
+		return nil, false, fmt.Errorf("invalid encoding %q, must be utf-8, utf-16, utf-16le, utf-16be or latin-1", name)
+	}
+}
+
+func init() {
+	filter.RegisterFilter("content", func() filter.Filter {
+		return &ContentFilter{}
+	})
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func utf16LE(s string, bom bool) []byte {
+	var b []byte
+	if bom {
+		b = []byte{0xFF, 0xFE}
+	}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return b
+}
+
+func TestContentFilter_Match(t *testing.T) {
+	invoice := []byte("INVOICE #2024-001\nTotal due: 120.00 EUR\nVAT: 20.00 EUR\n")
+	// "payslip" spans the first two 4096 byte chunks
+	spanning := append(bytes.Repeat([]byte("x"), contentChunkSize-3), []byte("payslip for March")...)
+	// NUL byte after the first chunk but within the sniffed bytes
+	lateNUL := append([]byte("ELF"), bytes.Repeat([]byte("x"), 5000)...)
+	lateNUL = append(lateNUL, 0)
+
+	testCases := []struct {
+		name     string
+		config   map[string]interface{}
+		content  []byte
+		expected bool
+	}{
+		{"any of", map[string]interface{}{"any_of": []string{"receipt", "INVOICE"}}, invoice, true},
+		{"any of missing", map[string]interface{}{"any_of": []string{"receipt"}}, invoice, false},
+		{"all of", map[string]interface{}{"all_of": []string{"Total", "VAT"}}, invoice, true},
+		{"all of partial", map[string]interface{}{"all_of": []string{"Total", "IBAN"}}, invoice, false},
+		{
+			"all of and any of",
+			map[string]interface{}{"all_of": []string{"VAT"}, "any_of": []string{"EUR", "USD"}},
+			invoice, true,
+		},
+		{"case sensitive", map[string]interface{}{"any_of": []string{"invoice"}}, invoice, false},
+		{"ignore case", map[string]interface{}{"any_of": []string{"invoice"}, "ignore_case": true}, invoice, true},
+		{"regex", map[string]interface{}{"any_of": []string{`#\d{4}-\d{3}`}, "regex": true}, invoice, true},
+		{
+			"regex ignore case",
+			map[string]interface{}{"any_of": []string{`^total due`}, "regex": true, "ignore_case": true},
+			[]byte("Total due"), true,
+		},
+		{"across chunks", map[string]interface{}{"any_of": []string{"payslip"}}, spanning, true},
+		{"regex across chunks", map[string]interface{}{"any_of": []string{"pay.lip"}, "regex": true}, spanning, true},
+		{"byte limit", map[string]interface{}{"any_of": []string{"payslip"}, "max_bytes": 100}, spanning, false},
+		{"binary skipped", map[string]interface{}{"any_of": []string{"ELF"}}, []byte("\x7fELF\x00\x01"), false},
+		{
+			"binary scanned",
+			map[string]interface{}{"any_of": []string{"ELF"}, "skip_binary": false},
+			[]byte("\x7fELF\x00\x01"), true,
+		},
+		{"binary after first chunk", map[string]interface{}{"any_of": []string{"ELF"}}, lateNUL, false},
+		{"utf-16 with BOM", map[string]interface{}{"any_of": []string{"Facture"}}, utf16LE("Facture n°12", true), true},
+		{
+			"utf-16 hint",
+			map[string]interface{}{"any_of": []string{"n°12"}, "encoding": "utf-16le"},
+			utf16LE("Facture n°12", false), true,
+		},
+		{
+			"latin-1 hint",
+			map[string]interface{}{"any_of": []string{"reçu"}, "encoding": "latin-1"},
+			[]byte("re\xe7u de paiement"), true,
+		},
+		{"empty file", map[string]interface{}{"any_of": []string{"a"}}, []byte{}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &ContentFilter{}
+			require.NoError(t, f.LoadConfig(tc.config))
+
+			ok, err := f.Match(&mockContext{tc.content, &mockFileInfo{NameVal: "a.txt"}})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ok)
+		})
+	}
+}
+
+func TestContentFilter_MultibyteRuneAcrossChunks(t *testing.T) {
+	// "é" is split between two chunks
+	content := strings.Repeat("a", contentChunkSize-1) + "écrit"
+	f := &ContentFilter{}
+	require.NoError(t, f.LoadConfig(map[string]interface{}{"any_of": []string{"ÉCRIT"}, "ignore_case": true}))
+
+	ok, err := f.Match(&mockContext{[]byte(content), &mockFileInfo{NameVal: "a.txt"}})
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestContentFilter_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{},
+		{"any_of": []string{""}},
+		{"any_of": []string{"("}, "regex": true},
+		{"any_of": []string{"a"}, "encoding": "ebcdic"},
+		{"any_of": []string{"a"}, "max_bytes": -1},
+	}
+	for _, config := range invalid {
+		f := &ContentFilter{}
+		assert.Error(t, f.LoadConfig(config), "config %v", config)
+	}
+}
@@ -14,6 +14,8 @@
 package filter
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"io/fs"
@@ -30,8 +32,16 @@ import (
 type ContextFilter struct {
 	path string
 	info fs.FileInfo
+	file filehandler.Context
+	// onInvalid receives the failures reported by validation filters
+	onInvalid InvalidFunc
+	// attributes are set by the filters that matched
+	attributes map[string]string
 }
 
+// InvalidFunc receives the format of a file that failed validation and the reason of the failure.
+type InvalidFunc func(format, reason string)
+
 // --- sync.Pool for reusable buffers ---
 var chunkPool = sync.Pool{
 	New: func() any {
@@ -54,9 +64,56 @@ func NewContextFilter(file filehandler.Context) (filter.Context, error) {
 	return &ContextFilter{
 		path: file.Path(),
 		info: file,
+		file: file,
 	}, nil
 }
 
+// OnInvalid registers fn to receive the validation failures reported while filtering ctx.
+// It has no effect on contexts that were not created by NewContextFilter.
+func OnInvalid(ctx filter.Context, fn InvalidFunc) {
+	if c, ok := ctx.(*ContextFilter); ok {
+		c.onInvalid = fn
+	}
+}
+
+// reportInvalid passes a validation failure to the handler registered with OnInvalid.
+func reportInvalid(ctx filter.Context, format, reason string) {
+	if c, ok := ctx.(*ContextFilter); ok && c.onInvalid != nil {
+		c.onInvalid(format, reason)
+	}
+}
+
+// Attributes returns the attributes set by the filters that ran on ctx.
+// It returns nil for contexts that were not created by NewContextFilter.
+func Attributes(ctx filter.Context) map[string]string {
+	if c, ok := ctx.(*ContextFilter); ok {
+		return c.attributes
+	}
+	return nil
+}
+
+// setAttributes sets the non-empty values of attrs, prefixing their names with
+// the selector of the filter, as in "exif.make".
+func setAttributes(ctx filter.Context, selector string, attrs map[string]string) {
+	for name, value := range attrs {
+		if value != "" {
+			ctx.SetAttribute(selector+"."+name, value)
+		}
+	}
+}
+
+// dateAttributes adds to attrs the RFC 3339 date and its year, month and day.
+// Zero dates are skipped.
+func dateAttributes(attrs map[string]string, name string, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	attrs[name] = t.Format(time.RFC3339)
+	attrs[name+".year"] = t.Format("2006")
+	attrs[name+".month"] = t.Format("01")
+	attrs[name+".day"] = t.Format("02")
+}
+
 // helper method for clarity
 func (c *ContextFilter) IsDir() bool        { return c.info.IsDir() }
 func (c *ContextFilter) BaseName() string   { return c.info.Name() }
@@ -134,3 +191,37 @@ func (c *ContextFilter) ReadChunks(chunkSize int, fn func([]byte) error) error {
 		return nil
 	})
 }
+
+// readerAt returns r as an io.ReaderAt with its size. Files opened by WithInput
+// support random access; other readers are loaded in memory.
+func readerAt(r io.Reader, size int64) (io.ReaderAt, int64, error) {
+	if ra, ok := r.(io.ReaderAt); ok {
+		return ra, size, nil
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bytes.NewReader(data), int64(len(data)), nil
+}
+
+// Hash returns the SHA-256 of the file, cached by the file context.
+func (c *ContextFilter) Hash() ([sha256.Size]byte, error) {
+	if c.IsDir() {
+		return [sha256.Size]byte{}, fmt.Errorf("cannot hash directory %q", c.path)
+	}
+	return c.file.GetHash()
+}
+
+// SetAttribute attaches a value to the file for the strategy of the destination.
+func (c *ContextFilter) SetAttribute(name, value string) {
+	if c.attributes == nil {
+		c.attributes = make(map[string]string)
+	}
+	c.attributes[name] = value
+}
+
+// Xattr reads an extended attribute of the file itself, not of a symlink target.
+func (c *ContextFilter) Xattr(name string) ([]byte, bool, error) {
+	return getXattr(c.path, name)
+}
@@ -15,6 +15,7 @@ package filter_test
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"errors"
 	"io"
 	"os"
@@ -127,3 +128,30 @@ func TestWithInput_OnDir(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "cannot open directory")
 }
+
+func TestHash(t *testing.T) {
+	content := []byte("Hello World")
+	ctxFile := newTempContextFile(t, "file.txt", content)
+
+	ctx, err := filter.NewContextFilter(ctxFile)
+	assert.NoError(t, err)
+
+	sum, err := ctx.Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, sha256.Sum256(content), sum)
+
+	// The hash is shared with the file context
+	cached, err := ctxFile.GetHash()
+	assert.NoError(t, err)
+	assert.Equal(t, sum, cached)
+}
+
+func TestAttributes(t *testing.T) {
+	ctx, err := filter.NewContextFilter(newTempContextFile(t, "file.txt", nil))
+	assert.NoError(t, err)
+	assert.Nil(t, filter.Attributes(ctx))
+
+	ctx.SetAttribute("regex.client", "ACME")
+	ctx.SetAttribute("regex.client", "Globex")
+	assert.Equal(t, map[string]string{"regex.client": "Globex"}, filter.Attributes(ctx))
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/polocto/FolderFlow/internal/metadata"
+	"github.com/polocto/FolderFlow/pkg/ffplugin/filter"
+)
+
+// docFormatGroups expands the format groups accepted in 'formats'.
+var docFormatGroups = map[string][]string{
+	"ooxml": {metadata.FormatDOCX, metadata.FormatXLSX, metadata.FormatPPTX},
+	"odf":   {metadata.FormatODT, metadata.FormatODS, metadata.FormatODP, metadata.FormatODG},
+}
+
+// DocMetaFilter matches office documents (OOXML and ODF) and PDF files on the
+// metadata they embed: author, title, subject, company, keywords and dates.
+type DocMetaFilter struct {
+	Author         []string `yaml:"author"`
+	AuthorRegex    string   `yaml:"author_regex"`
+	Title          []string `yaml:"title"`
+	TitleRegex     string   `yaml:"title_regex"`
+	Subject        []string `yaml:"subject"`
+	SubjectRegex   string   `yaml:"subject_regex"`
+	Company        []string `yaml:"company"`
+	CompanyRegex   string   `yaml:"company_regex"`
+	Keywords       []string `yaml:"keywords"`
+	CreatedAfter   string   `yaml:"created_after"`
+	CreatedBefore  string   `yaml:"created_before"`
+	ModifiedAfter  string   `yaml:"modified_after"`
+	ModifiedBefore string   `yaml:"modified_before"`
+	Formats        []string `yaml:"formats"`
+
+	fields   []docCriterion
+	created  dateRange
+	modified dateRange
+}
+
+// docCriterion matches one metadata field on exact values and an optional regex.
+type docCriterion struct {
+	value  func(*metadata.DocumentInfo) string
+	values []string
+	re     *regexp.Regexp
+}
+
+func (c docCriterion) match(info *metadata.DocumentInfo) bool {
+	value := c.value(info)
+	if len(c.values) > 0 && !slices.ContainsFunc(c.values, func(v string) bool { return strings.EqualFold(v, value) }) {
+		return false
+	}
+	return c.re == nil || c.re.MatchString(value)
+}
+
+func (f *DocMetaFilter) Match(ctx filter.Context) (bool, error) {
+	if ctx == nil {
+		return false, fmt.Errorf("context is nil")
+	}
+	if !ctx.Info().Mode().IsRegular() {
+		return false, nil
+	}
+
+	var info *metadata.DocumentInfo
+	err := ctx.WithInput(func(r io.Reader) error {
+		ra, size, err := readerAt(r, ctx.Size())
+		if err != nil {
+			return err
+		}
+		info, err = metadata.ReadDocumentInfo(ra, size)
+		return err
+	})
+	if errors.Is(err, metadata.ErrNotDocument) {
+		slog.Debug("Not a document", "basename", ctx.BaseName())
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("cannot read document metadata of %q: %w", ctx.BaseName(), err)
+	}
+	if !f.matchInfo(info) {
+		return false, nil
+	}
+	attrs := map[string]string{
+		"format": info.Format, "title": info.Title, "subject": info.Subject,
+		"author": info.Author, "company": info.Company,
+	}
+	dateAttributes(attrs, "created", info.Created)
+	dateAttributes(attrs, "modified", info.Modified)
+	setAttributes(ctx, f.Selector(), attrs)
+	return true, nil
+}
+
+func (f *DocMetaFilter) matchInfo(info *metadata.DocumentInfo) bool {
+	if len(f.Formats) > 0 && !slices.Contains(f.Formats, info.Format) {
+		return false
+	}
+	for _, c := range f.fields {
+		if !c.match(info) {
+			return false
+		}
+	}
+	if len(f.Keywords) > 0 && !f.matchKeywords(info.Keywords) {
+		return false
+	}
+	if f.created.isSet() && (info.Created.IsZero() || !f.created.contains(info.Created)) {
+		return false
+	}
+	if f.modified.isSet() && (info.Modified.IsZero() || !f.modified.contains(info.Modified)) {
+		return false
+	}
+	return true
+}
+
+// matchKeywords reports whether one of the configured keywords is in the
+// comma or semicolon separated keywords of the document.
+func (f *DocMetaFilter) matchKeywords(keywords string) bool {
+	for _, keyword := range strings.FieldsFunc(keywords, func(r rune) bool { return r == ',' || r == ';' }) {
+		keyword = strings.TrimSpace(keyword)
+		if slices.ContainsFunc(f.Keywords, func(k string) bool { return strings.EqualFold(k, keyword) }) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *DocMetaFilter) Selector() string {
+	return "docmeta"
+}
+
+func (f *DocMetaFilter) LoadConfig(config map[string]interface{}) error {
+	var cfg DocMetaFilter
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	fields := []struct {
+		name    string
+		values  []string
+		pattern string
+		value   func(*metadata.DocumentInfo) string
+	}{
+		{"author", cfg.Author, cfg.AuthorRegex, func(i *metadata.DocumentInfo) string { return i.Author }},
+		{"title", cfg.Title, cfg.TitleRegex, func(i *metadata.DocumentInfo) string { return i.Title }},
+		{"subject", cfg.Subject, cfg.SubjectRegex, func(i *metadata.DocumentInfo) string { return i.Subject }},
+		{"company", cfg.Company, cfg.CompanyRegex, func(i *metadata.DocumentInfo) string { return i.Company }},
+	}
+	for _, field := range fields {
+		if len(field.values) == 0 && field.pattern == "" {
+			continue
+		}
+		c := docCriterion{value: field.value}
+		for _, v := range field.values {
+			c.values = append(c.values, strings.TrimSpace(v))
+		}
+		if field.pattern != "" {
+			re, err := regexp.Compile(field.pattern)
+			if err != nil {
+				return fmt.Errorf("invalid '%s_regex' pattern %q: %w", field.name, field.pattern, err)
+			}
+			c.re = re
+		}
+		cfg.fields = append(cfg.fields, c)
+	}
+
+	var err error
+	if cfg.created, err = newDateRange(cfg.CreatedAfter, cfg.CreatedBefore); err != nil {
+		return err
+	}
+	if cfg.modified, err = newDateRange(cfg.ModifiedAfter, cfg.ModifiedBefore); err != nil {
+		return err
+	}
+
+	var formats []string
+	for _, format := range cfg.Formats {
+		format = strings.ToLower(strings.TrimPrefix(format, "."))
+		if group, ok := docFormatGroups[format]; ok {
+			formats = append(formats, group...)
+			continue
+		}
+		switch format {
+		case metadata.FormatDOCX, metadata.FormatXLSX, metadata.FormatPPTX,
+			metadata.FormatODT, metadata.FormatODS, metadata.FormatODP, metadata.FormatODG, metadata.FormatPDF:
+			formats = append(formats, format)
+		default:
+			return fmt.Errorf("invalid format %q, must be one of docx, xlsx, pptx, odt, ods, odp, odg, pdf, ooxml, odf", format)
+		}
+	}
+	cfg.Formats = formats
+
+	*f = cfg
+
+	slog.Debug("Loading docmeta was successful", "config", config)
+	return nil
+}
+
+func init() {
+	filter.RegisterFilter("docmeta", func() filter.Filter {
+		return &DocMetaFilter{}
+	})
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/polocto/FolderFlow/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocMetaFilter_MatchInfo(t *testing.T) {
+	report := &metadata.DocumentInfo{
+		Format: metadata.FormatDOCX, Title: "Quarterly report Q1", Author: "Alice Martin",
+		Company: "ACME Corp", Keywords: "finance; budget",
+		Created:  time.Date(2024, time.March, 1, 9, 30, 0, 0, time.UTC),
+		Modified: time.Date(2024, time.April, 2, 0, 0, 0, 0, time.UTC),
+	}
+	scan := &metadata.DocumentInfo{Format: metadata.FormatPDF, Title: "Scan 0042"}
+
+	testCases := []struct {
+		name     string
+		config   map[string]interface{}
+		info     *metadata.DocumentInfo
+		expected bool
+	}{
+		{"author", map[string]interface{}{"author": []string{"alice martin", "Bob"}}, report, true},
+		{"author is exact", map[string]interface{}{"author": []string{"Alice"}}, report, false},
+		{"author regex", map[string]interface{}{"author_regex": "^Alice "}, report, true},
+		{"title regex", map[string]interface{}{"title_regex": `Q[1-4]$`}, report, true},
+		{"company", map[string]interface{}{"company": []string{"ACME Corp"}}, report, true},
+		{"missing company", map[string]interface{}{"company": []string{"ACME Corp"}}, scan, false},
+		{"keywords", map[string]interface{}{"keywords": []string{"Budget"}}, report, true},
+		{"other keywords", map[string]interface{}{"keywords": []string{"legal"}}, report, false},
+		{"created", map[string]interface{}{"created_after": "2024-01-01", "created_before": "2024-06-01"}, report, true},
+		{"unknown creation date", map[string]interface{}{"created_after": "2024-01-01"}, scan, false},
+		{"modified", map[string]interface{}{"modified_before": "2024-04-01"}, report, false},
+		{"format group", map[string]interface{}{"formats": []string{"ooxml"}}, report, true},
+		{"format", map[string]interface{}{"formats": []string{".pdf"}}, report, false},
+		{"all criteria", map[string]interface{}{"author": []string{"Alice Martin"}, "title_regex": "report"}, report, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &DocMetaFilter{}
+			require.NoError(t, f.LoadConfig(tc.config))
+			assert.Equal(t, tc.expected, f.matchInfo(tc.info))
+		})
+	}
+}
+
+func TestDocMetaFilter_NotDocument(t *testing.T) {
+	f := &DocMetaFilter{}
+	require.NoError(t, f.LoadConfig(map[string]interface{}{"title_regex": "."}))
+
+	ok, err := f.Match(&mockContext{[]byte("plain text"), &mockFileInfo{NameVal: "a.docx"}})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestDocMetaFilter_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"author_regex": "("},
+		{"formats": []string{"rtf"}},
+		{"created_after": "last week"},
+	}
+	for _, config := range invalid {
+		f := &DocMetaFilter{}
+		assert.Error(t, f.LoadConfig(config), "config %v", config)
+	}
+}
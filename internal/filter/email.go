@@ -0,0 +1,251 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/mail"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/polocto/FolderFlow/internal/metadata"
+	"github.com/polocto/FolderFlow/pkg/ffplugin/filter"
+)
+
+// EmailFilter matches .eml messages and mbox files on their headers and attachments.
+// A mailbox matches when one of its messages matches.
+type EmailFilter struct {
+	From            []string `yaml:"from"`
+	FromDomains     []string `yaml:"from_domains"`
+	FromRegex       string   `yaml:"from_regex"`
+	To              []string `yaml:"to"`
+	ToDomains       []string `yaml:"to_domains"`
+	ToRegex         string   `yaml:"to_regex"`
+	SubjectRegex    string   `yaml:"subject_regex"`
+	DateAfter       string   `yaml:"date_after"`
+	DateBefore      string   `yaml:"date_before"`
+	HasAttachments  *bool    `yaml:"has_attachments"`
+	AttachmentTypes []string `yaml:"attachment_types"`
+	Formats         []string `yaml:"formats"`
+
+	from    addressCriterion
+	to      addressCriterion
+	subject *regexp.Regexp
+	date    dateRange
+}
+
+// addressCriterion matches an address list on addresses, domains and a regex.
+// The regex is matched against the "Name <address>" form of each address.
+type addressCriterion struct {
+	addresses []string
+	domains   []string
+	re        *regexp.Regexp
+}
+
+func (c addressCriterion) isSet() bool {
+	return len(c.addresses) > 0 || len(c.domains) > 0 || c.re != nil
+}
+
+func (c addressCriterion) match(list []*mail.Address) bool {
+	if !c.isSet() {
+		return true
+	}
+	return slices.ContainsFunc(list, func(a *mail.Address) bool {
+		address := strings.ToLower(a.Address)
+		if len(c.addresses) > 0 && !slices.Contains(c.addresses, address) {
+			return false
+		}
+		if len(c.domains) > 0 && !matchDomain(c.domains, address) {
+			return false
+		}
+		return c.re == nil || c.re.MatchString(displayAddress(a))
+	})
+}
+
+// displayAddress formats an address as "Name <address>", without encoding the name.
+func displayAddress(a *mail.Address) string {
+	if a.Name == "" {
+		return a.Address
+	}
+	return a.Name + " <" + a.Address + ">"
+}
+
+// matchDomain reports whether the domain of an address is one of domains or a subdomain.
+func matchDomain(domains []string, address string) bool {
+	at := strings.LastIndexByte(address, '@')
+	if at < 0 {
+		return false
+	}
+	domain := address[at+1:]
+	return slices.ContainsFunc(domains, func(d string) bool {
+		return domain == d || strings.HasSuffix(domain, "."+d)
+	})
+}
+
+func (f *EmailFilter) Match(ctx filter.Context) (bool, error) {
+	if ctx == nil {
+		return false, fmt.Errorf("context is nil")
+	}
+	if !ctx.Info().Mode().IsRegular() {
+		return false, nil
+	}
+
+	withAttachments := f.HasAttachments != nil || len(f.AttachmentTypes) > 0
+	matched := false
+	err := ctx.WithInput(func(r io.Reader) error {
+		format, err := metadata.ReadEmails(r, withAttachments, func(msg *metadata.EmailMessage) bool {
+			matched = f.matchMessage(msg)
+			return !matched
+		})
+		if err == nil && len(f.Formats) > 0 && !slices.Contains(f.Formats, format) {
+			matched = false
+		}
+		return err
+	})
+	if errors.Is(err, metadata.ErrNotEmail) {
+		slog.Debug("Not an email message", "basename", ctx.BaseName())
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("cannot read email %q: %w", ctx.BaseName(), err)
+	}
+	return matched, nil
+}
+
+func (f *EmailFilter) matchMessage(msg *metadata.EmailMessage) bool {
+	if !f.from.match(msg.From) {
+		return false
+	}
+	if !f.to.match(append(slices.Clone(msg.To), msg.Cc...)) {
+		return false
+	}
+	if f.subject != nil && !f.subject.MatchString(msg.Subject) {
+		return false
+	}
+	if f.date.isSet() && (msg.Date.IsZero() || !f.date.contains(msg.Date)) {
+		return false
+	}
+	if f.HasAttachments != nil && *f.HasAttachments != (len(msg.Attachments) > 0) {
+		return false
+	}
+	if len(f.AttachmentTypes) > 0 && !slices.ContainsFunc(msg.Attachments, f.matchAttachment) {
+		return false
+	}
+	return true
+}
+
+// matchAttachment matches an attachment on its extension or its MIME type.
+func (f *EmailFilter) matchAttachment(a metadata.EmailAttachment) bool {
+	ext := strings.ToLower(filepath.Ext(a.Filename))
+	for _, t := range f.AttachmentTypes {
+		if strings.Contains(t, "/") {
+			if ok, _ := path.Match(t, a.MediaType); ok {
+				return true
+			}
+		} else if ext != "" && t == ext {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *EmailFilter) Selector() string {
+	return "email"
+}
+
+func (f *EmailFilter) LoadConfig(config map[string]interface{}) error {
+	var cfg EmailFilter
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	var err error
+	if cfg.from, err = newAddressCriterion("from", cfg.From, cfg.FromDomains, cfg.FromRegex); err != nil {
+		return err
+	}
+	if cfg.to, err = newAddressCriterion("to", cfg.To, cfg.ToDomains, cfg.ToRegex); err != nil {
+		return err
+	}
+	if cfg.SubjectRegex != "" {
+		if cfg.subject, err = regexp.Compile(cfg.SubjectRegex); err != nil {
+			return fmt.Errorf("invalid 'subject_regex' pattern %q: %w", cfg.SubjectRegex, err)
+		}
+	}
+	if cfg.date, err = newDateRange(cfg.DateAfter, cfg.DateBefore); err != nil {
+		return err
+	}
+
+	for i, t := range cfg.AttachmentTypes {
+		t = strings.ToLower(strings.TrimSpace(t))
+		switch {
+		case t == "" || t == ".":
+			return fmt.Errorf("attachment types cannot be empty")
+		case strings.Contains(t, "/"):
+			if _, err := path.Match(t, ""); err != nil {
+				return fmt.Errorf("invalid attachment type %q: %w", t, err)
+			}
+		case !strings.HasPrefix(t, "."):
+			t = "." + t
+		}
+		cfg.AttachmentTypes[i] = t
+	}
+	for i, format := range cfg.Formats {
+		format = strings.ToLower(strings.TrimPrefix(format, "."))
+		if format != metadata.FormatEML && format != metadata.FormatMbox {
+			return fmt.Errorf("invalid format %q, must be eml or mbox", cfg.Formats[i])
+		}
+		cfg.Formats[i] = format
+	}
+
+	*f = cfg
+
+	slog.Debug("Loading email was successful", "config", config)
+	return nil
+}
+
+func newAddressCriterion(field string, addresses, domains []string, pattern string) (addressCriterion, error) {
+	var c addressCriterion
+	for _, address := range addresses {
+		if address = strings.ToLower(strings.TrimSpace(address)); address == "" {
+			return c, fmt.Errorf("'%s' addresses cannot be empty", field)
+		}
+		c.addresses = append(c.addresses, address)
+	}
+	for _, domain := range domains {
+		if domain = strings.ToLower(strings.Trim(strings.TrimSpace(domain), "@.")); domain == "" {
+			return c, fmt.Errorf("'%s_domains' cannot be empty", field)
+		}
+		c.domains = append(c.domains, domain)
+	}
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return c, fmt.Errorf("invalid '%s_regex' pattern %q: %w", field, pattern, err)
+		}
+		c.re = re
+	}
+	return c, nil
+}
+
+func init() {
+	filter.RegisterFilter("email", func() filter.Filter {
+		return &EmailFilter{}
+	})
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testInvoiceEmail = "From: =?UTF-8?Q?Fran=C3=A7ois?= <francois@billing.example.com>\n" +
+	"To: Alice <alice@example.org>\n" +
+	"Cc: accounting@corp.example.net\n" +
+	"Subject: =?UTF-8?Q?Facture_n=C2=B012?=\n" +
+	"Date: Tue, 2 Apr 2024 10:15:00 +0200\n" +
+	"Content-Type: multipart/mixed; boundary=b\n" +
+	"\n" +
+	"--b\n" +
+	"Content-Type: text/plain\n" +
+	"\n" +
+	"Invoice attached.\n" +
+	"--b\n" +
+	"Content-Type: application/octet-stream\n" +
+	"Content-Disposition: attachment; filename=\"Invoice-12.PDF\"\n" +
+	"\n" +
+	"data\n" +
+	"--b--\n"
+
+const testMbox = "From news@example.com Mon Jan  1 10:00:00 2024\n" +
+	"From: news@example.com\n" +
+	"Subject: Newsletter\n" +
+	"\n" +
+	"Hello\n" +
+	"\n" +
+	"From bob@example.net Tue Jan  2 10:00:00 2024\n" +
+	"From: Bob <bob@example.net>\n" +
+	"Subject: Holiday photos\n" +
+	"Content-Type: multipart/mixed; boundary=b\n" +
+	"\n" +
+	"--b\n" +
+	"Content-Type: image/jpeg\n" +
+	"Content-Disposition: attachment; filename=beach.jpg\n" +
+	"\n" +
+	"jpeg\n" +
+	"--b--\n"
+
+func TestEmailFilter_Match(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   map[string]interface{}
+		content  string
+		expected bool
+	}{
+		{"from domain", map[string]interface{}{"from_domains": []string{"example.com"}}, testInvoiceEmail, true},
+		{"from other domain", map[string]interface{}{"from_domains": []string{"example.org"}}, testInvoiceEmail, false},
+		{"from address", map[string]interface{}{"from": []string{"Francois@Billing.example.com"}}, testInvoiceEmail, true},
+		{"from regex on name", map[string]interface{}{"from_regex": "^François <"}, testInvoiceEmail, true},
+		{"to", map[string]interface{}{"to": []string{"alice@example.org"}}, testInvoiceEmail, true},
+		{"cc domain", map[string]interface{}{"to_domains": []string{"@example.net"}}, testInvoiceEmail, true},
+		{"to other", map[string]interface{}{"to": []string{"bob@example.org"}}, testInvoiceEmail, false},
+		{"subject regex", map[string]interface{}{"subject_regex": `(?i)^facture n°\d+`}, testInvoiceEmail, true},
+		{"date", map[string]interface{}{"date_after": "2024-04-01", "date_before": "2024-05-01"}, testInvoiceEmail, true},
+		{"date outside", map[string]interface{}{"date_before": "2024-01-01"}, testInvoiceEmail, false},
+		{"has attachments", map[string]interface{}{"has_attachments": true}, testInvoiceEmail, true},
+		{"no attachments", map[string]interface{}{"has_attachments": false}, testInvoiceEmail, false},
+		{"attachment extension", map[string]interface{}{"attachment_types": []string{"pdf"}}, testInvoiceEmail, true},
+		{"attachment mime type", map[string]interface{}{"attachment_types": []string{"image/*"}}, testInvoiceEmail, false},
+		{"mbox message", map[string]interface{}{"attachment_types": []string{"image/*"}}, testMbox, true},
+		{"mbox all criteria on one message", map[string]interface{}{
+			"from_domains": []string{"example.com"}, "has_attachments": true,
+		}, testMbox, false},
+		{"mbox format", map[string]interface{}{"formats": []string{"mbox"}}, testMbox, true},
+		{"eml format", map[string]interface{}{"formats": []string{"eml"}}, testMbox, false},
+		{"not an email", map[string]interface{}{}, "plain text", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &EmailFilter{}
+			require.NoError(t, f.LoadConfig(tc.config))
+
+			ok, err := f.Match(&mockContext{[]byte(tc.content), &mockFileInfo{NameVal: "message.eml"}})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ok)
+		})
+	}
+}
+
+func TestEmailFilter_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"from": []string{" "}},
+		{"to_domains": []string{"@"}},
+		{"subject_regex": "("},
+		{"from_regex": "["},
+		{"date_after": "soon"},
+		{"attachment_types": []string{""}},
+		{"attachment_types": []string{"image/["}},
+		{"formats": []string{"pst"}},
+	}
+	for _, config := range invalid {
+		f := &EmailFilter{}
+		assert.Error(t, f.LoadConfig(config), "config %v", config)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+
+	"github.com/polocto/FolderFlow/internal/metadata"
+	"github.com/polocto/FolderFlow/pkg/ffplugin/filter"
+)
+
+// defaultEXIFMaxBytes is enough for the EXIF block of JPEG and most HEIC files.
+const defaultEXIFMaxBytes = 1 << 20
+
+// EXIFFilter matches photos on the EXIF metadata they embed.
+type EXIFFilter struct {
+	Exists      *bool    `yaml:"exists"`
+	Make        []string `yaml:"make"`
+	Model       []string `yaml:"model"`
+	Lens        []string `yaml:"lens"`
+	TakenAfter  string   `yaml:"taken_after"`
+	TakenBefore string   `yaml:"taken_before"`
+	HasGPS      *bool    `yaml:"has_gps"`
+	Orientation []int    `yaml:"orientation"`
+	MaxBytes    int64    `yaml:"max_bytes"`
+	taken       dateRange
+}
+
+func (f *EXIFFilter) Match(ctx filter.Context) (bool, error) {
+	if ctx == nil {
+		return false, fmt.Errorf("context is nil")
+	}
+	if ctx.IsDir() {
+		return false, nil
+	}
+
+	meta, err := readEXIF(ctx, f.maxBytes())
+	if err != nil {
+		return false, err
+	}
+	if !f.matchEXIF(meta) {
+		return false, nil
+	}
+	if meta != nil {
+		attrs := map[string]string{"make": meta.Make, "model": meta.Model, "lens": meta.LensModel}
+		if taken, ok := meta.Taken(nil); ok {
+			dateAttributes(attrs, "taken", taken)
+		}
+		setAttributes(ctx, f.Selector(), attrs)
+	}
+	return true, nil
+}
+
+// matchEXIF applies the configured criteria. meta is nil when the file has no EXIF.
+func (f *EXIFFilter) matchEXIF(meta *metadata.EXIF) bool {
+	if f.Exists != nil && *f.Exists != (meta != nil) {
+		return false
+	}
+	if meta == nil {
+		// Only "exists: false" can match a file without EXIF
+		return f.Exists != nil
+	}
+
+	if !containsFold(f.Make, meta.Make) ||
+		!containsFold(f.Model, meta.Model) ||
+		!containsFold(f.Lens, meta.LensModel) {
+		return false
+	}
+	if f.HasGPS != nil && *f.HasGPS != meta.HasGPS {
+		return false
+	}
+	if len(f.Orientation) > 0 && !slices.Contains(f.Orientation, meta.Orientation) {
+		return false
+	}
+	if f.taken.isSet() {
+		taken, ok := meta.Taken(nil)
+		if !ok || !f.taken.contains(taken) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *EXIFFilter) maxBytes() int64 {
+	if f.MaxBytes > 0 {
+		return f.MaxBytes
+	}
+	return defaultEXIFMaxBytes
+}
+
+func (f *EXIFFilter) Selector() string {
+	return "exif"
+}
+
+func (f *EXIFFilter) LoadConfig(config map[string]interface{}) error {
+	var cfg EXIFFilter
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	taken, err := newDateRange(cfg.TakenAfter, cfg.TakenBefore)
+	if err != nil {
+		return err
+	}
+	cfg.taken = taken
+
+	if cfg.Exists != nil && !*cfg.Exists &&
+		(len(cfg.Make) > 0 || len(cfg.Model) > 0 || len(cfg.Lens) > 0 ||
+			cfg.taken.isSet() || cfg.HasGPS != nil || len(cfg.Orientation) > 0) {
+		return fmt.Errorf("'exists: false' cannot be combined with other EXIF criteria")
+	}
+	for _, o := range cfg.Orientation {
+		if o < 1 || o > 8 {
+			return fmt.Errorf("invalid orientation %d, must be between 1 and 8", o)
+		}
+	}
+	if cfg.MaxBytes < 0 {
+		return fmt.Errorf("'max_bytes' cannot be negative")
+	}
+
+	*f = cfg
+
+	slog.Debug("Loading exif was successful", "config", config)
+	return nil
+}
+
+// readEXIF returns the EXIF metadata of the file or nil if it has none.
+func readEXIF(ctx filter.Context, maxBytes int64) (*metadata.EXIF, error) {
+	var data []byte
+	err := ctx.WithInputLimited(maxBytes, func(r io.Reader) error {
+		var err error
+		data, err = io.ReadAll(r)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := metadata.ParseEXIF(data)
+	if errors.Is(err, metadata.ErrNoEXIF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXIF in %q: %w", ctx.BaseName(), err)
+	}
+	return meta, nil
+}
+
+func init() {
+	filter.RegisterFilter("exif", func() filter.Filter {
+		return &EXIFFilter{}
+	})
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"testing"
+
+	"github.com/polocto/FolderFlow/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadEXIFFilter(t *testing.T, config map[string]interface{}) *EXIFFilter {
+	t.Helper()
+	f := &EXIFFilter{}
+	require.NoError(t, f.LoadConfig(config))
+	return f
+}
+
+func TestEXIFFilter_MatchEXIF(t *testing.T) {
+	phone := &metadata.EXIF{
+		Make:             "Apple",
+		Model:            "iPhone 13 Pro",
+		Orientation:      6,
+		DateTimeOriginal: "2023:04:01 10:15:00",
+		HasGPS:           true,
+	}
+	dslr := &metadata.EXIF{
+		Make:             "Canon",
+		Model:            "Canon EOS 5D Mark IV",
+		LensModel:        "EF24-70mm f/2.8L II USM",
+		Orientation:      1,
+		DateTimeOriginal: "2021:07:14 18:00:00",
+	}
+
+	testCases := []struct {
+		name     string
+		config   map[string]interface{}
+		meta     *metadata.EXIF
+		expected bool
+	}{
+		{"any EXIF", map[string]interface{}{}, phone, true},
+		{"no EXIF without criteria", map[string]interface{}{}, nil, false},
+		{"screenshot", map[string]interface{}{"exists": false}, nil, true},
+		{"exists false with EXIF", map[string]interface{}{"exists": false}, dslr, false},
+		{"make", map[string]interface{}{"make": []string{"apple"}}, phone, true},
+		{"make mismatch", map[string]interface{}{"make": []string{"nikon", "sony"}}, dslr, false},
+		{"model substring", map[string]interface{}{"model": []string{"EOS"}}, dslr, true},
+		{"lens", map[string]interface{}{"lens": []string{"24-70"}}, dslr, true},
+		{"gps", map[string]interface{}{"has_gps": true}, phone, true},
+		{"no gps", map[string]interface{}{"has_gps": false}, phone, false},
+		{"orientation", map[string]interface{}{"orientation": []int{6, 8}}, phone, true},
+		{
+			"taken in range",
+			map[string]interface{}{"taken_after": "2023-01-01", "taken_before": "2024-01-01"},
+			phone, true,
+		},
+		{"taken before range", map[string]interface{}{"taken_after": "2023-01-01"}, dslr, false},
+		{
+			"all criteria",
+			map[string]interface{}{"make": []string{"canon"}, "has_gps": false, "orientation": []int{1}},
+			dslr, true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := loadEXIFFilter(t, tc.config)
+			assert.Equal(t, tc.expected, f.matchEXIF(tc.meta))
+		})
+	}
+}
+
+func TestEXIFFilter_MatchFileWithoutEXIF(t *testing.T) {
+	f := loadEXIFFilter(t, map[string]interface{}{"exists": false})
+
+	ok, err := f.Match(&mockContext{[]byte("Hello World"), &mockFileInfo{NameVal: "a.png"}})
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = f.Match(&mockContext{nil, &mockFileInfo{NameVal: "dir", IsDirVal: true}})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestEXIFFilter_MatchCorruptedEXIF(t *testing.T) {
+	f := loadEXIFFilter(t, map[string]interface{}{})
+
+	// APP1 segment announcing EXIF data that is not a TIFF structure
+	jpeg := []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x0E}
+	jpeg = append(jpeg, "Exif\x00\x00garbage"...)
+
+	_, err := f.Match(&mockContext{jpeg, &mockFileInfo{NameVal: "a.jpg"}})
+	assert.Error(t, err)
+}
+
+func TestEXIFFilter_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"exists": false, "make": []string{"Apple"}},
+		{"orientation": []int{9}},
+		{"taken_after": "yesterday"},
+		{"taken_after": "2024-01-01", "taken_before": "2023-01-01"},
+		{"max_bytes": -1},
+		{"unknown_key": true},
+	}
+	for _, config := range invalid {
+		f := &EXIFFilter{}
+		assert.Error(t, f.LoadConfig(config), "config %v", config)
+	}
+}
+
+func TestEXIFFilter_Selector(t *testing.T) {
+	assert.Equal(t, "exif", (&EXIFFilter{}).Selector())
+}
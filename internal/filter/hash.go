@@ -0,0 +1,290 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/polocto/FolderFlow/pkg/ffplugin/filter"
+)
+
+// hashListCheckInterval limits how often list files are checked for changes.
+const hashListCheckInterval = 2 * time.Second
+
+// HashFilter matches files whose content hash appears in known lists.
+// Lists use the sha256sum format; MD5 and SHA-1 entries are recognized by their length.
+type HashFilter struct {
+	Lists  []string `yaml:"lists"`
+	Hashes []string `yaml:"hashes"`
+	// Listed selects listed files (allowlist, the default) or unlisted files (blocklist)
+	Listed *bool `yaml:"listed"`
+
+	state *hashState
+}
+
+// hashSet holds the known hashes by algorithm, as lowercase hex strings.
+type hashSet struct {
+	sha256 map[string]struct{}
+	sha1   map[string]struct{}
+	md5    map[string]struct{}
+}
+
+func newHashSet() *hashSet {
+	return &hashSet{
+		sha256: make(map[string]struct{}),
+		sha1:   make(map[string]struct{}),
+		md5:    make(map[string]struct{}),
+	}
+}
+
+// add registers a hex digest, choosing the algorithm from its length.
+func (s *hashSet) add(digest string) error {
+	digest = strings.ToLower(digest)
+	if _, err := hex.DecodeString(digest); err != nil {
+		return fmt.Errorf("invalid hash %q", digest)
+	}
+	switch len(digest) {
+	case 2 * sha256.Size:
+		s.sha256[digest] = struct{}{}
+	case 2 * sha1.Size:
+		s.sha1[digest] = struct{}{}
+	case 2 * md5.Size:
+		s.md5[digest] = struct{}{}
+	default:
+		return fmt.Errorf("invalid hash %q: not a SHA-256, SHA-1 or MD5 digest", digest)
+	}
+	return nil
+}
+
+// hashListFile is a list file with the state it had when it was loaded.
+type hashListFile struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// hashState is shared by the workers classifying files concurrently.
+type hashState struct {
+	mu        sync.RWMutex
+	set       *hashSet
+	inline    []string
+	files     []hashListFile
+	lastCheck time.Time
+}
+
+func (f *HashFilter) Match(ctx filter.Context) (bool, error) {
+	if ctx == nil {
+		return false, fmt.Errorf("context is nil")
+	}
+	if ctx.IsDir() {
+		return false, nil
+	}
+
+	f.state.reloadIfChanged()
+	f.state.mu.RLock()
+	set := f.state.set
+	f.state.mu.RUnlock()
+
+	found, err := containsHash(ctx, set)
+	if err != nil {
+		return false, fmt.Errorf("cannot hash %q: %w", ctx.BaseName(), err)
+	}
+	return found == f.listed(), nil
+}
+
+func containsHash(ctx filter.Context, set *hashSet) (bool, error) {
+	if !ctx.Info().Mode().IsRegular() {
+		// Symbolic links and special files have no content to hash
+		return false, nil
+	}
+	if len(set.sha256) > 0 {
+		sum, err := ctx.Hash()
+		if err != nil {
+			return false, err
+		}
+		if _, ok := set.sha256[hex.EncodeToString(sum[:])]; ok {
+			return true, nil
+		}
+	}
+	if len(set.sha1) == 0 && len(set.md5) == 0 {
+		return false, nil
+	}
+
+	// MD5 and SHA-1 are not cached: compute both in a single pass
+	md5Hash, sha1Hash := md5.New(), sha1.New()
+	w := io.MultiWriter(md5Hash, sha1Hash)
+	if err := ctx.ReadChunks(4096, func(chunk []byte) error {
+		_, err := w.Write(chunk)
+		return err
+	}); err != nil {
+		return false, err
+	}
+	return hasDigest(set.md5, md5Hash) || hasDigest(set.sha1, sha1Hash), nil
+}
+
+func hasDigest(set map[string]struct{}, h hash.Hash) bool {
+	_, ok := set[hex.EncodeToString(h.Sum(nil))]
+	return ok
+}
+
+func (f *HashFilter) listed() bool {
+	return f.Listed == nil || *f.Listed
+}
+
+func (f *HashFilter) Selector() string {
+	return "hash"
+}
+
+func (f *HashFilter) LoadConfig(config map[string]interface{}) error {
+	var cfg HashFilter
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+	if len(cfg.Lists) == 0 && len(cfg.Hashes) == 0 {
+		return fmt.Errorf("'lists' or 'hashes' must be set")
+	}
+
+	state := &hashState{inline: cfg.Hashes}
+	for _, path := range cfg.Lists {
+		state.files = append(state.files, hashListFile{path: path})
+	}
+	set, err := state.load()
+	if err != nil {
+		return err
+	}
+	state.set = set
+	state.lastCheck = time.Now()
+	cfg.state = state
+
+	*f = cfg
+
+	slog.Debug("Loading hash was successful", "lists", f.Lists,
+		"sha256", len(set.sha256), "sha1", len(set.sha1), "md5", len(set.md5))
+	return nil
+}
+
+// load reads the inline hashes and every list file, recording their state.
+func (s *hashState) load() (*hashSet, error) {
+	set := newHashSet()
+	for _, digest := range s.inline {
+		if err := set.add(strings.TrimSpace(digest)); err != nil {
+			return nil, err
+		}
+	}
+	for i := range s.files {
+		file, err := readHashList(s.files[i].path, set)
+		if err != nil {
+			return nil, err
+		}
+		s.files[i] = file
+	}
+	return set, nil
+}
+
+// reloadIfChanged reloads the lists when one of the files was modified.
+// On failure the previous lists are kept, so that a file being rewritten
+// does not make every file match or fail.
+func (s *hashState) reloadIfChanged() {
+	s.mu.RLock()
+	recent := len(s.files) == 0 || time.Since(s.lastCheck) < hashListCheckInterval
+	s.mu.RUnlock()
+	if recent {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.files) == 0 || time.Since(s.lastCheck) < hashListCheckInterval {
+		return
+	}
+	s.lastCheck = time.Now()
+
+	changed := false
+	for _, file := range s.files {
+		info, err := os.Stat(file.path)
+		if err != nil || !info.ModTime().Equal(file.modTime) || info.Size() != file.size {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return
+	}
+
+	files := append([]hashListFile(nil), s.files...)
+	set, err := s.load()
+	if err != nil {
+		s.files = files
+		slog.Warn("Cannot reload hash lists, keeping the previous ones", "err", err)
+		return
+	}
+	s.set = set
+	slog.Info("Hash lists reloaded", "sha256", len(set.sha256), "sha1", len(set.sha1), "md5", len(set.md5))
+}
+
+// readHashList adds the hashes of a sha256sum formatted file to set.
+// Lines are "<hex digest>  <file name>", "<hex digest> *<file name>" or a bare digest.
+// Empty lines and lines starting with '#' are ignored.
+func readHashList(path string, set *hashSet) (hashListFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return hashListFile{}, fmt.Errorf("cannot open hash list %q: %w", path, err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			slog.Warn("failed to close file : ", "path", path, "err", err)
+		}
+	}()
+
+	info, err := file.Stat()
+	if err != nil {
+		return hashListFile{}, fmt.Errorf("cannot stat hash list %q: %w", path, err)
+	}
+
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		digest := strings.Fields(line)[0]
+		digest = strings.TrimPrefix(digest, "\\") // escaped file names
+		if err := set.add(digest); err != nil {
+			return hashListFile{}, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return hashListFile{}, fmt.Errorf("cannot read hash list %q: %w", path, err)
+	}
+	return hashListFile{path: path, modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+func init() {
+	filter.RegisterFilter("hash", func() filter.Filter {
+		return &HashFilter{}
+	})
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func hexSum(sum []byte) string {
+	return hex.EncodeToString(sum)
+}
+
+func TestHashFilter_Match(t *testing.T) {
+	logo := []byte("company logo")
+	wallpaper := []byte("default wallpaper")
+	other := []byte("holiday picture")
+	banner := []byte("intranet banner")
+
+	logoSHA := sha256.Sum256(logo)
+	wallpaperMD5 := md5.Sum(wallpaper)
+	otherSHA1 := sha1.Sum(other)
+	bannerSHA := sha256.Sum256(banner)
+
+	list := filepath.Join(t.TempDir(), "known.sha256")
+	require.NoError(t, os.WriteFile(list, []byte(
+		"# known files\n"+
+			hexSum(logoSHA[:])+"  logo.png\n"+
+			"\n"+
+			hexSum(wallpaperMD5[:])+" *wallpaper.jpg\n"+
+			hexSum(bannerSHA[:])+"\tbanner.png\n",
+	), 0o644))
+
+	testCases := []struct {
+		name     string
+		config   map[string]interface{}
+		content  []byte
+		expected bool
+	}{
+		{"sha256 listed", map[string]interface{}{"lists": []string{list}}, logo, true},
+		{"md5 listed", map[string]interface{}{"lists": []string{list}}, wallpaper, true},
+		{"tab separated", map[string]interface{}{"lists": []string{list}}, banner, true},
+		{"not listed", map[string]interface{}{"lists": []string{list}}, other, false},
+		{"blocklist", map[string]interface{}{"lists": []string{list}, "listed": false}, other, true},
+		{"blocklist listed", map[string]interface{}{"lists": []string{list}, "listed": false}, logo, false},
+		{"inline sha1", map[string]interface{}{"hashes": []string{hexSum(otherSHA1[:])}}, other, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &HashFilter{}
+			require.NoError(t, f.LoadConfig(tc.config))
+
+			ok, err := f.Match(&mockContext{tc.content, &mockFileInfo{NameVal: "a.png"}})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ok)
+		})
+	}
+}
+
+func TestHashFilter_ReloadsChangedList(t *testing.T) {
+	content := []byte("new sample")
+	sum := sha256.Sum256(content)
+	list := filepath.Join(t.TempDir(), "samples.sha256")
+	require.NoError(t, os.WriteFile(list, []byte("# empty for now\n"), 0o644))
+
+	f := &HashFilter{}
+	require.NoError(t, f.LoadConfig(map[string]interface{}{"lists": []string{list}}))
+	ctx := &mockContext{content, &mockFileInfo{NameVal: "sample.bin"}}
+
+	ok, err := f.Match(ctx)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, os.WriteFile(list, []byte(hexSum(sum[:])+"  sample.bin\n"), 0o644))
+	f.state.lastCheck = time.Time{}
+
+	ok, err = f.Match(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// A broken list keeps the previous entries
+	require.NoError(t, os.WriteFile(list, []byte("not a hash\n"), 0o644))
+	f.state.lastCheck = time.Time{}
+
+	ok, err = f.Match(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestHashFilter_LoadConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.sha256")
+	require.NoError(t, os.WriteFile(bad, []byte("xyz  file\n"), 0o644))
+
+	invalid := []map[string]interface{}{
+		{},
+		{"hashes": []string{"abc"}},
+		{"lists": []string{filepath.Join(dir, "missing.sha256")}},
+		{"lists": []string{bad}},
+	}
+	for _, config := range invalid {
+		f := &HashFilter{}
+		assert.Error(t, f.LoadConfig(config), "config %v", config)
+	}
+}
@@ -15,6 +15,7 @@ package filter
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"io"
 	"io/fs"
 	"time"
@@ -97,3 +98,31 @@ func (mc *mockContext) ReadChunks(chunkSize int, fn func(chunk []byte) error) er
 	}
 	return nil
 }
+
+func (mc *mockContext) Hash() ([sha256.Size]byte, error) {
+	if mc.content == nil {
+		return [sha256.Size]byte{}, fs.ErrInvalid
+	}
+	return sha256.Sum256(mc.content), nil
+}
+
+func (mc *mockContext) Xattr(name string) ([]byte, bool, error) {
+	return nil, false, nil
+}
+
+// SetAttribute discards attributes: tests checking them use attributeContext.
+func (mc *mockContext) SetAttribute(name, value string) {}
+
+// attributeContext records the attributes set by filters.
+type attributeContext struct {
+	*mockContext
+	attributes map[string]string
+}
+
+func newAttributeContext(content []byte, info fs.FileInfo) *attributeContext {
+	return &attributeContext{&mockContext{content, info}, make(map[string]string)}
+}
+
+func (ac *attributeContext) SetAttribute(name, value string) {
+	ac.attributes[name] = value
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+
+	// Register the decoders used by image.DecodeConfig
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+
+	"github.com/polocto/FolderFlow/internal/metadata"
+	"github.com/polocto/FolderFlow/pkg/ffplugin/filter"
+)
+
+// defaultImageMaxBytes leaves room for the EXIF thumbnails stored before the JPEG frame header.
+const defaultImageMaxBytes = 1 << 20
+
+const (
+	orientationLandscape = "landscape"
+	orientationPortrait  = "portrait"
+	orientationSquare    = "square"
+)
+
+// ImageFilter matches images on their pixel dimensions.
+// Only the image header is decoded, the pixels are never read.
+type ImageFilter struct {
+	MinWidth        int      `yaml:"min_width"`
+	MaxWidth        int      `yaml:"max_width"`
+	MinHeight       int      `yaml:"min_height"`
+	MaxHeight       int      `yaml:"max_height"`
+	MinMegapixels   float64  `yaml:"min_megapixels"`
+	MaxMegapixels   float64  `yaml:"max_megapixels"`
+	MinAspectRatio  float64  `yaml:"min_aspect_ratio"`
+	MaxAspectRatio  float64  `yaml:"max_aspect_ratio"`
+	Orientation     []string `yaml:"orientation"`
+	SquareTolerance float64  `yaml:"square_tolerance"`
+	Formats         []string `yaml:"formats"`
+	// ExifRotation swaps width and height of JPEG files rotated by their EXIF orientation
+	ExifRotation bool  `yaml:"exif_rotation"`
+	MaxBytes     int64 `yaml:"max_bytes"`
+}
+
+// imageHeader is the decoded size of an image.
+type imageHeader struct {
+	width  int
+	height int
+	format string
+}
+
+func (f *ImageFilter) Match(ctx filter.Context) (bool, error) {
+	if ctx == nil {
+		return false, fmt.Errorf("context is nil")
+	}
+	if ctx.IsDir() {
+		return false, nil
+	}
+
+	header, err := f.decodeHeader(ctx)
+	if err != nil {
+		return false, err
+	}
+	return f.matchHeader(header), nil
+}
+
+func (f *ImageFilter) decodeHeader(ctx filter.Context) (imageHeader, error) {
+	var header imageHeader
+	err := ctx.WithInputLimited(f.maxBytes(), func(r io.Reader) error {
+		// Keep what DecodeConfig consumed so the EXIF block can be parsed afterwards
+		var consumed bytes.Buffer
+		cfg, format, err := image.DecodeConfig(io.TeeReader(r, &consumed))
+		if err != nil {
+			return err
+		}
+		header = imageHeader{width: cfg.Width, height: cfg.Height, format: format}
+
+		if f.ExifRotation && format == "jpeg" {
+			if meta, err := metadata.ParseEXIF(consumed.Bytes()); err == nil && meta.Orientation >= 5 {
+				header.width, header.height = header.height, header.width
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return header, fmt.Errorf("cannot decode image header of %q: %w", ctx.BaseName(), err)
+	}
+	return header, nil
+}
+
+func (f *ImageFilter) matchHeader(h imageHeader) bool {
+	if len(f.Formats) > 0 && !slices.Contains(f.Formats, h.format) {
+		return false
+	}
+	if !inIntRange(h.width, f.MinWidth, f.MaxWidth) || !inIntRange(h.height, f.MinHeight, f.MaxHeight) {
+		return false
+	}
+
+	megapixels := float64(h.width) * float64(h.height) / 1e6
+	if !inFloatRange(megapixels, f.MinMegapixels, f.MaxMegapixels) {
+		return false
+	}
+
+	if h.height == 0 {
+		return false
+	}
+	ratio := float64(h.width) / float64(h.height)
+	if !inFloatRange(ratio, f.MinAspectRatio, f.MaxAspectRatio) {
+		return false
+	}
+
+	if len(f.Orientation) > 0 && !slices.Contains(f.Orientation, f.orientation(h)) {
+		return false
+	}
+	return true
+}
+
+func (f *ImageFilter) orientation(h imageHeader) string {
+	longest := math.Max(float64(h.width), float64(h.height))
+	if math.Abs(float64(h.width-h.height)) <= f.SquareTolerance*longest {
+		return orientationSquare
+	}
+	if h.width > h.height {
+		return orientationLandscape
+	}
+	return orientationPortrait
+}
+
+func (f *ImageFilter) maxBytes() int64 {
+	if f.MaxBytes > 0 {
+		return f.MaxBytes
+	}
+	return defaultImageMaxBytes
+}
+
+func (f *ImageFilter) Selector() string {
+	return "image"
+}
+
+func (f *ImageFilter) LoadConfig(config map[string]interface{}) error {
+	var cfg ImageFilter
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	if cfg.MinWidth < 0 || cfg.MaxWidth < 0 || cfg.MinHeight < 0 || cfg.MaxHeight < 0 ||
+		cfg.MinMegapixels < 0 || cfg.MaxMegapixels < 0 ||
+		cfg.MinAspectRatio < 0 || cfg.MaxAspectRatio < 0 || cfg.MaxBytes < 0 {
+		return fmt.Errorf("image filter limits cannot be negative")
+	}
+	if cfg.MaxWidth > 0 && cfg.MinWidth > cfg.MaxWidth {
+		return fmt.Errorf("'min_width' is greater than 'max_width'")
+	}
+	if cfg.MaxHeight > 0 && cfg.MinHeight > cfg.MaxHeight {
+		return fmt.Errorf("'min_height' is greater than 'max_height'")
+	}
+	if cfg.MaxMegapixels > 0 && cfg.MinMegapixels > cfg.MaxMegapixels {
+		return fmt.Errorf("'min_megapixels' is greater than 'max_megapixels'")
+	}
+	if cfg.MaxAspectRatio > 0 && cfg.MinAspectRatio > cfg.MaxAspectRatio {
+		return fmt.Errorf("'min_aspect_ratio' is greater than 'max_aspect_ratio'")
+	}
+	if cfg.SquareTolerance < 0 || cfg.SquareTolerance >= 1 {
+		return fmt.Errorf("'square_tolerance' must be in [0, 1)")
+	}
+	for i, o := range cfg.Orientation {
+		o = strings.ToLower(o)
+		switch o {
+		case orientationLandscape, orientationPortrait, orientationSquare:
+			cfg.Orientation[i] = o
+		default:
+			return fmt.Errorf("invalid orientation %q, must be 'landscape', 'portrait' or 'square'", o)
+		}
+	}
+	for i, format := range cfg.Formats {
+		cfg.Formats[i] = strings.ToLower(format)
+	}
+
+	*f = cfg
+
+	slog.Debug("Loading image was successful", "config", config)
+	return nil
+}
+
+// inIntRange reports whether v is in [minV, maxV]. A zero bound is not checked.
+func inIntRange(v, minV, maxV int) bool {
+	return (minV == 0 || v >= minV) && (maxV == 0 || v <= maxV)
+}
+
+// inFloatRange reports whether v is in [minV, maxV]. A zero bound is not checked.
+func inFloatRange(v, minV, maxV float64) bool {
+	return (minV == 0 || v >= minV) && (maxV == 0 || v <= maxV)
+}
+
+func init() {
+	filter.RegisterFilter("image", func() filter.Filter {
+		return &ImageFilter{}
+	})
+}
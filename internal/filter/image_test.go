@@ -0,0 +1,110 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pngOfSize(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h))))
+	return buf.Bytes()
+}
+
+func TestImageFilter_Match(t *testing.T) {
+	wallpaper := pngOfSize(t, 1920, 1080)
+	thumbnail := pngOfSize(t, 150, 150)
+	scan := pngOfSize(t, 2480, 3508)
+
+	testCases := []struct {
+		name     string
+		config   map[string]interface{}
+		content  []byte
+		expected bool
+	}{
+		{"min width", map[string]interface{}{"min_width": 1920}, wallpaper, true},
+		{"max width", map[string]interface{}{"max_width": 200}, wallpaper, false},
+		{"height range", map[string]interface{}{"min_height": 100, "max_height": 200}, thumbnail, true},
+		{"megapixels", map[string]interface{}{"min_megapixels": 8.0}, scan, true},
+		{"megapixels too low", map[string]interface{}{"min_megapixels": 8.0}, wallpaper, false},
+		{
+			"16:9 aspect ratio",
+			map[string]interface{}{"min_aspect_ratio": 1.77, "max_aspect_ratio": 1.78},
+			wallpaper, true,
+		},
+		{"landscape", map[string]interface{}{"orientation": []string{"landscape"}}, wallpaper, true},
+		{"portrait", map[string]interface{}{"orientation": []string{"Portrait"}}, scan, true},
+		{"square", map[string]interface{}{"orientation": []string{"square"}}, thumbnail, true},
+		{"not square", map[string]interface{}{"orientation": []string{"square"}}, scan, false},
+		{"format", map[string]interface{}{"formats": []string{"png"}}, thumbnail, true},
+		{"other format", map[string]interface{}{"formats": []string{"jpeg"}}, thumbnail, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &ImageFilter{}
+			require.NoError(t, f.LoadConfig(tc.config))
+
+			ok, err := f.Match(&mockContext{tc.content, &mockFileInfo{NameVal: "a.png"}})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ok)
+		})
+	}
+}
+
+func TestImageFilter_SquareTolerance(t *testing.T) {
+	f := &ImageFilter{}
+	require.NoError(t, f.LoadConfig(map[string]interface{}{
+		"orientation":      []string{"square"},
+		"square_tolerance": 0.05,
+	}))
+
+	assert.True(t, f.matchHeader(imageHeader{width: 1000, height: 960}))
+	assert.False(t, f.matchHeader(imageHeader{width: 1000, height: 900}))
+}
+
+func TestImageFilter_DecodeErrorIsReported(t *testing.T) {
+	f := &ImageFilter{}
+	require.NoError(t, f.LoadConfig(map[string]interface{}{"min_width": 10}))
+
+	_, err := f.Match(&mockContext{[]byte("not an image"), &mockFileInfo{NameVal: "a.png"}})
+	assert.Error(t, err)
+
+	// Header cut by the read limit
+	f.MaxBytes = 10
+	_, err = f.Match(&mockContext{pngOfSize(t, 10, 10), &mockFileInfo{NameVal: "a.png"}})
+	assert.Error(t, err)
+}
+
+func TestImageFilter_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"min_width": 200, "max_width": 100},
+		{"min_megapixels": -1.0},
+		{"orientation": []string{"diagonal"}},
+		{"square_tolerance": 1.5},
+		{"min_aspect_ratio": 2.0, "max_aspect_ratio": 1.0},
+	}
+	for _, config := range invalid {
+		f := &ImageFilter{}
+		assert.Error(t, f.LoadConfig(config), "config %v", config)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/polocto/FolderFlow/pkg/ffplugin/filter"
+	"gopkg.in/yaml.v3"
+)
+
+// File types accepted by the mode filter.
+const (
+	fileTypeRegular     = "regular"
+	fileTypeDir         = "dir"
+	fileTypeSymlink     = "symlink"
+	fileTypeFIFO        = "fifo"
+	fileTypeSocket      = "socket"
+	fileTypeBlockDevice = "block_device"
+	fileTypeCharDevice  = "char_device"
+)
+
+// ModeFilter matches files on their type and permission bits.
+type ModeFilter struct {
+	Types         []string `yaml:"types"`
+	Executable    *bool    `yaml:"executable"`
+	WorldWritable *bool    `yaml:"world_writable"`
+	Setuid        *bool    `yaml:"setuid"`
+	Setgid        *bool    `yaml:"setgid"`
+	Sticky        *bool    `yaml:"sticky"`
+	// AllBits, AnyBits and NoBits are octal permission masks such as "0750"
+	AllBits PermMask `yaml:"all_bits"`
+	AnyBits PermMask `yaml:"any_bits"`
+	NoBits  PermMask `yaml:"no_bits"`
+
+	allBits fs.FileMode
+	anyBits fs.FileMode
+	noBits  fs.FileMode
+}
+
+// PermMask is an octal permission mask written as a string such as "0750".
+// An unquoted YAML number such as 0750 is decoded as octal by YAML itself and
+// is kept as such instead of being read again as octal digits.
+type PermMask string
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (m *PermMask) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!int" {
+		var bits uint64
+		if err := node.Decode(&bits); err != nil {
+			return fmt.Errorf("invalid permission mask %q: %w", node.Value, err)
+		}
+		*m = PermMask("0" + strconv.FormatUint(bits, 8))
+		return nil
+	}
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return err
+	}
+	*m = PermMask(s)
+	return nil
+}
+
+func (f *ModeFilter) Match(ctx filter.Context) (bool, error) {
+	if ctx == nil {
+		return false, fmt.Errorf("context is nil")
+	}
+	return f.matchMode(ctx.Info().Mode()), nil
+}
+
+func (f *ModeFilter) matchMode(mode fs.FileMode) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, fileType(mode)) {
+		return false
+	}
+
+	perm := mode.Perm()
+	flags := []struct {
+		want *bool
+		set  bool
+	}{
+		{f.Executable, perm&0o111 != 0},
+		{f.WorldWritable, perm&0o002 != 0},
+		{f.Setuid, mode&fs.ModeSetuid != 0},
+		{f.Setgid, mode&fs.ModeSetgid != 0},
+		{f.Sticky, mode&fs.ModeSticky != 0},
+	}
+	for _, flag := range flags {
+		if flag.want != nil && *flag.want != flag.set {
+			return false
+		}
+	}
+
+	if perm&f.allBits != f.allBits {
+		return false
+	}
+	if f.anyBits != 0 && perm&f.anyBits == 0 {
+		return false
+	}
+	return perm&f.noBits == 0
+}
+
+// fileType returns the name of the type of a file mode.
+func fileType(mode fs.FileMode) string {
+	switch {
+	case mode.IsRegular():
+		return fileTypeRegular
+	case mode.IsDir():
+		return fileTypeDir
+	case mode&fs.ModeSymlink != 0:
+		return fileTypeSymlink
+	case mode&fs.ModeNamedPipe != 0:
+		return fileTypeFIFO
+	case mode&fs.ModeSocket != 0:
+		return fileTypeSocket
+	case mode&fs.ModeCharDevice != 0:
+		return fileTypeCharDevice
+	case mode&fs.ModeDevice != 0:
+		return fileTypeBlockDevice
+	default:
+		return ""
+	}
+}
+
+func (f *ModeFilter) Selector() string {
+	return "mode"
+}
+
+func (f *ModeFilter) LoadConfig(config map[string]interface{}) error {
+	var cfg ModeFilter
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	for i, t := range cfg.Types {
+		t = strings.ToLower(t)
+		switch t {
+		case fileTypeRegular, fileTypeDir, fileTypeSymlink, fileTypeFIFO,
+			fileTypeSocket, fileTypeBlockDevice, fileTypeCharDevice:
+			cfg.Types[i] = t
+		default:
+			return fmt.Errorf("invalid type %q, must be one of regular, dir, symlink, fifo, socket, block_device, char_device", t)
+		}
+	}
+
+	var err error
+	if cfg.allBits, err = parsePermBits("all_bits", cfg.AllBits); err != nil {
+		return err
+	}
+	if cfg.anyBits, err = parsePermBits("any_bits", cfg.AnyBits); err != nil {
+		return err
+	}
+	if cfg.noBits, err = parsePermBits("no_bits", cfg.NoBits); err != nil {
+		return err
+	}
+	if cfg.allBits&cfg.noBits != 0 {
+		return fmt.Errorf("'all_bits' and 'no_bits' overlap")
+	}
+
+	*f = cfg
+
+	slog.Debug("Loading mode was successful", "config", config)
+	return nil
+}
+
+// parsePermBits parses an optional octal permission mask.
+func parsePermBits(key string, value PermMask) (fs.FileMode, error) {
+	if value == "" {
+		return 0, nil
+	}
+	bits, err := strconv.ParseUint(string(value), 8, 32)
+	if err != nil || bits > 0o777 {
+		return 0, fmt.Errorf("invalid '%s' %q, expected an octal mask such as \"0755\"", key, value)
+	}
+	return fs.FileMode(bits), nil
+}
+
+func init() {
+	filter.RegisterFilter("mode", func() filter.Filter {
+		return &ModeFilter{}
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestModeFilter_Match(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   map[string]interface{}
+		mode     fs.FileMode
+		expected bool
+	}{
+		{"executable", map[string]interface{}{"executable": true}, 0o755, true},
+		{"not executable", map[string]interface{}{"executable": true}, 0o644, false},
+		{"world writable", map[string]interface{}{"world_writable": true}, 0o666, true},
+		{"not world writable", map[string]interface{}{"world_writable": false}, 0o666, false},
+		{"setuid", map[string]interface{}{"setuid": true}, 0o755 | fs.ModeSetuid, true},
+		{"setgid", map[string]interface{}{"setgid": true}, 0o755, false},
+		{"sticky dir", map[string]interface{}{"sticky": true, "types": []string{"dir"}}, 0o777 | fs.ModeDir | fs.ModeSticky, true},
+		{"all bits", map[string]interface{}{"all_bits": "0750"}, 0o755, true},
+		{"all bits missing", map[string]interface{}{"all_bits": "0770"}, 0o755, false},
+		{"any bits", map[string]interface{}{"any_bits": "0022"}, 0o620, true},
+		{"no bits", map[string]interface{}{"no_bits": "0007"}, 0o750, true},
+		{"no bits set", map[string]interface{}{"no_bits": "0007"}, 0o754, false},
+		{"regular", map[string]interface{}{"types": []string{"regular"}}, 0o644, true},
+		{"symlink", map[string]interface{}{"types": []string{"symlink"}}, 0o777 | fs.ModeSymlink, true},
+		{"fifo", map[string]interface{}{"types": []string{"FIFO", "socket"}}, 0o644 | fs.ModeNamedPipe, true},
+		{"char device", map[string]interface{}{"types": []string{"char_device"}}, 0o600 | fs.ModeDevice | fs.ModeCharDevice, true},
+		{"block device", map[string]interface{}{"types": []string{"char_device"}}, 0o600 | fs.ModeDevice, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &ModeFilter{}
+			require.NoError(t, f.LoadConfig(tc.config))
+
+			ok, err := f.Match(&mockContext{nil, &mockFileInfo{NameVal: "a", ModeVal: tc.mode}})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ok)
+		})
+	}
+}
+
+func TestModeFilter_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"types": []string{"door"}},
+		{"all_bits": "rwx"},
+		{"any_bits": "01777"},
+		{"all_bits": "0700", "no_bits": "0100"},
+	}
+	for _, config := range invalid {
+		f := &ModeFilter{}
+		assert.Error(t, f.LoadConfig(config), "config %v", config)
+	}
+}
+
+func TestModeFilter_UnquotedMask(t *testing.T) {
+	testCases := []struct {
+		yaml string
+		want fs.FileMode
+	}{
+		{"all_bits: 0644", 0o644},
+		{"all_bits: 0o755", 0o755},
+		{`all_bits: "0644"`, 0o644},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.yaml, func(t *testing.T) {
+			var config map[string]interface{}
+			require.NoError(t, yaml.Unmarshal([]byte(tc.yaml), &config))
+
+			f := &ModeFilter{}
+			require.NoError(t, f.LoadConfig(config))
+			assert.Equal(t, tc.want, f.allBits)
+		})
+	}
+
+	var config map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte("no_bits: -1"), &config))
+	assert.Error(t, (&ModeFilter{}).LoadConfig(config))
+}
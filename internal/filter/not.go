@@ -0,0 +1,77 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/polocto/FolderFlow/pkg/ffplugin/filter"
+)
+
+// NotFilter inverts the result of another filter, configured with the same
+// name and config keys as the filters of a destination.
+type NotFilter struct {
+	Name   string                 `yaml:"name"`
+	Config map[string]interface{} `yaml:"config"`
+
+	filter filter.Filter
+}
+
+func (f *NotFilter) Match(ctx filter.Context) (bool, error) {
+	if ctx == nil {
+		return false, fmt.Errorf("context is nil")
+	}
+
+	matched, err := f.filter.Match(ctx)
+	if err != nil {
+		// An error is not a negative match
+		return false, err
+	}
+	return !matched, nil
+}
+
+func (f *NotFilter) Selector() string {
+	return "not"
+}
+
+func (f *NotFilter) LoadConfig(config map[string]interface{}) error {
+	var cfg NotFilter
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	if cfg.Name == "" {
+		return fmt.Errorf("'name' config cannot be empty")
+	}
+	inner, err := filter.NewFilter(cfg.Name)
+	if err != nil {
+		return fmt.Errorf("failed to create filter '%s': %w", cfg.Name, err)
+	}
+	if err := inner.LoadConfig(cfg.Config); err != nil {
+		return fmt.Errorf("failed to load config for filter '%s': %w", cfg.Name, err)
+	}
+	cfg.filter = inner
+
+	*f = cfg
+
+	slog.Debug("Loading not was successful", "config", config)
+	return nil
+}
+
+func init() {
+	filter.RegisterFilter("not", func() filter.Filter {
+		return &NotFilter{}
+	})
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotFilter_Match(t *testing.T) {
+	f := &NotFilter{}
+	require.NoError(t, f.LoadConfig(map[string]interface{}{
+		"name":   "extensions",
+		"config": map[string]interface{}{"extensions": []string{".jpg"}},
+	}))
+
+	ok, err := f.Match(&mockContext{nil, &mockFileInfo{NameVal: "photo.jpg"}})
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = f.Match(&mockContext{nil, &mockFileInfo{NameVal: "notes.txt"}})
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestNotFilter_Error(t *testing.T) {
+	f := &NotFilter{}
+	require.NoError(t, f.LoadConfig(map[string]interface{}{"name": "valid"}))
+
+	// Reading fails: the error is returned instead of a match
+	ok, err := f.Match(&mockContext{nil, &mockFileInfo{NameVal: "a.json"}})
+	assert.Error(t, err)
+	assert.False(t, ok)
+}
+
+func TestNotFilter_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{},
+		{"name": "unknown"},
+		{"name": "extensions", "config": map[string]interface{}{"unknown": true}},
+	}
+	for _, config := range invalid {
+		f := &NotFilter{}
+		assert.Error(t, f.LoadConfig(config), "config %v", config)
+	}
+}
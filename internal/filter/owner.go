@@ -0,0 +1,125 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"fmt"
+	"log/slog"
+	"os/user"
+	"slices"
+	"strconv"
+
+	"github.com/polocto/FolderFlow/pkg/ffplugin/filter"
+)
+
+// OwnerFilter matches files on the user and group owning them.
+// Users and groups are names or numeric ids; names are resolved when the configuration is loaded.
+type OwnerFilter struct {
+	Users  []string `yaml:"users"`
+	Groups []string `yaml:"groups"`
+
+	uids []uint32
+	gids []uint32
+}
+
+func (f *OwnerFilter) Match(ctx filter.Context) (bool, error) {
+	if ctx == nil {
+		return false, fmt.Errorf("context is nil")
+	}
+
+	uid, gid, ok := fileOwner(ctx.Info())
+	if !ok {
+		slog.Debug("Owner not available", "basename", ctx.BaseName())
+		return false, nil
+	}
+	if len(f.uids) > 0 && !slices.Contains(f.uids, uid) {
+		return false, nil
+	}
+	if len(f.gids) > 0 && !slices.Contains(f.gids, gid) {
+		return false, nil
+	}
+	return true, nil
+}
+
+func (f *OwnerFilter) Selector() string {
+	return "owner"
+}
+
+func (f *OwnerFilter) LoadConfig(config map[string]interface{}) error {
+	var cfg OwnerFilter
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	if !ownerSupported {
+		return fmt.Errorf("the owner filter is not supported on this platform")
+	}
+	if len(cfg.Users) == 0 && len(cfg.Groups) == 0 {
+		return fmt.Errorf("'users' or 'groups' must be set")
+	}
+
+	for _, name := range cfg.Users {
+		uid, err := resolveID(name, func(name string) (string, error) {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return u.Uid, nil
+		})
+		if err != nil {
+			return fmt.Errorf("invalid user %q: %w", name, err)
+		}
+		cfg.uids = append(cfg.uids, uid)
+	}
+	for _, name := range cfg.Groups {
+		gid, err := resolveID(name, func(name string) (string, error) {
+			g, err := user.LookupGroup(name)
+			if err != nil {
+				return "", err
+			}
+			return g.Gid, nil
+		})
+		if err != nil {
+			return fmt.Errorf("invalid group %q: %w", name, err)
+		}
+		cfg.gids = append(cfg.gids, gid)
+	}
+
+	*f = cfg
+
+	slog.Debug("Loading owner was successful", "config", config, "uids", f.uids, "gids", f.gids)
+	return nil
+}
+
+// resolveID parses a numeric id or resolves a name with lookup.
+func resolveID(name string, lookup func(string) (string, error)) (uint32, error) {
+	if id, err := strconv.ParseUint(name, 10, 32); err == nil {
+		return uint32(id), nil
+	}
+	id, err := lookup(name)
+	if err != nil {
+		return 0, err
+	}
+	parsed, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("id %q is not numeric", id)
+	}
+	return uint32(parsed), nil
+}
+
+func init() {
+	filter.RegisterFilter("owner", func() filter.Filter {
+		return &OwnerFilter{}
+	})
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+//go:build unix
+
+package filter
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	filehandler "github.com/polocto/FolderFlow/internal/fileHandler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOwnerFilter_Match(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0o644))
+	file, err := filehandler.NewContextFile(path)
+	require.NoError(t, err)
+	ctx, err := NewContextFilter(file)
+	require.NoError(t, err)
+
+	uid := strconv.Itoa(os.Getuid())
+	gid := strconv.Itoa(os.Getgid())
+	other := strconv.Itoa(os.Getuid() + 4242)
+
+	testCases := []struct {
+		name     string
+		config   map[string]interface{}
+		expected bool
+	}{
+		{"uid", map[string]interface{}{"users": []string{uid}}, true},
+		{"other uid", map[string]interface{}{"users": []string{other}}, false},
+		{"any uid", map[string]interface{}{"users": []string{other, uid}}, true},
+		{"gid", map[string]interface{}{"groups": []string{gid}}, true},
+		{"uid and other gid", map[string]interface{}{"users": []string{uid}, "groups": []string{other}}, false},
+	}
+	if current, err := user.Current(); err == nil {
+		testCases = append(testCases, struct {
+			name     string
+			config   map[string]interface{}
+			expected bool
+		}{"user name", map[string]interface{}{"users": []string{current.Username}}, true})
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &OwnerFilter{}
+			require.NoError(t, f.LoadConfig(tc.config))
+
+			ok, err := f.Match(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ok)
+		})
+	}
+}
+
+func TestOwnerFilter_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{},
+		{"users": []string{"no-such-user-folderflow"}},
+		{"groups": []string{"no-such-group-folderflow"}},
+	}
+	for _, config := range invalid {
+		f := &OwnerFilter{}
+		assert.Error(t, f.LoadConfig(config), "config %v", config)
+	}
+}
@@ -34,8 +34,15 @@ func (f *RegexFilter) Match(ctx filter.Context) (bool, error) {
 	}
 	basename := ctx.Info().Name()
 	for i, re := range f.compiledRe {
-		if re.MatchString(basename) {
+		if m := re.FindStringSubmatch(basename); m != nil {
 			slog.Debug("Match found", "basename", basename, "pattern", f.Patterns[i])
+			groups := make(map[string]string)
+			for j, name := range re.SubexpNames() {
+				if name != "" && groups[name] == "" {
+					groups[name] = m[j]
+				}
+			}
+			setAttributes(ctx, f.Selector(), groups)
 			return true, nil
 		}
 	}
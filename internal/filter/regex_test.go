@@ -100,6 +100,25 @@ func TestRegexFilterMatch(t *testing.T) {
 	}
 }
 
+func TestRegexFilterAttributes(t *testing.T) {
+	f := &RegexFilter{}
+	if err := f.LoadConfig(map[string]interface{}{
+		"patterns": []string{`^notes`, `^INV-(?P<client>[A-Z]+)-(?P<year>\d{4})(?:-(?P<part>\d+))?`},
+	}); err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+
+	ctx := newAttributeContext(nil, &mockFileInfo{NameVal: "INV-ACME-2024.pdf"})
+	ok, err := f.Match(ctx)
+	if err != nil || !ok {
+		t.Fatalf("Match() = %v, %v, expected a match", ok, err)
+	}
+	expected := map[string]string{"regex.client": "ACME", "regex.year": "2024"}
+	if len(ctx.attributes) != len(expected) || ctx.attributes["regex.client"] != "ACME" || ctx.attributes["regex.year"] != "2024" {
+		t.Errorf("attributes = %v, expected %v", ctx.attributes, expected)
+	}
+}
+
 func TestRegexFilterSelector(t *testing.T) {
 	filter := &RegexFilter{}
 	selector := filter.Selector()
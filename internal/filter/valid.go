@@ -0,0 +1,412 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/polocto/FolderFlow/pkg/ffplugin/filter"
+)
+
+// Formats checked by the valid filter.
+const (
+	validFormatImage = "image"
+	validFormatZip   = "zip"
+	validFormatGzip  = "gzip"
+	validFormatTar   = "tar"
+	validFormatPDF   = "pdf"
+	validFormatJSON  = "json"
+	validFormatXML   = "xml"
+)
+
+var validFormats = []string{
+	validFormatImage, validFormatZip, validFormatGzip, validFormatTar,
+	validFormatPDF, validFormatJSON, validFormatXML,
+}
+
+// pdfTailSize is the amount of data searched for the PDF trailer.
+const pdfTailSize = 2048
+
+const (
+	// maxImagePixels bounds the size of the images decoded, whatever their file size (about 1 GiB in RGBA).
+	maxImagePixels = 256 << 20
+	// minImageDecodeBytes lets small files of plain images, which compress very well, be decoded
+	// even when they exceed the compression ratio limit.
+	minImageDecodeBytes = 256 << 20
+)
+
+var (
+	pdfObjectHeader = regexp.MustCompile(`^\d+\s+\d+\s+obj\b`)
+	xmlEntityDecl   = regexp.MustCompile(`<!ENTITY\s+([^\s%]+)\s+(?:"([^"]*)"|'([^']*)')`)
+)
+
+// ValidFilter matches files whose content is intact: images decode completely,
+// archives have valid checksums, PDF files have a trailer and JSON and XML files are well-formed.
+// Combined with the not filter, it selects corrupt files.
+type ValidFilter struct {
+	Formats []string `yaml:"formats"`
+	// UnsupportedValid makes files of unchecked formats match (the default)
+	UnsupportedValid *bool `yaml:"unsupported_valid"`
+	// MaxRatio is the uncompressed to compressed size ratio above which
+	// compressed files and images are reported invalid instead of being fully decompressed
+	MaxRatio float64 `yaml:"max_ratio"`
+}
+
+func (f *ValidFilter) Match(ctx filter.Context) (bool, error) {
+	if ctx == nil {
+		return false, fmt.Errorf("context is nil")
+	}
+	if !ctx.Info().Mode().IsRegular() {
+		// Symbolic links and special files have no content to check
+		return f.unsupportedValid(), nil
+	}
+
+	var format string
+	var invalid error
+	err := ctx.WithInput(func(r io.Reader) error {
+		ra, size, err := readerAt(r, ctx.Size())
+		if err != nil {
+			return err
+		}
+		format = detectValidFormat(ra, size, ctx.BaseName())
+		if format == "" || (len(f.Formats) > 0 && !slices.Contains(f.Formats, format)) {
+			format = ""
+			return nil
+		}
+		invalid = validate(format, ra, size, ctx.BaseName(), f.maxRatio())
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("cannot validate %q: %w", ctx.BaseName(), err)
+	}
+
+	if format == "" {
+		return f.unsupportedValid(), nil
+	}
+	if invalid != nil {
+		slog.Debug("File failed validation", "basename", ctx.BaseName(), "format", format, "reason", invalid)
+		reportInvalid(ctx, format, invalid.Error())
+		return false, nil
+	}
+	return true, nil
+}
+
+func (f *ValidFilter) unsupportedValid() bool {
+	return f.UnsupportedValid == nil || *f.UnsupportedValid
+}
+
+func (f *ValidFilter) maxRatio() float64 {
+	if f.MaxRatio > 0 {
+		return f.MaxRatio
+	}
+	return defaultArchiveMaxRatio
+}
+
+// detectValidFormat returns the format to check, from the content for binary
+// formats and from the extension for text formats, or "" when it is not supported.
+func detectValidFormat(ra io.ReaderAt, size int64, name string) string {
+	head := make([]byte, 512)
+	n, _ := ra.ReadAt(head, 0)
+	head = head[:n]
+
+	ext := strings.ToLower(filepath.Ext(name))
+	switch {
+	case bytes.HasPrefix(head, []byte("%PDF-")):
+		return validFormatPDF
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")), bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		return validFormatZip
+	case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+		return validFormatGzip
+	case isTarHeader(head), ext == ".tar":
+		return validFormatTar
+	case ext == ".json", ext == ".geojson":
+		return validFormatJSON
+	case ext == ".xml", ext == ".svg":
+		return validFormatXML
+	}
+	if _, _, err := image.DecodeConfig(bytes.NewReader(head)); !errors.Is(err, image.ErrFormat) {
+		return validFormatImage
+	}
+	return ""
+}
+
+// isTarHeader reports whether a block starts with a POSIX or GNU tar header.
+func isTarHeader(head []byte) bool {
+	return len(head) >= 262 && string(head[257:262]) == "ustar"
+}
+
+// validate fully checks a file and returns the reason why it is invalid, or nil.
+// Compressed formats are not decompressed beyond maxRatio times the file size.
+func validate(format string, ra io.ReaderAt, size int64, name string, maxRatio float64) error {
+	r := io.NewSectionReader(ra, 0, size)
+	limit := int64(maxRatio * float64(max(size, 1)))
+	switch format {
+	case validFormatImage:
+		return validateImage(r, max(limit, minImageDecodeBytes))
+	case validFormatZip:
+		return validateZip(ra, size, limit)
+	case validFormatGzip:
+		return validateGzip(r, name, limit)
+	case validFormatTar:
+		return validateTar(r)
+	case validFormatPDF:
+		return validatePDF(ra, size)
+	case validFormatJSON:
+		return validateJSON(r)
+	case validFormatXML:
+		return validateXML(r)
+	default:
+		return nil
+	}
+}
+
+// validateImage decodes the whole image once its dimensions are known to fit
+// in limit bytes, as a file declaring huge dimensions would exhaust the memory.
+func validateImage(r io.ReadSeeker, limit int64) error {
+	config, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return err
+	}
+	pixels := int64(config.Width) * int64(config.Height)
+	if pixels > maxImagePixels {
+		return fmt.Errorf("%w: %dx%d image exceeds %d pixels", errArchiveBomb, config.Width, config.Height, maxImagePixels)
+	}
+	if pixels*4 > limit {
+		return fmt.Errorf("%w: %dx%d image decodes to more than %d bytes", errArchiveBomb, config.Width, config.Height, limit)
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, _, err = image.Decode(r)
+	return err
+}
+
+// validateZip reads the central directory and every entry, which checks their CRC-32.
+// Encrypted entries and unsupported compression methods cannot be checked and are skipped.
+// Archives expanding beyond limit bytes are refused as zip bombs.
+func validateZip(ra io.ReaderAt, size, limit int64) error {
+	archive, err := zip.NewReader(ra, size)
+	if err != nil {
+		return err
+	}
+	counter := &countingReader{limit: limit}
+	for _, file := range archive.File {
+		if file.Flags&0x1 != 0 {
+			continue
+		}
+		rc, err := file.Open()
+		if errors.Is(err, zip.ErrAlgorithm) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", file.Name, err)
+		}
+		counter.r = rc
+		_, err = io.Copy(io.Discard, counter)
+		_ = rc.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", file.Name, err)
+		}
+	}
+	return nil
+}
+
+// validateGzip decompresses every member, which checks their CRC-32 and size.
+// Compressed tar archives are checked as tar streams too.
+// Streams expanding beyond limit bytes are refused as zip bombs.
+func validateGzip(r io.Reader, name string, limit int64) error {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = zr.Close() }()
+
+	br := bufio.NewReader(&countingReader{r: zr, limit: limit})
+	head, _ := br.Peek(512)
+	lower := strings.ToLower(name)
+	if isTarHeader(head) || strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz") {
+		if err := validateTar(br); err != nil {
+			return err
+		}
+	}
+	// Read the remaining data (tar padding, trailing members) up to the checksum
+	_, err = io.Copy(io.Discard, br)
+	return err
+}
+
+// validateTar reads every header and entry, which detects truncated archives.
+func validateTar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(io.Discard, tr); err != nil {
+			return fmt.Errorf("%s: %w", header.Name, err)
+		}
+	}
+}
+
+// validatePDF checks that the file ends with a trailer whose startxref offset
+// points to a cross-reference table or stream, which half-written files lack.
+func validatePDF(ra io.ReaderAt, size int64) error {
+	start := max(size-pdfTailSize, 0)
+	tail := make([]byte, size-start)
+	if _, err := ra.ReadAt(tail, start); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	eof := bytes.LastIndex(tail, []byte("%%EOF"))
+	if eof < 0 {
+		return errors.New("missing %%EOF marker")
+	}
+	xref := bytes.LastIndex(tail[:eof], []byte("startxref"))
+	if xref < 0 {
+		return errors.New("missing startxref")
+	}
+	offset, err := strconv.ParseInt(string(bytes.TrimSpace(tail[xref+len("startxref"):eof])), 10, 64)
+	if err != nil || offset <= 0 || offset >= size {
+		return fmt.Errorf("invalid startxref offset %q", bytes.TrimSpace(tail[xref+len("startxref"):eof]))
+	}
+
+	section := make([]byte, 64)
+	n, err := ra.ReadAt(section, offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	section = bytes.TrimLeft(section[:n], " \t\r\n")
+	if !bytes.HasPrefix(section, []byte("xref")) && !pdfObjectHeader.Match(section) {
+		return fmt.Errorf("startxref offset %d does not point to a cross-reference section", offset)
+	}
+	return nil
+}
+
+// validateJSON checks that the file holds exactly one well-formed JSON value.
+func validateJSON(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	values := 0
+	depth := 0
+	for {
+		token, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if delim, ok := token.(json.Delim); ok && (delim == '[' || delim == '{') {
+			depth++
+		} else if ok {
+			depth--
+		}
+		if depth == 0 {
+			values++
+		}
+	}
+	if values != 1 {
+		return fmt.Errorf("expected one JSON value, found %d", values)
+	}
+	return nil
+}
+
+// validateXML checks that the file is well-formed and has a single root element.
+func validateXML(r io.Reader) error {
+	dec := xml.NewDecoder(r)
+	// Charsets are not converted, only the structure is checked
+	dec.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+	dec.Entity = make(map[string]string)
+
+	roots := 0
+	for depth := 0; ; {
+		token, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				roots++
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.Directive:
+			// Entities declared in the DOCTYPE, as written by drawing tools in SVG files
+			for _, m := range xmlEntityDecl.FindAllSubmatch(t, -1) {
+				dec.Entity[string(m[1])] = string(m[2]) + string(m[3])
+			}
+		}
+	}
+	if roots != 1 {
+		return fmt.Errorf("expected one root element, found %d", roots)
+	}
+	return nil
+}
+
+func (f *ValidFilter) Selector() string {
+	return "valid"
+}
+
+func (f *ValidFilter) LoadConfig(config map[string]interface{}) error {
+	var cfg ValidFilter
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	for i, format := range cfg.Formats {
+		format = strings.ToLower(strings.TrimSpace(format))
+		if !slices.Contains(validFormats, format) {
+			return fmt.Errorf("invalid format %q, must be one of %s", cfg.Formats[i], strings.Join(validFormats, ", "))
+		}
+		cfg.Formats[i] = format
+	}
+	if cfg.MaxRatio < 0 {
+		return fmt.Errorf("'max_ratio' cannot be negative")
+	}
+
+	*f = cfg
+
+	slog.Debug("Loading valid was successful", "config", config)
+	return nil
+}
+
+func init() {
+	filter.RegisterFilter("valid", func() filter.Filter {
+		return &ValidFilter{}
+	})
+}
@@ -0,0 +1,264 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	filehandler "github.com/polocto/FolderFlow/internal/fileHandler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pngFile(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for x := range 64 {
+		img.Set(x, x, color.RGBA{R: uint8(x * 4), A: 255})
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// zipFile builds an archive with a stored entry, so that its content can be corrupted in place.
+func zipFile(t *testing.T, content string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: "notes.txt", Method: zip.Store})
+	require.NoError(t, err)
+	_, err = w.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func tarFile(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	content := bytes.Repeat([]byte("data"), 1000)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "data.bin", Mode: 0o644, Size: int64(len(content))}))
+	_, err := tw.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func gzipFile(t *testing.T, content []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func pdfFile() []byte {
+	body := "%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n"
+	xref := len(body)
+	return []byte(body + "xref\n0 2\n0000000000 65535 f \n0000000009 00000 n \n" +
+		"trailer\n<< /Size 2 /Root 1 0 R >>\nstartxref\n" + strconv.Itoa(xref) + "\n%%EOF\n")
+}
+
+func TestValidFilter_Match(t *testing.T) {
+	pngData := pngFile(t)
+	zipData := zipFile(t, "hello, archive")
+	corruptZip := bytes.Replace(zipData, []byte("hello"), []byte("HELLO"), 1)
+	tarData := tarFile(t)
+	gzipData := gzipFile(t, []byte("compressed text"))
+	corruptGzip := append([]byte(nil), gzipData...)
+	corruptGzip[len(corruptGzip)-5] ^= 0xff // CRC-32
+	tgz := gzipFile(t, tarData)
+	truncatedTgz := gzipFile(t, tarData[:1500])
+	pdfData := pdfFile()
+	// The offset points inside the catalog dictionary
+	wrongXref := regexp.MustCompile(`startxref\n\d+`).ReplaceAll(pdfData, []byte("startxref\n20"))
+	svg := `<?xml version="1.0"?>
+<!DOCTYPE svg [<!ENTITY ns_svg "http://www.w3.org/2000/svg">]>
+<svg xmlns="&ns_svg;"><rect/></svg>`
+
+	testCases := []struct {
+		name     string
+		basename string
+		content  []byte
+		expected bool
+	}{
+		{"png", "a.png", pngData, true},
+		{"truncated png", "a.png", pngData[:len(pngData)/2], false},
+		{"zip", "a.zip", zipData, true},
+		{"zip crc mismatch", "a.zip", corruptZip, false},
+		{"truncated zip", "a.zip", zipData[:len(zipData)-10], false},
+		{"tar", "a.tar", tarData, true},
+		{"truncated tar", "a.tar", tarData[:1500], false},
+		{"gzip", "a.txt.gz", gzipData, true},
+		{"gzip crc mismatch", "a.txt.gz", corruptGzip, false},
+		{"tar.gz", "a.tar.gz", tgz, true},
+		{"truncated tar in gzip", "a.tgz", truncatedTgz, false},
+		{"pdf", "a.pdf", pdfData, true},
+		{"half-written pdf", "a.pdf", pdfData[:len(pdfData)-30], false},
+		{"pdf with wrong xref", "a.pdf", wrongXref, false},
+		{"json", "a.json", []byte(`{"a": [1, 2, {"b": null}]}`), true},
+		{"json scalar", "a.json", []byte("42"), true},
+		{"truncated json", "a.json", []byte(`{"a": [1, 2`), false},
+		{"two json values", "a.json", []byte(`{} {}`), false},
+		{"xml", "a.xml", []byte("<?xml version=\"1.0\"?><root><a>text</a></root>"), true},
+		{"xml with declared entity", "a.svg", []byte(svg), true},
+		{"mismatched xml", "a.xml", []byte("<root><a></b></root>"), false},
+		{"truncated xml", "a.xml", []byte("<root><a>text</a>"), false},
+		{"unsupported", "a.txt", []byte("plain text"), true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &ValidFilter{}
+			require.NoError(t, f.LoadConfig(nil))
+
+			ok, err := f.Match(&mockContext{tc.content, &mockFileInfo{NameVal: tc.basename, SizeVal: int64(len(tc.content))}})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ok)
+		})
+	}
+}
+
+func TestValidFilter_CompressionBomb(t *testing.T) {
+	zeros := make([]byte, 4<<20)
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("zeros.bin") // deflated
+	require.NoError(t, err)
+	_, err = w.Write(zeros)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	testCases := []struct {
+		name     string
+		basename string
+		content  []byte
+	}{
+		{"zip", "bomb.zip", buf.Bytes()},
+		{"gzip", "bomb.bin.gz", gzipFile(t, zeros)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := &mockContext{tc.content, &mockFileInfo{NameVal: tc.basename, SizeVal: int64(len(tc.content))}}
+
+			f := &ValidFilter{}
+			require.NoError(t, f.LoadConfig(nil))
+			ok, err := f.Match(ctx)
+			require.NoError(t, err)
+			assert.False(t, ok, "the default ratio refuses the archive")
+
+			require.NoError(t, f.LoadConfig(map[string]interface{}{"max_ratio": 100000}))
+			ok, err = f.Match(ctx)
+			require.NoError(t, err)
+			assert.True(t, ok)
+		})
+	}
+}
+
+// pngDeclaring returns a small PNG whose header declares the given dimensions.
+func pngDeclaring(t *testing.T, width, height uint32) []byte {
+	t.Helper()
+	data := pngFile(t)
+	// The IHDR chunk starts after the 8 byte signature: length, type, width, height...
+	binary.BigEndian.PutUint32(data[16:], width)
+	binary.BigEndian.PutUint32(data[20:], height)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestValidFilter_ImageBomb(t *testing.T) {
+	testCases := []struct {
+		name          string
+		width, height uint32
+	}{
+		{"above the ratio", 12000, 12000},
+		{"above the pixel limit", 100000, 100000},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := pngDeclaring(t, tc.width, tc.height)
+			err := validate(validFormatImage, bytes.NewReader(data), int64(len(data)), "bomb.png", defaultArchiveMaxRatio)
+			assert.ErrorIs(t, err, errArchiveBomb)
+		})
+	}
+
+	// A plain image compresses well beyond the ratio but is small once decoded
+	plain := image.NewRGBA(image.Rect(0, 0, 2000, 2000))
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, plain))
+	assert.NoError(t, validate(validFormatImage, bytes.NewReader(buf.Bytes()), int64(buf.Len()), "plain.png", defaultArchiveMaxRatio))
+}
+
+func TestValidFilter_Options(t *testing.T) {
+	truncated := pngFile(t)[:100]
+
+	f := &ValidFilter{}
+	require.NoError(t, f.LoadConfig(map[string]interface{}{"formats": []string{"pdf"}}))
+	ok, err := f.Match(&mockContext{truncated, &mockFileInfo{NameVal: "a.png"}})
+	require.NoError(t, err)
+	assert.True(t, ok, "formats that are not listed are not checked")
+
+	require.NoError(t, f.LoadConfig(map[string]interface{}{"unsupported_valid": false}))
+	ok, err = f.Match(&mockContext{[]byte("text"), &mockFileInfo{NameVal: "a.txt"}})
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = f.Match(&mockContext{nil, &mockFileInfo{NameVal: "link", ModeVal: fs.ModeSymlink}})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestValidFilter_ReportsReason(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"a": `), 0o644))
+	file, err := filehandler.NewContextFile(path)
+	require.NoError(t, err)
+	ctx, err := NewContextFilter(file)
+	require.NoError(t, err)
+
+	var format, reason string
+	OnInvalid(ctx, func(f, r string) { format, reason = f, r })
+
+	f := &ValidFilter{}
+	require.NoError(t, f.LoadConfig(nil))
+	ok, err := f.Match(ctx)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "json", format)
+	assert.NotEmpty(t, reason)
+}
+
+func TestValidFilter_LoadConfigErrors(t *testing.T) {
+	f := &ValidFilter{}
+	assert.Error(t, f.LoadConfig(map[string]interface{}{"formats": []string{"docx"}}))
+	assert.Error(t, f.LoadConfig(map[string]interface{}{"max_ratio": -1}))
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/polocto/FolderFlow/internal/metadata"
+	"github.com/polocto/FolderFlow/pkg/ffplugin/filter"
+)
+
+// codecAliases maps common codec names to the FourCCs used in containers.
+var codecAliases = map[string][]string{
+	"h264": {"avc1", "avc3"},
+	"avc":  {"avc1", "avc3"},
+	"h265": {"hvc1", "hev1"},
+	"hevc": {"hvc1", "hev1"},
+	"vp8":  {"vp08"},
+	"vp9":  {"vp09"},
+	"av1":  {"av01"},
+}
+
+// formatAliases maps the names accepted in 'formats' to metadata formats.
+var formatAliases = map[string]string{
+	"mp4":      metadata.FormatMP4,
+	"m4v":      metadata.FormatMP4,
+	"mov":      metadata.FormatMOV,
+	"mkv":      metadata.FormatMatroska,
+	"matroska": metadata.FormatMatroska,
+	"webm":     metadata.FormatWebM,
+}
+
+// VideoFilter matches video files on the properties stored in their container
+// (MP4/MOV boxes, Matroska/WebM EBML elements).
+type VideoFilter struct {
+	MinWidth      int      `yaml:"min_width"`
+	MaxWidth      int      `yaml:"max_width"`
+	MinHeight     int      `yaml:"min_height"`
+	MaxHeight     int      `yaml:"max_height"`
+	MinDuration   string   `yaml:"min_duration"`
+	MaxDuration   string   `yaml:"max_duration"`
+	MinFPS        float64  `yaml:"min_fps"`
+	MaxFPS        float64  `yaml:"max_fps"`
+	Codecs        []string `yaml:"codecs"`
+	Formats       []string `yaml:"formats"`
+	CreatedAfter  string   `yaml:"created_after"`
+	CreatedBefore string   `yaml:"created_before"`
+
+	minDuration time.Duration
+	maxDuration time.Duration
+	created     dateRange
+}
+
+func (f *VideoFilter) Match(ctx filter.Context) (bool, error) {
+	if ctx == nil {
+		return false, fmt.Errorf("context is nil")
+	}
+	if ctx.IsDir() {
+		return false, nil
+	}
+
+	// The whole file is opened so that media data can be skipped by seeking:
+	// only the metadata boxes and elements are actually read.
+	var info *metadata.VideoInfo
+	err := ctx.WithInput(func(r io.Reader) error {
+		var err error
+		info, err = metadata.ReadVideoInfo(r)
+		return err
+	})
+	if errors.Is(err, metadata.ErrNotVideo) {
+		slog.Debug("Not a video file", "basename", ctx.BaseName())
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("cannot read video metadata of %q: %w", ctx.BaseName(), err)
+	}
+	if !f.matchInfo(info) {
+		return false, nil
+	}
+	attrs := map[string]string{"format": info.Format, "codec": info.Codec}
+	if info.Width > 0 && info.Height > 0 {
+		attrs["width"], attrs["height"] = strconv.Itoa(info.Width), strconv.Itoa(info.Height)
+	}
+	dateAttributes(attrs, "created", info.Created)
+	setAttributes(ctx, f.Selector(), attrs)
+	return true, nil
+}
+
+func (f *VideoFilter) matchInfo(info *metadata.VideoInfo) bool {
+	if len(f.Formats) > 0 && !slices.Contains(f.Formats, info.Format) {
+		return false
+	}
+	if len(f.Codecs) > 0 && !slices.Contains(f.Codecs, strings.ToLower(info.Codec)) {
+		return false
+	}
+	if !inIntRange(info.Width, f.MinWidth, f.MaxWidth) || !inIntRange(info.Height, f.MinHeight, f.MaxHeight) {
+		return false
+	}
+	if (f.MinFPS != 0 || f.MaxFPS != 0) &&
+		(info.FrameRate == 0 || !inFloatRange(info.FrameRate, f.MinFPS, f.MaxFPS)) {
+		return false
+	}
+	if f.minDuration != 0 || f.maxDuration != 0 {
+		if info.Duration == 0 ||
+			(f.minDuration != 0 && info.Duration < f.minDuration) ||
+			(f.maxDuration != 0 && info.Duration > f.maxDuration) {
+			return false
+		}
+	}
+	if f.created.isSet() && (info.Created.IsZero() || !f.created.contains(info.Created)) {
+		return false
+	}
+	return true
+}
+
+func (f *VideoFilter) Selector() string {
+	return "video"
+}
+
+func (f *VideoFilter) LoadConfig(config map[string]interface{}) error {
+	var cfg VideoFilter
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	if cfg.MinWidth < 0 || cfg.MaxWidth < 0 || cfg.MinHeight < 0 || cfg.MaxHeight < 0 ||
+		cfg.MinFPS < 0 || cfg.MaxFPS < 0 {
+		return fmt.Errorf("video filter limits cannot be negative")
+	}
+	if cfg.MaxWidth > 0 && cfg.MinWidth > cfg.MaxWidth {
+		return fmt.Errorf("'min_width' is greater than 'max_width'")
+	}
+	if cfg.MaxHeight > 0 && cfg.MinHeight > cfg.MaxHeight {
+		return fmt.Errorf("'min_height' is greater than 'max_height'")
+	}
+	if cfg.MaxFPS > 0 && cfg.MinFPS > cfg.MaxFPS {
+		return fmt.Errorf("'min_fps' is greater than 'max_fps'")
+	}
+
+	var err error
+	if cfg.minDuration, err = parseDuration("min_duration", cfg.MinDuration); err != nil {
+		return err
+	}
+	if cfg.maxDuration, err = parseDuration("max_duration", cfg.MaxDuration); err != nil {
+		return err
+	}
+	if cfg.maxDuration != 0 && cfg.minDuration > cfg.maxDuration {
+		return fmt.Errorf("'min_duration' (%s) is greater than 'max_duration' (%s)", cfg.minDuration, cfg.maxDuration)
+	}
+	if cfg.created, err = newDateRange(cfg.CreatedAfter, cfg.CreatedBefore); err != nil {
+		return err
+	}
+
+	var codecs []string
+	for _, codec := range cfg.Codecs {
+		codec = strings.ToLower(strings.TrimSpace(codec))
+		if aliases, ok := codecAliases[codec]; ok {
+			codecs = append(codecs, aliases...)
+		} else {
+			codecs = append(codecs, codec)
+		}
+	}
+	cfg.Codecs = codecs
+
+	for i, format := range cfg.Formats {
+		name, ok := formatAliases[strings.ToLower(strings.TrimPrefix(format, "."))]
+		if !ok {
+			return fmt.Errorf("invalid format %q, must be one of mp4, mov, mkv, webm", format)
+		}
+		cfg.Formats[i] = name
+	}
+
+	*f = cfg
+
+	slog.Debug("Loading video was successful", "config", config)
+	return nil
+}
+
+func init() {
+	filter.RegisterFilter("video", func() filter.Filter {
+		return &VideoFilter{}
+	})
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/polocto/FolderFlow/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVideoFilter_MatchInfo(t *testing.T) {
+	dashcam := &metadata.VideoInfo{
+		Format: metadata.FormatMP4, Codec: "hvc1", Width: 2560, Height: 1440,
+		FrameRate: 30, Duration: 3 * time.Minute,
+		Created: time.Date(2024, time.March, 2, 8, 0, 0, 0, time.UTC),
+	}
+	webm := &metadata.VideoInfo{Format: metadata.FormatWebM, Codec: "vp09", Width: 1280, Height: 720}
+
+	testCases := []struct {
+		name     string
+		config   map[string]interface{}
+		info     *metadata.VideoInfo
+		expected bool
+	}{
+		{"min width", map[string]interface{}{"min_width": 1920}, dashcam, true},
+		{"max height", map[string]interface{}{"max_height": 1080}, dashcam, false},
+		{"codec alias", map[string]interface{}{"codecs": []string{"HEVC"}}, dashcam, true},
+		{"codec fourcc", map[string]interface{}{"codecs": []string{"avc1"}}, dashcam, false},
+		{"fps", map[string]interface{}{"min_fps": 25.0, "max_fps": 30.0}, dashcam, true},
+		{"unknown fps", map[string]interface{}{"min_fps": 25.0}, webm, false},
+		{"duration", map[string]interface{}{"min_duration": "1m", "max_duration": "5m"}, dashcam, true},
+		{"unknown duration", map[string]interface{}{"max_duration": "5m"}, webm, false},
+		{"created", map[string]interface{}{"created_after": "2024-01-01", "created_before": "2025-01-01"}, dashcam, true},
+		{"created outside range", map[string]interface{}{"created_before": "2024-01-01"}, dashcam, false},
+		{"format", map[string]interface{}{"formats": []string{".mkv", "webm"}}, webm, true},
+		{"other format", map[string]interface{}{"formats": []string{"mov"}}, dashcam, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &VideoFilter{}
+			require.NoError(t, f.LoadConfig(tc.config))
+			assert.Equal(t, tc.expected, f.matchInfo(tc.info))
+		})
+	}
+}
+
+func TestVideoFilter_NotVideo(t *testing.T) {
+	f := &VideoFilter{}
+	require.NoError(t, f.LoadConfig(map[string]interface{}{"min_width": 1}))
+
+	ok, err := f.Match(&mockContext{[]byte("plain text, no container"), &mockFileInfo{NameVal: "a.mp4"}})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestVideoFilter_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"min_width": -1},
+		{"min_height": 1080, "max_height": 720},
+		{"min_fps": 60.0, "max_fps": 30.0},
+		{"max_duration": "forever"},
+		{"formats": []string{"avi"}},
+		{"created_after": "yesterday"},
+	}
+	for _, config := range invalid {
+		f := &VideoFilter{}
+		assert.Error(t, f.LoadConfig(config), "config %v", config)
+	}
+}
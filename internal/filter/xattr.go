@@ -0,0 +1,142 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/polocto/FolderFlow/pkg/ffplugin/filter"
+)
+
+// defaultXattrSeparator separates tags in attributes such as user.xdg.tags.
+const defaultXattrSeparator = ","
+
+// XattrFilter matches files on an extended attribute: its presence,
+// its exact value or the tags of a separated list.
+type XattrFilter struct {
+	Attribute  string   `yaml:"attribute"`
+	Exists     *bool    `yaml:"exists"`
+	Value      *string  `yaml:"value"`
+	Tags       []string `yaml:"tags"`
+	AllTags    bool     `yaml:"all_tags"`
+	Separator  string   `yaml:"separator"`
+	IgnoreCase bool     `yaml:"ignore_case"`
+}
+
+func (f *XattrFilter) Match(ctx filter.Context) (bool, error) {
+	if ctx == nil {
+		return false, fmt.Errorf("context is nil")
+	}
+
+	value, ok, err := ctx.Xattr(f.Attribute)
+	if err != nil {
+		return false, err
+	}
+	return f.matchValue(value, ok), nil
+}
+
+func (f *XattrFilter) matchValue(raw []byte, ok bool) bool {
+	if f.Exists != nil && *f.Exists != ok {
+		return false
+	}
+	if !ok {
+		// Only "exists: false" can match a file without the attribute
+		return f.Exists != nil
+	}
+
+	// Attributes written by C tools are often NUL terminated
+	value := strings.TrimRight(string(raw), "\x00")
+	if f.Value != nil && !f.equal(value, *f.Value) {
+		return false
+	}
+	if len(f.Tags) > 0 && !f.matchTags(value) {
+		return false
+	}
+	return true
+}
+
+func (f *XattrFilter) matchTags(value string) bool {
+	var tags []string
+	for _, tag := range strings.Split(value, f.Separator) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	for _, wanted := range f.Tags {
+		found := false
+		for _, tag := range tags {
+			if f.equal(tag, wanted) {
+				found = true
+				break
+			}
+		}
+		if found && !f.AllTags {
+			return true
+		}
+		if !found && f.AllTags {
+			return false
+		}
+	}
+	return f.AllTags
+}
+
+func (f *XattrFilter) equal(a, b string) bool {
+	if f.IgnoreCase {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+func (f *XattrFilter) Selector() string {
+	return "xattr"
+}
+
+func (f *XattrFilter) LoadConfig(config map[string]interface{}) error {
+	var cfg XattrFilter
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	if cfg.Attribute == "" {
+		return fmt.Errorf("'attribute' config cannot be empty")
+	}
+	if cfg.Exists == nil && cfg.Value == nil && len(cfg.Tags) == 0 {
+		return fmt.Errorf("one of 'exists', 'value' or 'tags' must be set")
+	}
+	if cfg.Exists != nil && !*cfg.Exists && (cfg.Value != nil || len(cfg.Tags) > 0) {
+		return fmt.Errorf("'exists: false' cannot be combined with 'value' or 'tags'")
+	}
+	if cfg.Separator == "" {
+		cfg.Separator = defaultXattrSeparator
+	}
+	for i, tag := range cfg.Tags {
+		if cfg.Tags[i] = strings.TrimSpace(tag); cfg.Tags[i] == "" {
+			return fmt.Errorf("tags cannot be empty")
+		}
+	}
+
+	*f = cfg
+
+	slog.Debug("Loading xattr was successful", "config", config)
+	return nil
+}
+
+func init() {
+	filter.RegisterFilter("xattr", func() filter.Filter {
+		return &XattrFilter{}
+	})
+}
@@ -0,0 +1,21 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+//go:build darwin || freebsd || netbsd
+
+package filter
+
+import "golang.org/x/sys/unix"
+
+// errNoXattr is returned by getxattr(2) for a missing attribute.
+const errNoXattr = unix.ENOATTR
@@ -0,0 +1,57 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	filehandler "github.com/polocto/FolderFlow/internal/fileHandler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestXattrFilter_ReadsFileAttributes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	require.NoError(t, os.WriteFile(path, []byte("%PDF"), 0o644))
+	if err := unix.Setxattr(path, "user.xdg.tags", []byte("urgent,clientA"), 0); err != nil {
+		if errors.Is(err, unix.ENOTSUP) {
+			t.Skip("extended attributes are not supported by the temporary directory")
+		}
+		require.NoError(t, err)
+	}
+
+	file, err := filehandler.NewContextFile(path)
+	require.NoError(t, err)
+	ctx, err := NewContextFilter(file)
+	require.NoError(t, err)
+
+	value, ok, err := ctx.Xattr("user.xdg.tags")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "urgent,clientA", string(value))
+
+	_, ok, err = ctx.Xattr("user.xdg.comment")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	f := &XattrFilter{}
+	require.NoError(t, f.LoadConfig(map[string]interface{}{"attribute": "user.xdg.tags", "tags": []string{"urgent"}}))
+	matched, err := f.Match(ctx)
+	require.NoError(t, err)
+	assert.True(t, matched)
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestXattrFilter_MatchValue(t *testing.T) {
+	tags := []byte("work, #urgent,clientA\x00")
+
+	testCases := []struct {
+		name     string
+		config   map[string]interface{}
+		value    []byte
+		ok       bool
+		expected bool
+	}{
+		{"exists", map[string]interface{}{"exists": true}, tags, true, true},
+		{"missing", map[string]interface{}{"exists": true}, nil, false, false},
+		{"not exists", map[string]interface{}{"exists": false}, nil, false, true},
+		{"not exists but set", map[string]interface{}{"exists": false}, tags, true, false},
+		{"value", map[string]interface{}{"value": "work, #urgent,clientA"}, tags, true, true},
+		{"other value", map[string]interface{}{"value": "work"}, tags, true, false},
+		{"tag", map[string]interface{}{"tags": []string{"#urgent"}}, tags, true, true},
+		{"any tag", map[string]interface{}{"tags": []string{"home", "clientA"}}, tags, true, true},
+		{"tag is not a substring", map[string]interface{}{"tags": []string{"client"}}, tags, true, false},
+		{"all tags", map[string]interface{}{"tags": []string{"work", "clientA"}, "all_tags": true}, tags, true, true},
+		{"all tags partial", map[string]interface{}{"tags": []string{"work", "home"}, "all_tags": true}, tags, true, false},
+		{"ignore case", map[string]interface{}{"tags": []string{"CLIENTA"}, "ignore_case": true}, tags, true, true},
+		{"separator", map[string]interface{}{"tags": []string{"b"}, "separator": ";"}, []byte("a;b"), true, true},
+		{"tag on missing attribute", map[string]interface{}{"tags": []string{"work"}}, nil, false, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := map[string]interface{}{"attribute": "user.xdg.tags"}
+			for k, v := range tc.config {
+				config[k] = v
+			}
+			f := &XattrFilter{}
+			require.NoError(t, f.LoadConfig(config))
+			assert.Equal(t, tc.expected, f.matchValue(tc.value, tc.ok))
+		})
+	}
+}
+
+func TestXattrFilter_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"exists": true},
+		{"attribute": "user.xdg.tags"},
+		{"attribute": "user.xdg.tags", "exists": false, "tags": []string{"a"}},
+		{"attribute": "user.xdg.tags", "tags": []string{" "}},
+	}
+	for _, config := range invalid {
+		f := &XattrFilter{}
+		assert.Error(t, f.LoadConfig(config), "config %v", config)
+	}
+}
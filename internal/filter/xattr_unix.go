@@ -0,0 +1,51 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+//go:build linux || darwin || freebsd || netbsd
+
+package filter
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// getXattr reads an extended attribute without following symbolic links.
+// It reports false when the attribute, or extended attribute support, is missing.
+func getXattr(path, name string) ([]byte, bool, error) {
+	for {
+		size, err := unix.Lgetxattr(path, name, nil)
+		if err != nil {
+			return xattrError(path, name, err)
+		}
+		buf := make([]byte, size)
+		n, err := unix.Lgetxattr(path, name, buf)
+		if errors.Is(err, unix.ERANGE) {
+			// The attribute grew between the two calls
+			continue
+		}
+		if err != nil {
+			return xattrError(path, name, err)
+		}
+		return buf[:n], true, nil
+	}
+}
+
+func xattrError(path, name string, err error) ([]byte, bool, error) {
+	if errors.Is(err, errNoXattr) || errors.Is(err, unix.ENOTSUP) {
+		return nil, false, nil
+	}
+	return nil, false, fmt.Errorf("cannot read extended attribute %q of %q: %w", name, path, err)
+}
@@ -0,0 +1,479 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotAudio is returned when the data is not a supported audio format.
+var ErrNotAudio = errors.New("not a supported audio file")
+
+// Audio formats reported in AudioTags.Format.
+const (
+	FormatMP3  = "mp3"
+	FormatFLAC = "flac"
+	FormatOgg  = "ogg"
+	FormatOpus = "opus"
+	FormatMP4  = "mp4"
+)
+
+// oggTailSize is the amount of data read at the end of Ogg files to find the last page.
+const oggTailSize = 64 << 10
+
+// AudioTags holds the tags and stream properties of an audio file.
+// Fields are left empty when the file does not define them.
+type AudioTags struct {
+	Format      string
+	Title       string
+	Artist      string
+	AlbumArtist string
+	Album       string
+	Genre       string
+	Year        int
+	Track       int
+	TrackTotal  int
+	Disc        int
+	DiscTotal   int
+	Compilation bool
+	// Duration is 0 when it cannot be determined.
+	Duration time.Duration
+}
+
+// ReadAudioTags reads ID3v1/ID3v2 (MP3), Vorbis comments (FLAC, Ogg Vorbis,
+// Opus) and MP4 ilst atoms (M4A, M4B, MP4).
+// size is the total size of the input; it is used to compute durations and to
+// locate trailing tags. Data is skipped by seeking when r implements io.Seeker.
+func ReadAudioTags(r io.Reader, size int64) (*AudioTags, error) {
+	s := newStream(r)
+	head, err := s.peek(12)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(head, []byte("ID3")):
+		return readID3File(s, size)
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		return readFLAC(s)
+	case bytes.HasPrefix(head, []byte("OggS")):
+		return readOgg(s, size)
+	case len(head) >= 8 && string(head[4:8]) == "ftyp":
+		return readMP4Audio(s)
+	case len(head) >= 4 && isMPEGFrame(head):
+		return readMP3(s, size, &AudioTags{Format: FormatMP3})
+	default:
+		return nil, ErrNotAudio
+	}
+}
+
+// --- Vorbis comments (FLAC, Ogg) ---
+
+func parseVorbisComments(data []byte, tags *AudioTags) error {
+	if len(data) < 8 {
+		return fmt.Errorf("vorbis comment block is truncated")
+	}
+	vendorLen := int(binary.LittleEndian.Uint32(data))
+	pos := 4 + vendorLen
+	if pos+4 > len(data) {
+		return fmt.Errorf("vorbis comment block is truncated")
+	}
+	count := int(binary.LittleEndian.Uint32(data[pos:]))
+	pos += 4
+
+	for i := 0; i < count; i++ {
+		if pos+4 > len(data) {
+			return fmt.Errorf("vorbis comment block is truncated")
+		}
+		n := int(binary.LittleEndian.Uint32(data[pos:]))
+		pos += 4
+		if n < 0 || pos+n > len(data) {
+			return fmt.Errorf("vorbis comment block is truncated")
+		}
+		key, value, ok := strings.Cut(string(data[pos:pos+n]), "=")
+		pos += n
+		if ok {
+			setVorbisField(tags, strings.ToUpper(key), strings.TrimSpace(value))
+		}
+	}
+	return nil
+}
+
+func setVorbisField(tags *AudioTags, key, value string) {
+	switch key {
+	case "TITLE":
+		tags.Title = firstNonEmpty(tags.Title, value)
+	case "ARTIST":
+		tags.Artist = firstNonEmpty(tags.Artist, value)
+	case "ALBUMARTIST", "ALBUM ARTIST":
+		tags.AlbumArtist = firstNonEmpty(tags.AlbumArtist, value)
+	case "ALBUM":
+		tags.Album = firstNonEmpty(tags.Album, value)
+	case "GENRE":
+		tags.Genre = firstNonEmpty(tags.Genre, value)
+	case "DATE", "YEAR":
+		if tags.Year == 0 {
+			tags.Year = parseYear(value)
+		}
+	case "TRACKNUMBER":
+		tags.Track, tags.TrackTotal = parseNumberPair(value, tags.TrackTotal)
+	case "TRACKTOTAL", "TOTALTRACKS":
+		tags.TrackTotal, _ = strconv.Atoi(value)
+	case "DISCNUMBER":
+		tags.Disc, tags.DiscTotal = parseNumberPair(value, tags.DiscTotal)
+	case "DISCTOTAL", "TOTALDISCS":
+		tags.DiscTotal, _ = strconv.Atoi(value)
+	case "COMPILATION":
+		tags.Compilation = value == "1"
+	}
+}
+
+// --- FLAC ---
+
+func readFLAC(s *stream) (*AudioTags, error) {
+	tags := &AudioTags{Format: FormatFLAC}
+	if err := s.skip(4); err != nil {
+		return nil, err
+	}
+
+	for {
+		header, err := s.readFull(4)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read FLAC metadata block: %w", err)
+		}
+		last := header[0]&0x80 != 0
+		typ := header[0] & 0x7F
+		length := int64(header[1])<<16 | int64(header[2])<<8 | int64(header[3])
+
+		switch typ {
+		case 0, 4: // STREAMINFO, VORBIS_COMMENT
+			block, err := s.readFull(length)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read FLAC metadata block: %w", err)
+			}
+			if typ == 0 {
+				parseFLACStreamInfo(block, tags)
+			} else if err := parseVorbisComments(block, tags); err != nil {
+				return nil, err
+			}
+		default:
+			if err := s.skip(length); err != nil {
+				return nil, err
+			}
+		}
+		if last {
+			return tags, nil
+		}
+	}
+}
+
+func parseFLACStreamInfo(b []byte, tags *AudioTags) {
+	if len(b) < 18 {
+		return
+	}
+	rate := int64(b[10])<<12 | int64(b[11])<<4 | int64(b[12])>>4
+	samples := int64(b[13]&0x0F)<<32 | int64(binary.BigEndian.Uint32(b[14:]))
+	if rate > 0 {
+		tags.Duration = time.Duration(samples) * time.Second / time.Duration(rate)
+	}
+}
+
+// --- Ogg (Vorbis, Opus) ---
+
+type oggPage struct {
+	granule  uint64
+	serial   uint32
+	segments []byte
+	data     []byte
+}
+
+func readOggPage(s *stream) (oggPage, error) {
+	header, err := s.readFull(27)
+	if err != nil {
+		return oggPage{}, err
+	}
+	if string(header[:4]) != "OggS" {
+		return oggPage{}, fmt.Errorf("invalid Ogg page at offset %d", s.pos-27)
+	}
+	segments, err := s.readFull(int64(header[26]))
+	if err != nil {
+		return oggPage{}, err
+	}
+	var size int64
+	for _, l := range segments {
+		size += int64(l)
+	}
+	data, err := s.readFull(size)
+	if err != nil {
+		return oggPage{}, err
+	}
+	return oggPage{
+		granule:  binary.LittleEndian.Uint64(header[6:]),
+		serial:   binary.LittleEndian.Uint32(header[14:]),
+		segments: segments,
+		data:     data,
+	}, nil
+}
+
+// readOggPackets returns the first n packets of the first logical stream.
+func readOggPackets(s *stream, n int) ([][]byte, uint32, error) {
+	var packets [][]byte
+	var current []byte
+	var serial uint32
+	first := true
+
+	for len(packets) < n {
+		page, err := readOggPage(s)
+		if err != nil {
+			return nil, 0, err
+		}
+		if first {
+			serial, first = page.serial, false
+		} else if page.serial != serial {
+			continue
+		}
+
+		pos := 0
+		for _, l := range page.segments {
+			current = append(current, page.data[pos:pos+int(l)]...)
+			pos += int(l)
+			if len(current) > maxBlockSize {
+				return nil, 0, errBlockTooLarge
+			}
+			if l < 255 {
+				packets = append(packets, current)
+				current = nil
+				if len(packets) == n {
+					break
+				}
+			}
+		}
+	}
+	return packets, serial, nil
+}
+
+func readOgg(s *stream, size int64) (*AudioTags, error) {
+	packets, serial, err := readOggPackets(s, 2)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read Ogg headers: %w", err)
+	}
+	ident, comments := packets[0], packets[1]
+
+	tags := &AudioTags{Format: FormatOgg}
+	var rate, preSkip uint64
+	switch {
+	case bytes.HasPrefix(ident, []byte("\x01vorbis")) && len(ident) >= 16:
+		rate = uint64(binary.LittleEndian.Uint32(ident[12:]))
+		if bytes.HasPrefix(comments, []byte("\x03vorbis")) {
+			if err := parseVorbisComments(comments[7:], tags); err != nil {
+				return nil, err
+			}
+		}
+	case bytes.HasPrefix(ident, []byte("OpusHead")) && len(ident) >= 12:
+		tags.Format = FormatOpus
+		rate = 48000 // Opus granule positions always use a 48 kHz clock
+		preSkip = uint64(binary.LittleEndian.Uint16(ident[10:]))
+		if bytes.HasPrefix(comments, []byte("OpusTags")) {
+			if err := parseVorbisComments(comments[8:], tags); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return tags, nil
+	}
+
+	if rate > 0 && size > 0 {
+		if granule, ok := lastOggGranule(s, size, serial); ok && granule > preSkip {
+			tags.Duration = time.Duration(granule-preSkip) * time.Second / time.Duration(rate)
+		}
+	}
+	return tags, nil
+}
+
+func lastOggGranule(s *stream, size int64, serial uint32) (uint64, bool) {
+	tail, err := s.tail(size, oggTailSize)
+	if err != nil {
+		return 0, false
+	}
+	for i := bytes.LastIndex(tail, []byte("OggS")); i >= 0; i = bytes.LastIndex(tail[:i], []byte("OggS")) {
+		if i+18 <= len(tail) && binary.LittleEndian.Uint32(tail[i+14:]) == serial {
+			return binary.LittleEndian.Uint64(tail[i+6:]), true
+		}
+	}
+	return 0, false
+}
+
+// --- MP4 (iTunes metadata) ---
+
+func readMP4Audio(s *stream) (*AudioTags, error) {
+	moov, err := readTopLevelBox(s, "moov")
+	if errors.Is(err, errBoxNotFound) {
+		return nil, ErrNotAudio
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tags := &AudioTags{Format: FormatMP4}
+	if mvhd, ok := findBox(moov.data, moov.start, "mvhd"); ok {
+		if header, ok := parseMovieHeader(mvhd.data); ok {
+			tags.Duration = header.duration
+		}
+	}
+
+	if ilst, ok := findILST(moov); ok {
+		parseILST(ilst.data, tags)
+	}
+	return tags, nil
+}
+
+// findILST looks for the iTunes item list in moov/udta/meta or moov/meta.
+func findILST(moov box) (box, bool) {
+	parents := []box{moov}
+	if udta, ok := findBox(moov.data, moov.start, "udta"); ok {
+		parents = append([]box{udta}, parents...)
+	}
+	for _, parent := range parents {
+		meta, ok := findBox(parent.data, parent.start, "meta")
+		if !ok {
+			continue
+		}
+		children, base := metaChildren(meta)
+		if ilst, ok := findBox(children, base, "ilst"); ok {
+			return ilst, true
+		}
+	}
+	return box{}, false
+}
+
+func parseILST(ilst []byte, tags *AudioTags) {
+	_ = walkBoxes(ilst, 0, func(item box) bool {
+		data, ok := findBox(item.data, 0, "data")
+		if !ok || len(data.data) < 8 {
+			return true
+		}
+		value := data.data[8:] // skip type indicator and locale
+		text := strings.TrimSpace(string(value))
+
+		switch item.typ {
+		case "\xa9nam":
+			tags.Title = text
+		case "\xa9ART":
+			tags.Artist = text
+		case "aART":
+			tags.AlbumArtist = text
+		case "\xa9alb":
+			tags.Album = text
+		case "\xa9gen":
+			tags.Genre = text
+		case "gnre":
+			if len(value) >= 2 {
+				tags.Genre = id3v1Genre(int(binary.BigEndian.Uint16(value)) - 1)
+			}
+		case "\xa9day":
+			tags.Year = parseYear(text)
+		case "trkn":
+			if len(value) >= 6 {
+				tags.Track = int(binary.BigEndian.Uint16(value[2:]))
+				tags.TrackTotal = int(binary.BigEndian.Uint16(value[4:]))
+			}
+		case "disk":
+			if len(value) >= 6 {
+				tags.Disc = int(binary.BigEndian.Uint16(value[2:]))
+				tags.DiscTotal = int(binary.BigEndian.Uint16(value[4:]))
+			}
+		case "cpil":
+			tags.Compilation = len(value) > 0 && value[0] != 0
+		}
+		return true
+	})
+}
+
+// movieHeader is the content of an MP4 mvhd box.
+type movieHeader struct {
+	created  time.Time
+	duration time.Duration
+}
+
+// mp4Epoch is the origin of MP4 timestamps.
+var mp4Epoch = time.Date(1904, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func mp4Time(seconds uint64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return mp4Epoch.Add(time.Duration(seconds) * time.Second)
+}
+
+func parseMovieHeader(b []byte) (movieHeader, bool) {
+	var created, timescale, duration uint64
+	switch {
+	case len(b) >= 32 && b[0] == 1:
+		created = binary.BigEndian.Uint64(b[4:])
+		timescale = uint64(binary.BigEndian.Uint32(b[20:]))
+		duration = binary.BigEndian.Uint64(b[24:])
+	case len(b) >= 20:
+		created = uint64(binary.BigEndian.Uint32(b[4:]))
+		timescale = uint64(binary.BigEndian.Uint32(b[12:]))
+		duration = uint64(binary.BigEndian.Uint32(b[16:]))
+	default:
+		return movieHeader{}, false
+	}
+	h := movieHeader{created: mp4Time(created)}
+	if timescale > 0 && duration != 0xFFFFFFFF && duration != 0xFFFFFFFFFFFFFFFF {
+		h.duration = time.Duration(float64(duration) / float64(timescale) * float64(time.Second))
+	}
+	return h, true
+}
+
+// --- helpers ---
+
+func firstNonEmpty(current, value string) string {
+	if current != "" {
+		return current
+	}
+	return value
+}
+
+// parseYear extracts the year from values such as "2001", "2001-05-03" or "2001-05-03T10:00:00Z".
+func parseYear(s string) int {
+	s = strings.TrimSpace(s)
+	if len(s) < 4 {
+		return 0
+	}
+	year, err := strconv.Atoi(s[:4])
+	if err != nil {
+		return 0
+	}
+	return year
+}
+
+// parseNumberPair parses "3" or "3/12". total is kept when the value has no total.
+func parseNumberPair(s string, total int) (int, int) {
+	num, tot, hasTotal := strings.Cut(strings.TrimSpace(s), "/")
+	n, _ := strconv.Atoi(strings.TrimSpace(num))
+	if hasTotal {
+		if t, err := strconv.Atoi(strings.TrimSpace(tot)); err == nil {
+			total = t
+		}
+	}
+	return n, total
+}
@@ -0,0 +1,243 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func id3Frame(id string, text []byte) []byte {
+	b := []byte(id)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(text)))
+	return append(b, 0, 0)
+}
+
+func id3TextFrame(id, text string) []byte {
+	payload := append([]byte{3}, text...)
+	return append(id3Frame(id, payload), payload...)
+}
+
+func id3v23Tag(frames ...[]byte) []byte {
+	body := bytes.Join(frames, nil)
+	size := len(body)
+	header := []byte{'I', 'D', '3', 3, 0, 0,
+		byte(size >> 21 & 0x7F), byte(size >> 14 & 0x7F), byte(size >> 7 & 0x7F), byte(size & 0x7F)}
+	return append(header, body...)
+}
+
+// mpegFrameWithXing is an MPEG 1 layer III frame (128 kbps, 44.1 kHz, stereo) with a Xing header.
+func mpegFrameWithXing(frames uint32) []byte {
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0x00})
+	copy(frame[36:], "Xing")
+	binary.BigEndian.PutUint32(frame[40:], 1)
+	binary.BigEndian.PutUint32(frame[44:], frames)
+	return frame
+}
+
+func readTags(t *testing.T, data []byte) *AudioTags {
+	t.Helper()
+	tags, err := ReadAudioTags(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	return tags
+}
+
+func TestReadAudioTags_ID3v2(t *testing.T) {
+	utf16Artist := []byte{1, 0xFF, 0xFE, 'B', 0, 'j', 0, 0xF6, 0, 'r', 0, 'k', 0}
+	file := id3v23Tag(
+		id3TextFrame("TIT2", "Hyperballad"),
+		append(id3Frame("TPE1", utf16Artist), utf16Artist...),
+		id3TextFrame("TALB", "Post"),
+		id3TextFrame("TCON", "(13)"),
+		id3TextFrame("TYER", "1995"),
+		id3TextFrame("TRCK", "3/11"),
+		id3TextFrame("TPOS", "1/1"),
+	)
+	file = append(file, mpegFrameWithXing(1000)...)
+
+	tags := readTags(t, file)
+	assert.Equal(t, FormatMP3, tags.Format)
+	assert.Equal(t, "Hyperballad", tags.Title)
+	assert.Equal(t, "Björk", tags.Artist)
+	assert.Equal(t, "Post", tags.Album)
+	assert.Equal(t, "Pop", tags.Genre)
+	assert.Equal(t, 1995, tags.Year)
+	assert.Equal(t, 3, tags.Track)
+	assert.Equal(t, 11, tags.TrackTotal)
+	assert.Equal(t, 1, tags.Disc)
+	assert.InDelta(t, 26.12, tags.Duration.Seconds(), 0.01)
+}
+
+func TestReadAudioTags_ID3v1(t *testing.T) {
+	frame := mpegFrameWithXing(0)
+	copy(frame[36:], "none") // no Xing header: constant bitrate estimation
+	audio := bytes.Repeat(frame, 10)
+
+	tag := make([]byte, id3v1Size)
+	copy(tag, "TAG")
+	copy(tag[3:], "Song")
+	copy(tag[33:], "Band")
+	copy(tag[63:], "Record")
+	copy(tag[93:], "1984")
+	tag[126] = 7
+	tag[127] = 17
+
+	tags := readTags(t, append(audio, tag...))
+	assert.Equal(t, "Song", tags.Title)
+	assert.Equal(t, "Band", tags.Artist)
+	assert.Equal(t, "Record", tags.Album)
+	assert.Equal(t, 1984, tags.Year)
+	assert.Equal(t, 7, tags.Track)
+	assert.Equal(t, "Rock", tags.Genre)
+	assert.Greater(t, tags.Duration, time.Duration(0))
+}
+
+func TestID3v1Genre(t *testing.T) {
+	assert.Equal(t, "Blues", id3v1Genre(0))
+	assert.Equal(t, "Hard Rock", id3v1Genre(79))
+	// Winamp extensions
+	assert.Equal(t, "Folk", id3v1Genre(80))
+	assert.Equal(t, "Podcast", id3v1Genre(186))
+	assert.Equal(t, "Psybient", id3v1Genre(191))
+	assert.Empty(t, id3v1Genre(192))
+	assert.Empty(t, id3v1Genre(255))
+}
+
+func vorbisComments(comments ...string) []byte {
+	b := binary.LittleEndian.AppendUint32(nil, 6)
+	b = append(b, "vendor"...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(comments)))
+	for _, c := range comments {
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(c)))
+		b = append(b, c...)
+	}
+	return b
+}
+
+func TestReadAudioTags_FLAC(t *testing.T) {
+	streamInfo := make([]byte, 34)
+	// 44100 Hz, 441000 samples
+	streamInfo[10], streamInfo[11], streamInfo[12] = 0x0A, 0xC4, 0x40
+	binary.BigEndian.PutUint32(streamInfo[14:], 441000)
+	comments := vorbisComments("TITLE=Intro", "ARTIST=Various", "ALBUMARTIST=Various Artists",
+		"DATE=2020-02-02", "TRACKNUMBER=1", "TRACKTOTAL=20", "COMPILATION=1", "genre=Electronic")
+
+	file := []byte("fLaC")
+	file = append(file, 0x00, 0, 0, 34)
+	file = append(file, streamInfo...)
+	file = append(file, 0x84, 0, byte(len(comments)>>8), byte(len(comments)))
+	file = append(file, comments...)
+
+	tags := readTags(t, file)
+	assert.Equal(t, FormatFLAC, tags.Format)
+	assert.Equal(t, "Intro", tags.Title)
+	assert.Equal(t, "Various Artists", tags.AlbumArtist)
+	assert.Equal(t, "Electronic", tags.Genre)
+	assert.Equal(t, 2020, tags.Year)
+	assert.Equal(t, 1, tags.Track)
+	assert.Equal(t, 20, tags.TrackTotal)
+	assert.True(t, tags.Compilation)
+	assert.Equal(t, 10*time.Second, tags.Duration)
+}
+
+func oggPageBytes(granule uint64, packet []byte) []byte {
+	var segments []byte
+	n := len(packet)
+	for n >= 255 {
+		segments = append(segments, 255)
+		n -= 255
+	}
+	segments = append(segments, byte(n))
+
+	page := []byte("OggS\x00\x00")
+	page = binary.LittleEndian.AppendUint64(page, granule)
+	page = binary.LittleEndian.AppendUint32(page, 42) // serial
+	page = append(page, make([]byte, 8)...)           // sequence and checksum
+	page = append(page, byte(len(segments)))
+	page = append(page, segments...)
+	return append(page, packet...)
+}
+
+func TestReadAudioTags_OggVorbis(t *testing.T) {
+	ident := make([]byte, 30)
+	copy(ident, "\x01vorbis")
+	binary.LittleEndian.PutUint32(ident[12:], 44100)
+	comments := append([]byte("\x03vorbis"), vorbisComments("ARTIST=Someone", "ALBUM=Something")...)
+
+	file := oggPageBytes(0, ident)
+	file = append(file, oggPageBytes(0, comments)...)
+	file = append(file, oggPageBytes(44100*5, []byte("audio"))...)
+
+	tags := readTags(t, file)
+	assert.Equal(t, FormatOgg, tags.Format)
+	assert.Equal(t, "Someone", tags.Artist)
+	assert.Equal(t, "Something", tags.Album)
+	assert.Equal(t, 5*time.Second, tags.Duration)
+}
+
+func ilstItem(typ string, value []byte) []byte {
+	return bmffBox(typ, bmffBox("data", []byte{0, 0, 0, 1, 0, 0, 0, 0}, value))
+}
+
+func TestReadAudioTags_MP4(t *testing.T) {
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], 1000)   // timescale
+	binary.BigEndian.PutUint32(mvhd[16:], 185000) // duration
+
+	ilst := bmffBox("ilst",
+		ilstItem("\xa9nam", []byte("Track")),
+		ilstItem("\xa9ART", []byte("Artist")),
+		ilstItem("\xa9alb", []byte("Album")),
+		ilstItem("\xa9day", []byte("2011-01-01T08:00:00Z")),
+		ilstItem("trkn", []byte{0, 0, 0, 4, 0, 10, 0, 0}),
+		ilstItem("disk", []byte{0, 0, 0, 2, 0, 2}),
+		ilstItem("gnre", []byte{0, 10}), // ID3v1 genre index + 1
+	)
+	meta := bmffBox("meta", []byte{0, 0, 0, 0}, bmffBox("hdlr", make([]byte, 25)), ilst)
+	moov := bmffBox("moov", bmffBox("mvhd", mvhd), bmffBox("udta", meta))
+
+	// moov is stored after the media data, as done by most encoders
+	file := bmffBox("ftyp", []byte("M4A "), []byte{0, 0, 0, 0})
+	file = append(file, bmffBox("mdat", make([]byte, 4096))...)
+	file = append(file, moov...)
+
+	tags := readTags(t, file)
+	assert.Equal(t, FormatMP4, tags.Format)
+	assert.Equal(t, "Track", tags.Title)
+	assert.Equal(t, "Artist", tags.Artist)
+	assert.Equal(t, "Album", tags.Album)
+	assert.Equal(t, 2011, tags.Year)
+	assert.Equal(t, 4, tags.Track)
+	assert.Equal(t, 10, tags.TrackTotal)
+	assert.Equal(t, 2, tags.Disc)
+	assert.Equal(t, "Metal", tags.Genre)
+	assert.Equal(t, 185*time.Second, tags.Duration)
+
+	// Same result without seeking
+	tags, err := ReadAudioTags(io.MultiReader(bytes.NewReader(file)), int64(len(file)))
+	require.NoError(t, err)
+	assert.Equal(t, "Track", tags.Title)
+}
+
+func TestReadAudioTags_NotAudio(t *testing.T) {
+	_, err := ReadAudioTags(bytes.NewReader([]byte("plain text file")), 15)
+	assert.ErrorIs(t, err, ErrNotAudio)
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package metadata
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// box is an ISO base media file format (MP4, MOV, HEIC) box located in a buffer.
+type box struct {
+	typ string
+	// start is the file offset of the box payload (after the header)
+	start int64
+	data  []byte
+	// truncated is true when the box extends beyond the buffer
+	truncated bool
+}
+
+// walkBoxes iterates over the boxes found in buf. base is the file offset of buf[0].
+// Iteration stops at the first malformed box or when fn returns false.
+func walkBoxes(buf []byte, base int64, fn func(b box) bool) error {
+	pos := 0
+	for pos+8 <= len(buf) {
+		size := int64(binary.BigEndian.Uint32(buf[pos:]))
+		typ := string(buf[pos+4 : pos+8])
+		header := 8
+		switch size {
+		case 0:
+			size = int64(len(buf) - pos)
+		case 1:
+			if pos+16 > len(buf) {
+				return nil
+			}
+			size = int64(binary.BigEndian.Uint64(buf[pos+8:]))
+			header = 16
+		}
+		if size < int64(header) {
+			return fmt.Errorf("invalid box %q size %d at offset %d", typ, size, base+int64(pos))
+		}
+
+		end := int64(len(buf))
+		truncated := size > end-int64(pos)
+		if !truncated {
+			end = int64(pos) + size
+		}
+
+		b := box{
+			typ:       typ,
+			start:     base + int64(pos+header),
+			data:      buf[pos+header : end],
+			truncated: truncated,
+		}
+		if !fn(b) || truncated {
+			return nil
+		}
+		pos = int(end)
+	}
+	return nil
+}
+
+// findBox returns the first direct child box of the given type.
+func findBox(buf []byte, base int64, typ string) (box, bool) {
+	var found box
+	var ok bool
+	_ = walkBoxes(buf, base, func(b box) bool {
+		if b.typ == typ {
+			found, ok = b, true
+			return false
+		}
+		return true
+	})
+	return found, ok
+}
+
+// readUint reads a big-endian unsigned integer of n bytes (0, 2, 4 or 8).
+func readUint(buf []byte, n int) (uint64, bool) {
+	if len(buf) < n {
+		return 0, false
+	}
+	switch n {
+	case 0:
+		return 0, true
+	case 2:
+		return uint64(binary.BigEndian.Uint16(buf)), true
+	case 4:
+		return uint64(binary.BigEndian.Uint32(buf)), true
+	case 8:
+		return binary.BigEndian.Uint64(buf), true
+	default:
+		return 0, false
+	}
+}
+
+// errBoxNotFound is returned when a top-level box is missing from a stream.
+var errBoxNotFound = errors.New("box not found")
+
+// readTopLevelBox scans the top-level boxes of an ISO BMFF stream and loads the
+// first box of type typ in memory. Other boxes, including the media data, are skipped.
+func readTopLevelBox(s *stream, typ string) (box, error) {
+	for {
+		header, err := s.peek(8)
+		if err != nil {
+			return box{}, err
+		}
+		if len(header) < 8 {
+			return box{}, errBoxNotFound
+		}
+		start := s.pos
+		size := int64(binary.BigEndian.Uint32(header))
+		boxType := string(header[4:8])
+		headerSize := int64(8)
+		if err := s.skip(8); err != nil {
+			return box{}, err
+		}
+
+		switch size {
+		case 0:
+			// The box extends to the end of the file
+			if boxType != typ {
+				return box{}, errBoxNotFound
+			}
+			data, err := io.ReadAll(io.LimitReader(s, maxBlockSize))
+			if err != nil {
+				return box{}, err
+			}
+			return box{typ: boxType, start: start + headerSize, data: data}, nil
+		case 1:
+			large, err := s.readFull(8)
+			if err != nil {
+				return box{}, err
+			}
+			size = int64(binary.BigEndian.Uint64(large))
+			headerSize = 16
+		}
+		if size < headerSize {
+			return box{}, fmt.Errorf("invalid box %q size %d at offset %d", boxType, size, start)
+		}
+
+		if boxType == typ {
+			data, err := s.readFull(size - headerSize)
+			if err != nil {
+				return box{}, fmt.Errorf("cannot read box %q: %w", boxType, err)
+			}
+			return box{typ: boxType, start: start + headerSize, data: data}, nil
+		}
+		if err := s.skip(size - headerSize); err != nil {
+			if errors.Is(err, io.EOF) {
+				return box{}, errBoxNotFound
+			}
+			return box{}, err
+		}
+	}
+}
+
+// metaChildren returns the children of a "meta" box, which is a full box in
+// ISO files but a plain container in QuickTime files.
+func metaChildren(meta box) ([]byte, int64) {
+	if len(meta.data) >= 8 && string(meta.data[4:8]) == "hdlr" {
+		return meta.data, meta.start
+	}
+	if len(meta.data) < 4 {
+		return nil, meta.start
+	}
+	return meta.data[4:], meta.start + 4
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package metadata
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrNotDocument is returned when the data is not a supported document format.
+var ErrNotDocument = errors.New("not a supported document")
+
+// Document formats reported in DocumentInfo.Format.
+const (
+	FormatDOCX = "docx"
+	FormatXLSX = "xlsx"
+	FormatPPTX = "pptx"
+	FormatODT  = "odt"
+	FormatODS  = "ods"
+	FormatODP  = "odp"
+	FormatODG  = "odg"
+	FormatPDF  = "pdf"
+)
+
+// maxDocumentPartSize bounds the size of the metadata parts read from office documents.
+const maxDocumentPartSize = 1 << 20
+
+// DocumentInfo holds the metadata stored in an office document or a PDF file.
+// Fields are left empty when the document does not define them.
+type DocumentInfo struct {
+	Format   string
+	Title    string
+	Subject  string
+	Author   string
+	Keywords string
+	Company  string
+	Created  time.Time
+	Modified time.Time
+}
+
+// odfFormats maps ODF mimetypes to formats.
+var odfFormats = map[string]string{
+	"application/vnd.oasis.opendocument.text":         FormatODT,
+	"application/vnd.oasis.opendocument.spreadsheet":  FormatODS,
+	"application/vnd.oasis.opendocument.presentation": FormatODP,
+	"application/vnd.oasis.opendocument.graphics":     FormatODG,
+}
+
+// ReadDocumentInfo reads the metadata of OOXML (docx, xlsx, pptx) and ODF
+// (odt, ods, odp, odg) documents and PDF files.
+func ReadDocumentInfo(r io.ReaderAt, size int64) (*DocumentInfo, error) {
+	head := make([]byte, 8)
+	n, err := r.ReadAt(head, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	head = head[:n]
+
+	switch {
+	case bytes.HasPrefix(head, []byte("%PDF-")):
+		return readPDFInfo(r, size)
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		archive, err := zip.NewReader(r, size)
+		if err != nil {
+			return nil, ErrNotDocument
+		}
+		return readOfficeInfo(archive)
+	default:
+		return nil, ErrNotDocument
+	}
+}
+
+func readOfficeInfo(archive *zip.Reader) (*DocumentInfo, error) {
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	if mimetype, ok := files["mimetype"]; ok {
+		data, err := readZipPart(mimetype)
+		if err != nil {
+			return nil, err
+		}
+		format, ok := odfFormats[strings.TrimSpace(string(data))]
+		if !ok {
+			return nil, ErrNotDocument
+		}
+		return readODFInfo(format, files["meta.xml"])
+	}
+
+	info := &DocumentInfo{}
+	switch {
+	case files["word/document.xml"] != nil:
+		info.Format = FormatDOCX
+	case files["xl/workbook.xml"] != nil:
+		info.Format = FormatXLSX
+	case files["ppt/presentation.xml"] != nil:
+		info.Format = FormatPPTX
+	default:
+		return nil, ErrNotDocument
+	}
+
+	if file := files["docProps/core.xml"]; file != nil {
+		var core struct {
+			Title    string `xml:"title"`
+			Subject  string `xml:"subject"`
+			Creator  string `xml:"creator"`
+			Keywords string `xml:"keywords"`
+			Created  string `xml:"created"`
+			Modified string `xml:"modified"`
+		}
+		if err := unmarshalZipPart(file, &core); err != nil {
+			return nil, err
+		}
+		info.Title, info.Subject, info.Author, info.Keywords = core.Title, core.Subject, core.Creator, core.Keywords
+		info.Created = parseDocumentDate(core.Created)
+		info.Modified = parseDocumentDate(core.Modified)
+	}
+	if file := files["docProps/app.xml"]; file != nil {
+		var app struct {
+			Company string `xml:"Company"`
+		}
+		if err := unmarshalZipPart(file, &app); err != nil {
+			return nil, err
+		}
+		info.Company = app.Company
+	}
+	trimDocumentInfo(info)
+	return info, nil
+}
+
+func readODFInfo(format string, file *zip.File) (*DocumentInfo, error) {
+	info := &DocumentInfo{Format: format}
+	if file == nil {
+		return info, nil
+	}
+
+	var meta struct {
+		Meta struct {
+			Title          string   `xml:"title"`
+			Subject        string   `xml:"subject"`
+			InitialCreator string   `xml:"initial-creator"`
+			Creator        string   `xml:"creator"`
+			Keywords       []string `xml:"keyword"`
+			CreationDate   string   `xml:"creation-date"`
+			Date           string   `xml:"date"`
+			UserDefined    []struct {
+				Name  string `xml:"name,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"user-defined"`
+		} `xml:"meta"`
+	}
+	if err := unmarshalZipPart(file, &meta); err != nil {
+		return nil, err
+	}
+
+	m := meta.Meta
+	info.Title, info.Subject = m.Title, m.Subject
+	// dc:creator is the last person who modified the document
+	info.Author = m.InitialCreator
+	if info.Author == "" {
+		info.Author = m.Creator
+	}
+	info.Keywords = strings.Join(m.Keywords, ", ")
+	info.Created = parseDocumentDate(m.CreationDate)
+	info.Modified = parseDocumentDate(m.Date)
+	for _, field := range m.UserDefined {
+		if strings.EqualFold(field.Name, "Company") {
+			info.Company = field.Value
+		}
+	}
+	trimDocumentInfo(info)
+	return info, nil
+}
+
+func readZipPart(file *zip.File) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+	return io.ReadAll(io.LimitReader(rc, maxDocumentPartSize))
+}
+
+func unmarshalZipPart(file *zip.File, v any) error {
+	data, err := readZipPart(file)
+	if err != nil {
+		return err
+	}
+	return xml.Unmarshal(data, v)
+}
+
+func trimDocumentInfo(info *DocumentInfo) {
+	for _, field := range []*string{&info.Title, &info.Subject, &info.Author, &info.Keywords, &info.Company} {
+		*field = strings.TrimSpace(*field)
+	}
+}
+
+// parseDocumentDate parses the W3C date formats used by OOXML, ODF and XMP.
+// Dates without a time zone are read as UTC.
+func parseDocumentDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05.999999999",
+		"2006-01-02T15:04Z07:00",
+		"2006-01-02",
+		"2006-01",
+		"2006",
+	} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package metadata
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func zipParts(t *testing.T, parts map[string]string, order ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range order {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(parts[name]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func readDocument(t *testing.T, data []byte) *DocumentInfo {
+	t.Helper()
+	info, err := ReadDocumentInfo(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	return info
+}
+
+func TestReadDocumentInfo_OOXML(t *testing.T) {
+	parts := map[string]string{
+		"[Content_Types].xml": `<Types/>`,
+		"word/document.xml":   `<w:document/>`,
+		"docProps/core.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties"
+  xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/"
+  xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <dc:title>Quarterly report</dc:title>
+  <dc:subject>Finance</dc:subject>
+  <dc:creator>Alice Martin</dc:creator>
+  <cp:keywords>q1, budget</cp:keywords>
+  <dcterms:created xsi:type="dcterms:W3CDTF">2024-03-01T09:30:00Z</dcterms:created>
+  <dcterms:modified xsi:type="dcterms:W3CDTF">2024-03-05T17:00:00+01:00</dcterms:modified>
+</cp:coreProperties>`,
+		"docProps/app.xml": `<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/extended-properties">
+  <Application>Microsoft Office Word</Application><Company>ACME Corp</Company></Properties>`,
+	}
+	info := readDocument(t, zipParts(t, parts, "[Content_Types].xml", "word/document.xml", "docProps/core.xml", "docProps/app.xml"))
+
+	assert.Equal(t, FormatDOCX, info.Format)
+	assert.Equal(t, "Quarterly report", info.Title)
+	assert.Equal(t, "Finance", info.Subject)
+	assert.Equal(t, "Alice Martin", info.Author)
+	assert.Equal(t, "q1, budget", info.Keywords)
+	assert.Equal(t, "ACME Corp", info.Company)
+	assert.Equal(t, time.Date(2024, time.March, 1, 9, 30, 0, 0, time.UTC), info.Created)
+	assert.Equal(t, time.Date(2024, time.March, 5, 16, 0, 0, 0, time.UTC), info.Modified)
+}
+
+func TestReadDocumentInfo_OOXMLWithoutProperties(t *testing.T) {
+	parts := map[string]string{"[Content_Types].xml": `<Types/>`, "xl/workbook.xml": `<workbook/>`}
+	info := readDocument(t, zipParts(t, parts, "[Content_Types].xml", "xl/workbook.xml"))
+	assert.Equal(t, &DocumentInfo{Format: FormatXLSX}, info)
+}
+
+func TestReadDocumentInfo_ODF(t *testing.T) {
+	parts := map[string]string{
+		"mimetype": "application/vnd.oasis.opendocument.text",
+		"meta.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-meta xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+  xmlns:meta="urn:oasis:names:tc:opendocument:xmlns:meta:1.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <office:meta>
+    <meta:initial-creator>Bob Durand</meta:initial-creator>
+    <dc:creator>Alice Martin</dc:creator>
+    <dc:title>Compte rendu</dc:title>
+    <meta:keyword>réunion</meta:keyword>
+    <meta:keyword>projet</meta:keyword>
+    <meta:creation-date>2023-11-20T14:05:00.123</meta:creation-date>
+    <dc:date>2023-11-21T08:00:00</dc:date>
+    <meta:user-defined meta:name="Company">Durand SARL</meta:user-defined>
+  </office:meta>
+</office:document-meta>`,
+	}
+	info := readDocument(t, zipParts(t, parts, "mimetype", "meta.xml"))
+
+	assert.Equal(t, FormatODT, info.Format)
+	assert.Equal(t, "Bob Durand", info.Author)
+	assert.Equal(t, "Compte rendu", info.Title)
+	assert.Equal(t, "réunion, projet", info.Keywords)
+	assert.Equal(t, "Durand SARL", info.Company)
+	assert.Equal(t, time.Date(2023, time.November, 20, 14, 5, 0, 123e6, time.UTC), info.Created)
+	assert.Equal(t, time.Date(2023, time.November, 21, 8, 0, 0, 0, time.UTC), info.Modified)
+}
+
+// pdfDocument builds a PDF with an Info dictionary, extra objects and a classic xref table.
+func pdfDocument(info string, extra ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := []int{}
+	for i, obj := range append([]string{"<< /Type /Catalog >>", info}, extra...) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 2 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+func TestReadDocumentInfo_PDF(t *testing.T) {
+	data := pdfDocument(`<< /Title (Invoice \(draft\) n\2600) /Author <FEFF0041006C0069006300650020004D0061007200740069006E>
+  /Subject (Billing) /Trapped /False /Count 3 /Company (ACME Corp)
+  /Nested << /Title (ignored) >> /Array [ /Title (ignored) ]
+  /CreationDate (D:20240102153000+01'00') /ModDate (D:2024) >>`)
+	info := readDocument(t, data)
+
+	assert.Equal(t, FormatPDF, info.Format)
+	assert.Equal(t, "Invoice (draft) n°0", info.Title)
+	assert.Equal(t, "Alice Martin", info.Author)
+	assert.Equal(t, "Billing", info.Subject)
+	assert.Equal(t, "ACME Corp", info.Company)
+	assert.Equal(t, time.Date(2024, time.January, 2, 14, 30, 0, 0, time.UTC), info.Created)
+	assert.Equal(t, time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), info.Modified)
+}
+
+func TestReadDocumentInfo_PDFXrefStream(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.5\n1 0 obj\n<< /Type /Catalog >>\nendobj\n")
+	buf.WriteString("12 0 obj\n<< /Title (Wrong object) >>\nendobj\n")
+	buf.WriteString("2 0 obj\n<< /Title (Scanned report) >>\nendobj\n")
+	xref := buf.Len()
+	buf.WriteString("3 0 obj\n<< /Type /XRef /Size 4 /Root 1 0 R /Info 2 0 R /Filter /FlateDecode >>\nstream\nx\x9c\nendstream\nendobj\n")
+	fmt.Fprintf(&buf, "startxref\n%d\n%%%%EOF\n", xref)
+
+	info := readDocument(t, buf.Bytes())
+	assert.Equal(t, "Scanned report", info.Title)
+}
+
+func TestReadDocumentInfo_PDFXMP(t *testing.T) {
+	xmp := `<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+<rdf:Description rdf:about="" xmlns:xmp="http://ns.adobe.com/xap/1.0/" xmp:CreateDate="2022-06-01T10:00:00Z"
+  xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:title><rdf:Alt><rdf:li xml:lang="x-default">Annual review</rdf:li></rdf:Alt></dc:title>
+<dc:creator><rdf:Seq><rdf:li>Alice Martin</rdf:li><rdf:li>Bob Durand</rdf:li></rdf:Seq></dc:creator>
+</rdf:Description></rdf:RDF></x:xmpmeta>`
+	data := pdfDocument("<< /Producer (LaTeX) >>", "<< /Type /Metadata /Subtype /XML >>\nstream\n"+xmp+"\nendstream")
+	info := readDocument(t, data)
+
+	assert.Equal(t, "Annual review", info.Title)
+	assert.Equal(t, "Alice Martin, Bob Durand", info.Author)
+	assert.Equal(t, time.Date(2022, time.June, 1, 10, 0, 0, 0, time.UTC), info.Created)
+}
+
+func TestReadDocumentInfo_NotDocument(t *testing.T) {
+	for _, data := range [][]byte{
+		[]byte("plain text"),
+		zipParts(t, map[string]string{"a.txt": "text"}, "a.txt"),
+		zipParts(t, map[string]string{"mimetype": "application/epub+zip"}, "mimetype"),
+	} {
+		_, err := ReadDocumentInfo(bytes.NewReader(data), int64(len(data)))
+		assert.ErrorIs(t, err, ErrNotDocument)
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package metadata
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// ErrNotEmail is returned when the data is not an email message or a mailbox.
+var ErrNotEmail = errors.New("not an email message")
+
+// Email formats reported by ReadEmails.
+const (
+	FormatEML  = "eml"
+	FormatMbox = "mbox"
+)
+
+const (
+	// emailSniffSize is the amount of data checked for a header block.
+	emailSniffSize = 8 << 10
+	// maxMIMEDepth bounds the nesting of multipart bodies.
+	maxMIMEDepth = 10
+)
+
+var emailFieldName = regexp.MustCompile(`^[!-9;-~]+:`)
+
+// wordDecoder decodes RFC 2047 encoded words in any charset known to x/text.
+var wordDecoder = &mime.WordDecoder{
+	CharsetReader: func(charset string, input io.Reader) (io.Reader, error) {
+		enc, err := htmlindex.Get(charset)
+		if err != nil {
+			return nil, err
+		}
+		return enc.NewDecoder().Reader(input), nil
+	},
+}
+
+// EmailMessage holds the decoded headers of a message and its attachments.
+type EmailMessage struct {
+	From    []*mail.Address
+	To      []*mail.Address
+	Cc      []*mail.Address
+	Subject string
+	// Date is the zero time when the Date header is missing or invalid.
+	Date        time.Time
+	Attachments []EmailAttachment
+}
+
+// EmailAttachment describes an attached file.
+type EmailAttachment struct {
+	Filename  string
+	MediaType string
+}
+
+// ReadEmails reads an .eml message or every message of an mbox file and
+// passes them to fn until it returns false. Attachments are only listed when
+// withAttachments is true, otherwise message bodies are skipped.
+// It returns the format of the file.
+func ReadEmails(r io.Reader, withAttachments bool, fn func(*EmailMessage) bool) (string, error) {
+	br := bufio.NewReaderSize(r, emailSniffSize)
+	head, err := br.Peek(emailSniffSize)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return "", err
+	}
+
+	if bytes.HasPrefix(head, []byte("From ")) {
+		return FormatMbox, readMbox(br, withAttachments, fn)
+	}
+	if !looksLikeHeader(head) {
+		return "", ErrNotEmail
+	}
+	msg, err := readEmail(br, withAttachments)
+	if err != nil {
+		return "", err
+	}
+	fn(msg)
+	return FormatEML, nil
+}
+
+// looksLikeHeader reports whether data starts with a header block holding a From field.
+func looksLikeHeader(data []byte) bool {
+	if !emailFieldName.Match(data) {
+		return false
+	}
+	end := bytes.Index(data, []byte("\n\r\n"))
+	if i := bytes.Index(data, []byte("\n\n")); i >= 0 && (end < 0 || i < end) {
+		end = i
+	}
+	if end < 0 {
+		end = len(data)
+	}
+	for _, line := range bytes.Split(data[:end], []byte("\n")) {
+		if len(line) >= 5 && strings.EqualFold(string(line[:5]), "from:") {
+			return true
+		}
+	}
+	return false
+}
+
+func readMbox(br *bufio.Reader, withAttachments bool, fn func(*EmailMessage) bool) error {
+	mr := &mboxReader{br: br, lineStart: true}
+	for index := 0; mr.nextMessage(); index++ {
+		msg, err := readEmail(mr, withAttachments)
+		if err != nil {
+			// A malformed message does not hide the rest of the mailbox
+			slog.Debug("Skipping malformed mbox message", "index", index, "err", err)
+		} else if !fn(msg) {
+			return nil
+		}
+		// Skip the rest of the message up to the next separator line
+		if _, err := io.Copy(io.Discard, mr); err != nil {
+			return err
+		}
+	}
+	return mr.err
+}
+
+func readEmail(r io.Reader, withAttachments bool) (*EmailMessage, error) {
+	m, err := mail.ReadMessage(bufio.NewReader(r))
+	if err != nil {
+		return nil, fmt.Errorf("invalid message header: %w", err)
+	}
+
+	msg := &EmailMessage{
+		From: parseAddresses(m.Header.Get("From")),
+		To:   parseAddresses(m.Header.Get("To")),
+		Cc:   parseAddresses(m.Header.Get("Cc")),
+	}
+	msg.Subject, err = wordDecoder.DecodeHeader(m.Header.Get("Subject"))
+	if err != nil {
+		msg.Subject = m.Header.Get("Subject")
+	}
+	if date, err := m.Header.Date(); err == nil {
+		msg.Date = date.UTC()
+	}
+
+	if withAttachments {
+		header := textproto.MIMEHeader(m.Header)
+		if err := walkParts(header, m.Body, 0, msg); err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
+
+// parseAddresses parses an address list, ignoring invalid lists.
+func parseAddresses(list string) []*mail.Address {
+	if strings.TrimSpace(list) == "" {
+		return nil
+	}
+	parser := mail.AddressParser{WordDecoder: wordDecoder}
+	addresses, err := parser.ParseList(list)
+	if err != nil {
+		// Keep the address of a single malformed entry such as "John <john@example.com"
+		if address, err := parser.Parse(list); err == nil {
+			return []*mail.Address{address}
+		}
+		return nil
+	}
+	return addresses
+}
+
+// walkParts lists the attachments of a MIME entity.
+func walkParts(header textproto.MIMEHeader, body io.Reader, depth int, msg *EmailMessage) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", nil
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") && depth < maxMIMEDepth {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				// A truncated message keeps the attachments found so far
+				return nil
+			}
+			if err := walkParts(part.Header, part, depth+1, msg); err != nil {
+				return err
+			}
+		}
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := dispositionParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	if decoded, err := wordDecoder.DecodeHeader(filename); err == nil {
+		filename = decoded
+	}
+	if disposition == "attachment" || (filename != "" && disposition != "inline") {
+		msg.Attachments = append(msg.Attachments, EmailAttachment{
+			Filename:  path.Base(strings.ReplaceAll(filename, "\\", "/")),
+			MediaType: mediaType,
+		})
+	}
+	return nil
+}
+
+// mboxReader reads the messages of an mbox file one at a time. A message ends
+// before a line starting with "From " that follows an empty line.
+type mboxReader struct {
+	br        *bufio.Reader
+	pending   []byte
+	lineStart bool
+	prevBlank bool
+	inMessage bool
+	// separator is true when the separator of the next message was already read
+	separator bool
+	eof       bool
+	err       error
+}
+
+// nextMessage skips the separator line of the next message and reports whether there is one.
+func (m *mboxReader) nextMessage() bool {
+	if m.separator {
+		m.separator, m.inMessage, m.prevBlank = false, true, false
+		return true
+	}
+	for !m.eof {
+		line, err := m.readLine()
+		if err != nil {
+			return false
+		}
+		if bytes.HasPrefix(line, []byte("From ")) {
+			m.inMessage, m.prevBlank = true, false
+			return true
+		}
+	}
+	return false
+}
+
+func (m *mboxReader) Read(p []byte) (int, error) {
+	for len(m.pending) == 0 {
+		if !m.inMessage || m.eof {
+			return 0, io.EOF
+		}
+		start := m.lineStart
+		line, err := m.readLine()
+		if err != nil {
+			return 0, err
+		}
+		if start && m.prevBlank && bytes.HasPrefix(line, []byte("From ")) {
+			m.inMessage, m.separator = false, true
+			return 0, io.EOF
+		}
+		m.prevBlank = start && len(bytes.TrimRight(line, "\r\n")) == 0
+		if start && bytes.HasPrefix(line, []byte(">From ")) {
+			line = line[1:] // mboxrd escaping
+		}
+		m.pending = line
+	}
+	n := copy(p, m.pending)
+	m.pending = m.pending[n:]
+	return n, nil
+}
+
+// readLine returns the next line, or a part of it when it is longer than the buffer.
+func (m *mboxReader) readLine() ([]byte, error) {
+	line, err := m.br.ReadSlice('\n')
+	switch {
+	case err == nil:
+		m.lineStart = true
+	case errors.Is(err, bufio.ErrBufferFull):
+		m.lineStart = false
+	case errors.Is(err, io.EOF):
+		m.eof = true
+		if len(line) == 0 {
+			return nil, io.EOF
+		}
+	default:
+		m.err = err
+		return nil, err
+	}
+	return append([]byte(nil), line...), nil
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package metadata
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const invoiceEmail = "From: =?ISO-8859-1?Q?Fran=E7ois_Dupont?= <francois@billing.example.com>\r\n" +
+	"To: Alice <alice@example.org>, bob@example.net\r\n" +
+	"Cc: accounting@example.org\r\n" +
+	"Subject: =?UTF-8?B?RmFjdHVyZSBuwrAxMg==?=\r\n" +
+	"Date: Tue, 2 Apr 2024 10:15:00 +0200\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=\"outer\"\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=\"inner\"\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"\r\n" +
+	"Please find the invoice attached.\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"\r\n" +
+	"<p>Please find the invoice attached.</p>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: application/pdf; name=\"invoice-12.pdf\"\r\n" +
+	"Content-Disposition: attachment; filename=\"invoice-12.pdf\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"JVBERi0xLjQK\r\n" +
+	"--outer\r\n" +
+	"Content-Type: image/png\r\n" +
+	"Content-Disposition: inline\r\n" +
+	"\r\n" +
+	"logo\r\n" +
+	"--outer\r\n" +
+	"Content-Type: application/octet-stream; name=\"=?UTF-8?Q?r=C3=A9sum=C3=A9.docx?=\"\r\n" +
+	"\r\n" +
+	"data\r\n" +
+	"--outer--\r\n"
+
+func readAllEmails(t *testing.T, data string, withAttachments bool) (string, []*EmailMessage) {
+	t.Helper()
+	var messages []*EmailMessage
+	format, err := ReadEmails(strings.NewReader(data), withAttachments, func(msg *EmailMessage) bool {
+		messages = append(messages, msg)
+		return true
+	})
+	require.NoError(t, err)
+	return format, messages
+}
+
+func TestReadEmails_EML(t *testing.T) {
+	format, messages := readAllEmails(t, invoiceEmail, true)
+	require.Len(t, messages, 1)
+	msg := messages[0]
+
+	assert.Equal(t, FormatEML, format)
+	require.Len(t, msg.From, 1)
+	assert.Equal(t, "François Dupont", msg.From[0].Name)
+	assert.Equal(t, "francois@billing.example.com", msg.From[0].Address)
+	require.Len(t, msg.To, 2)
+	assert.Equal(t, "bob@example.net", msg.To[1].Address)
+	require.Len(t, msg.Cc, 1)
+	assert.Equal(t, "Facture n°12", msg.Subject)
+	assert.Equal(t, time.Date(2024, time.April, 2, 8, 15, 0, 0, time.UTC), msg.Date)
+	assert.Equal(t, []EmailAttachment{
+		{Filename: "invoice-12.pdf", MediaType: "application/pdf"},
+		{Filename: "résumé.docx", MediaType: "application/octet-stream"},
+	}, msg.Attachments)
+}
+
+func TestReadEmails_WithoutAttachments(t *testing.T) {
+	_, messages := readAllEmails(t, invoiceEmail, false)
+	require.Len(t, messages, 1)
+	assert.Empty(t, messages[0].Attachments)
+}
+
+func TestReadEmails_Mbox(t *testing.T) {
+	mbox := "From alice@example.org Mon Jan  1 10:00:00 2024\n" +
+		"From: Alice <alice@example.org>\n" +
+		"Subject: First\n" +
+		"\n" +
+		">From the archive: this line is escaped\n" +
+		"\n" +
+		"From bob@example.net Tue Jan  2 10:00:00 2024\n" +
+		"From: bob@example.net\n" +
+		"Subject: Second\n" +
+		"Content-Type: multipart/mixed; boundary=b\n" +
+		"\n" +
+		"--b\n" +
+		"Content-Disposition: attachment; filename=photo.jpg\n" +
+		"Content-Type: image/jpeg\n" +
+		"\n" +
+		"jpeg\n" +
+		"--b--\n"
+
+	format, messages := readAllEmails(t, mbox, true)
+	assert.Equal(t, FormatMbox, format)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "First", messages[0].Subject)
+	assert.Equal(t, "Second", messages[1].Subject)
+	assert.Equal(t, "bob@example.net", messages[1].From[0].Address)
+	assert.Equal(t, []EmailAttachment{{Filename: "photo.jpg", MediaType: "image/jpeg"}}, messages[1].Attachments)
+
+	// Reading stops when the callback returns false
+	count := 0
+	_, err := ReadEmails(strings.NewReader(mbox), false, func(*EmailMessage) bool {
+		count++
+		return false
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestReadEmails_MboxSkipsMalformedMessage(t *testing.T) {
+	mbox := "From alice@example.org Mon Jan  1 10:00:00 2024\n" +
+		"From: Alice <alice@example.org>\n" +
+		"Subject: First\n" +
+		"\n" +
+		"body\n" +
+		"\n" +
+		"From mallory@example.com Mon Jan  1 11:00:00 2024\n" +
+		"this line is not a header field\n" +
+		"\n" +
+		"body\n" +
+		"\n" +
+		"From bob@example.net Tue Jan  2 10:00:00 2024\n" +
+		"From: bob@example.net\n" +
+		"Subject: Third\n" +
+		"\n" +
+		"body\n"
+
+	_, messages := readAllEmails(t, mbox, true)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "First", messages[0].Subject)
+	assert.Equal(t, "Third", messages[1].Subject)
+}
+
+func TestReadEmails_NotEmail(t *testing.T) {
+	for _, data := range []string{
+		"plain text",
+		"Title: notes\nAuthor: me\n\nbody",
+		"",
+	} {
+		_, err := ReadEmails(strings.NewReader(data), false, func(*EmailMessage) bool { return true })
+		assert.ErrorIs(t, err, ErrNotEmail, "data %q", data)
+	}
+}
@@ -0,0 +1,475 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+// Package metadata contains pure-Go parsers for the metadata embedded in
+// media files (EXIF, audio tags, container headers...).
+// Parsers work on in-memory buffers so that callers decide how much of a
+// file is read, usually through the filter context's WithInputLimited.
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrNoEXIF is returned when the data does not contain any EXIF metadata.
+var ErrNoEXIF = errors.New("no EXIF metadata found")
+
+const exifTimeLayout = "2006:01:02 15:04:05"
+
+// EXIF holds the subset of EXIF tags FolderFlow knows how to route on.
+type EXIF struct {
+	Make      string
+	Model     string
+	LensMake  string
+	LensModel string
+	Software  string
+	// Orientation is the raw EXIF orientation (1-8), 0 when absent.
+	Orientation int
+	// Raw date strings as stored in the file ("2006:01:02 15:04:05").
+	DateTimeOriginal   string
+	DateTime           string
+	OffsetTimeOriginal string
+	HasGPS             bool
+	Latitude           float64
+	Longitude          float64
+}
+
+// Taken returns the date the picture was taken.
+// DateTimeOriginal is preferred over DateTime. EXIF dates carry no time zone
+// unless OffsetTimeOriginal is set, in which case loc is ignored.
+func (e *EXIF) Taken(loc *time.Location) (time.Time, bool) {
+	if loc == nil {
+		loc = time.Local
+	}
+	for _, raw := range []string{e.DateTimeOriginal, e.DateTime} {
+		if raw == "" {
+			continue
+		}
+		if e.OffsetTimeOriginal != "" && raw == e.DateTimeOriginal {
+			if t, err := time.Parse(exifTimeLayout+"-07:00", raw+e.OffsetTimeOriginal); err == nil {
+				return t, true
+			}
+		}
+		if t, err := time.ParseInLocation(exifTimeLayout, raw, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ParseEXIF extracts EXIF metadata from the beginning of a JPEG, TIFF
+// (including TIFF based raw formats) or HEIF/HEIC file.
+// It returns ErrNoEXIF when the format is not supported or holds no EXIF.
+func ParseEXIF(data []byte) (*EXIF, error) {
+	switch {
+	case len(data) >= 2 && data[0] == 0xFF && data[1] == 0xD8:
+		return parseJPEGEXIF(data)
+	case isTIFFHeader(data):
+		return parseTIFF(data)
+	case len(data) >= 12 && string(data[4:8]) == "ftyp":
+		return parseHEIFEXIF(data)
+	default:
+		return nil, ErrNoEXIF
+	}
+}
+
+func isTIFFHeader(b []byte) bool {
+	return len(b) >= 8 &&
+		(bytes.HasPrefix(b, []byte("II*\x00")) || bytes.HasPrefix(b, []byte("MM\x00*")))
+}
+
+func parseJPEGEXIF(data []byte) (*EXIF, error) {
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return nil, fmt.Errorf("invalid JPEG marker at offset %d", pos)
+		}
+		marker := data[pos+1]
+		switch {
+		case marker == 0xFF: // fill byte
+			pos++
+			continue
+		case marker == 0x01 || marker >= 0xD0 && marker <= 0xD8:
+			pos += 2
+			continue
+		case marker == 0xD9 || marker == 0xDA:
+			// EXIF always precedes the image data
+			return nil, ErrNoEXIF
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 {
+			return nil, fmt.Errorf("invalid JPEG segment length at offset %d", pos)
+		}
+		start, end := pos+4, pos+2+length
+		if marker == 0xE1 && end-start >= 6 && bytes.HasPrefix(data[start:], []byte("Exif\x00\x00")) {
+			if end > len(data) {
+				return nil, fmt.Errorf("EXIF segment exceeds the %d bytes read", len(data))
+			}
+			return parseTIFF(data[start+6 : end])
+		}
+		pos = end
+	}
+	return nil, ErrNoEXIF
+}
+
+func parseHEIFEXIF(data []byte) (*EXIF, error) {
+	meta, ok := findBox(data, 0, "meta")
+	if !ok {
+		return nil, ErrNoEXIF
+	}
+	children, childBase := metaChildren(meta)
+
+	iinf, ok := findBox(children, childBase, "iinf")
+	if !ok {
+		return nil, ErrNoEXIF
+	}
+	itemID, ok := heifExifItemID(iinf.data)
+	if !ok {
+		return nil, ErrNoEXIF
+	}
+
+	iloc, ok := findBox(children, childBase, "iloc")
+	if !ok {
+		return nil, ErrNoEXIF
+	}
+	offset, length, ok := heifItemLocation(iloc.data, itemID)
+	if !ok {
+		return nil, fmt.Errorf("cannot locate EXIF item %d", itemID)
+	}
+	if length < 4 || length > uint64(len(data)) || offset > uint64(len(data))-length {
+		return nil, fmt.Errorf("EXIF item exceeds the %d bytes read", len(data))
+	}
+
+	payload := data[offset : offset+length]
+	tiffOffset := uint64(binary.BigEndian.Uint32(payload)) + 4
+	if tiffOffset < uint64(len(payload)) && isTIFFHeader(payload[tiffOffset:]) {
+		return parseTIFF(payload[tiffOffset:])
+	}
+	// Some writers get the header offset wrong, look for the TIFF header instead
+	if i := bytes.Index(payload, []byte("Exif\x00\x00")); i >= 0 && isTIFFHeader(payload[i+6:]) {
+		return parseTIFF(payload[i+6:])
+	}
+	return nil, fmt.Errorf("invalid EXIF item payload")
+}
+
+// heifExifItemID returns the id of the first item of type "Exif" in an iinf box.
+func heifExifItemID(iinf []byte) (uint32, bool) {
+	if len(iinf) < 6 {
+		return 0, false
+	}
+	countSize := 2
+	if iinf[0] != 0 {
+		countSize = 4
+	}
+	if len(iinf) < 4+countSize {
+		return 0, false
+	}
+	entries := iinf[4+countSize:]
+
+	var id uint32
+	var found bool
+	_ = walkBoxes(entries, 0, func(b box) bool {
+		if b.typ != "infe" || len(b.data) < 4 || b.data[0] < 2 {
+			return true
+		}
+		idSize := 2
+		if b.data[0] >= 3 {
+			idSize = 4
+		}
+		rest := b.data[4:]
+		if len(rest) < idSize+2+4 {
+			return true
+		}
+		v, _ := readUint(rest, idSize)
+		if string(rest[idSize+2:idSize+6]) == "Exif" {
+			id, found = uint32(v), true
+			return false
+		}
+		return true
+	})
+	return id, found
+}
+
+// heifItemLocation returns the file offset and length of an item from an iloc box.
+// Only items stored in the file itself (construction method 0) with a single extent are supported.
+func heifItemLocation(iloc []byte, itemID uint32) (uint64, uint64, bool) {
+	if len(iloc) < 8 {
+		return 0, 0, false
+	}
+	version := iloc[0]
+	offsetSize := int(iloc[4] >> 4)
+	lengthSize := int(iloc[4] & 0x0F)
+	baseOffsetSize := int(iloc[5] >> 4)
+	indexSize := 0
+	if version == 1 || version == 2 {
+		indexSize = int(iloc[5] & 0x0F)
+	}
+
+	pos := 6
+	// next reads a big-endian integer of n bytes and moves past it
+	next := func(n int) (uint64, bool) {
+		if pos+n > len(iloc) {
+			return 0, false
+		}
+		v, ok := readUint(iloc[pos:], n)
+		pos += n
+		return v, ok
+	}
+
+	idSize := 2
+	if version == 2 {
+		idSize = 4
+	}
+	count, ok := next(idSize)
+	if !ok {
+		return 0, 0, false
+	}
+
+	for i := uint64(0); i < count; i++ {
+		id, ok := next(idSize)
+		if !ok {
+			return 0, 0, false
+		}
+		method := uint64(0)
+		if version == 1 || version == 2 {
+			if method, ok = next(2); !ok {
+				return 0, 0, false
+			}
+			method &= 0x0F
+		}
+		if _, ok = next(2); !ok { // data_reference_index
+			return 0, 0, false
+		}
+		base, ok := next(baseOffsetSize)
+		if !ok {
+			return 0, 0, false
+		}
+		extents, ok := next(2)
+		if !ok {
+			return 0, 0, false
+		}
+
+		var firstOffset, totalLength uint64
+		for e := uint64(0); e < extents; e++ {
+			_, ok1 := next(indexSize)
+			off, ok2 := next(offsetSize)
+			length, ok3 := next(lengthSize)
+			if !ok1 || !ok2 || !ok3 {
+				return 0, 0, false
+			}
+			if e == 0 {
+				firstOffset = off
+			}
+			totalLength += length
+		}
+
+		if uint32(id) == itemID {
+			if method != 0 || extents != 1 || base+firstOffset < base {
+				return 0, 0, false
+			}
+			return base + firstOffset, totalLength, true
+		}
+	}
+	return 0, 0, false
+}
+
+// --- TIFF structure ---
+
+const (
+	tagMake               = 0x010F
+	tagModel              = 0x0110
+	tagOrientation        = 0x0112
+	tagSoftware           = 0x0131
+	tagDateTime           = 0x0132
+	tagExifIFD            = 0x8769
+	tagGPSIFD             = 0x8825
+	tagDateTimeOriginal   = 0x9003
+	tagOffsetTimeOriginal = 0x9011
+	tagLensMake           = 0xA433
+	tagLensModel          = 0xA434
+	tagGPSLatitudeRef     = 0x0001
+	tagGPSLatitude        = 0x0002
+	tagGPSLongitudeRef    = 0x0003
+	tagGPSLongitude       = 0x0004
+)
+
+// maxIFDEntries protects against corrupted entry counts.
+const maxIFDEntries = 4096
+
+type tiffEntry struct {
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+type tiffReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+func parseTIFF(data []byte) (*EXIF, error) {
+	if !isTIFFHeader(data) {
+		return nil, fmt.Errorf("invalid TIFF header")
+	}
+	t := tiffReader{data: data, order: binary.LittleEndian}
+	if data[0] == 'M' {
+		t.order = binary.BigEndian
+	}
+
+	ifd0, err := t.readIFD(t.order.Uint32(data[4:]))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read IFD0: %w", err)
+	}
+
+	e := &EXIF{
+		Make:        t.ascii(ifd0[tagMake]),
+		Model:       t.ascii(ifd0[tagModel]),
+		Software:    t.ascii(ifd0[tagSoftware]),
+		DateTime:    t.ascii(ifd0[tagDateTime]),
+		Orientation: int(t.uint(ifd0[tagOrientation])),
+	}
+
+	if ptr, ok := ifd0[tagExifIFD]; ok {
+		sub, err := t.readIFD(uint32(t.uint(ptr)))
+		if err != nil {
+			return nil, fmt.Errorf("cannot read EXIF IFD: %w", err)
+		}
+		e.DateTimeOriginal = t.ascii(sub[tagDateTimeOriginal])
+		e.OffsetTimeOriginal = t.ascii(sub[tagOffsetTimeOriginal])
+		e.LensMake = t.ascii(sub[tagLensMake])
+		e.LensModel = t.ascii(sub[tagLensModel])
+	}
+
+	if ptr, ok := ifd0[tagGPSIFD]; ok {
+		gps, err := t.readIFD(uint32(t.uint(ptr)))
+		if err != nil {
+			return nil, fmt.Errorf("cannot read GPS IFD: %w", err)
+		}
+		lat, okLat := t.degrees(gps[tagGPSLatitude])
+		lon, okLon := t.degrees(gps[tagGPSLongitude])
+		if okLat && okLon {
+			if strings.EqualFold(t.ascii(gps[tagGPSLatitudeRef]), "S") {
+				lat = -lat
+			}
+			if strings.EqualFold(t.ascii(gps[tagGPSLongitudeRef]), "W") {
+				lon = -lon
+			}
+			e.HasGPS, e.Latitude, e.Longitude = true, lat, lon
+		}
+	}
+
+	return e, nil
+}
+
+func (t tiffReader) readIFD(offset uint32) (map[uint16]tiffEntry, error) {
+	if uint64(offset)+2 > uint64(len(t.data)) {
+		return nil, fmt.Errorf("IFD offset %d out of range", offset)
+	}
+	n := int(t.order.Uint16(t.data[offset:]))
+	if n > maxIFDEntries {
+		return nil, fmt.Errorf("too many IFD entries: %d", n)
+	}
+	start := int(offset) + 2
+	if start+n*12 > len(t.data) {
+		return nil, fmt.Errorf("IFD at offset %d is truncated", offset)
+	}
+
+	entries := make(map[uint16]tiffEntry, n)
+	for i := 0; i < n; i++ {
+		raw := t.data[start+i*12 : start+(i+1)*12]
+		typ := t.order.Uint16(raw[2:])
+		count := t.order.Uint32(raw[4:])
+		size := uint64(tiffTypeSize(typ)) * uint64(count)
+		if size == 0 {
+			continue
+		}
+		var value []byte
+		if size <= 4 {
+			value = raw[8 : 8+size]
+		} else {
+			off := uint64(t.order.Uint32(raw[8:]))
+			if off+size > uint64(len(t.data)) {
+				// Value lies outside of what has been read, ignore it
+				continue
+			}
+			value = t.data[off : off+size]
+		}
+		entries[t.order.Uint16(raw)] = tiffEntry{typ: typ, count: count, value: value}
+	}
+	return entries, nil
+}
+
+func tiffTypeSize(typ uint16) int {
+	switch typ {
+	case 1, 2, 6, 7: // BYTE, ASCII, SBYTE, UNDEFINED
+		return 1
+	case 3, 8: // SHORT, SSHORT
+		return 2
+	case 4, 9, 11: // LONG, SLONG, FLOAT
+		return 4
+	case 5, 10, 12: // RATIONAL, SRATIONAL, DOUBLE
+		return 8
+	default:
+		return 0
+	}
+}
+
+func (t tiffReader) ascii(e tiffEntry) string {
+	if e.typ != 2 && e.typ != 7 {
+		return ""
+	}
+	s := string(e.value)
+	if i := strings.IndexByte(s, 0); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+func (t tiffReader) uint(e tiffEntry) uint32 {
+	switch {
+	case e.typ == 3 && len(e.value) >= 2:
+		return uint32(t.order.Uint16(e.value))
+	case (e.typ == 4 || e.typ == 9) && len(e.value) >= 4:
+		return t.order.Uint32(e.value)
+	case e.typ == 1 && len(e.value) >= 1:
+		return uint32(e.value[0])
+	default:
+		return 0
+	}
+}
+
+// degrees converts a GPS coordinate stored as three rationals to decimal degrees.
+func (t tiffReader) degrees(e tiffEntry) (float64, bool) {
+	if e.typ != 5 || len(e.value) < 24 {
+		return 0, false
+	}
+	var parts [3]float64
+	for i := range parts {
+		num := t.order.Uint32(e.value[i*8:])
+		den := t.order.Uint32(e.value[i*8+4:])
+		if den == 0 {
+			if num != 0 {
+				return 0, false
+			}
+			continue
+		}
+		parts[i] = float64(num) / float64(den)
+	}
+	return parts[0] + parts[1]/60 + parts[2]/3600, true
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package metadata
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleTIFF() []byte {
+	return buildTIFF(
+		[]ifdEntry{
+			asciiEntry(tagMake, "Apple"),
+			asciiEntry(tagModel, "iPhone 13 Pro"),
+			shortEntry(tagOrientation, 6),
+		},
+		[]ifdEntry{
+			asciiEntry(tagDateTimeOriginal, "2023:04:01 10:15:00"),
+			asciiEntry(tagLensModel, "iPhone 13 Pro back camera"),
+		},
+		[]ifdEntry{
+			asciiEntry(tagGPSLatitudeRef, "N"),
+			rationalEntry(tagGPSLatitude, 48, 51, 0),
+			asciiEntry(tagGPSLongitudeRef, "W"),
+			rationalEntry(tagGPSLongitude, 2, 21, 0),
+		},
+	)
+}
+
+func assertSample(t *testing.T, e *EXIF) {
+	t.Helper()
+	assert.Equal(t, "Apple", e.Make)
+	assert.Equal(t, "iPhone 13 Pro", e.Model)
+	assert.Equal(t, "iPhone 13 Pro back camera", e.LensModel)
+	assert.Equal(t, 6, e.Orientation)
+	assert.True(t, e.HasGPS)
+	assert.InDelta(t, 48.85, e.Latitude, 0.001)
+	assert.InDelta(t, -2.35, e.Longitude, 0.001)
+
+	taken, ok := e.Taken(time.UTC)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2023, 4, 1, 10, 15, 0, 0, time.UTC), taken)
+}
+
+func TestParseEXIF_JPEG(t *testing.T) {
+	e, err := ParseEXIF(buildJPEG(sampleTIFF()))
+	require.NoError(t, err)
+	assertSample(t, e)
+}
+
+func TestParseEXIF_TIFF(t *testing.T) {
+	e, err := ParseEXIF(sampleTIFF())
+	require.NoError(t, err)
+	assertSample(t, e)
+}
+
+func sampleHEIC() []byte {
+	payload := append([]byte{0, 0, 0, 6}, "Exif\x00\x00"...)
+	payload = append(payload, sampleTIFF()...)
+
+	infe := bmffBox("infe", []byte{2, 0, 0, 0}, []byte{0, 1, 0, 0}, []byte("Exif"), []byte{0})
+	iinf := bmffBox("iinf", []byte{0, 0, 0, 0, 0, 1}, infe)
+
+	// iloc version 0, offset/length size 4, base offset size 0, one item with one extent
+	ilocPayload := func(offset uint32) []byte {
+		b := []byte{0, 0, 0, 0, 0x44, 0x00, 0, 1, 0, 1, 0, 0, 0, 1}
+		b = binary.BigEndian.AppendUint32(b, offset)
+		return binary.BigEndian.AppendUint32(b, uint32(len(payload)))
+	}
+	ftyp := bmffBox("ftyp", []byte("heic"), []byte{0, 0, 0, 0}, []byte("mif1heic"))
+	meta := bmffBox("meta", []byte{0, 0, 0, 0}, iinf, bmffBox("iloc", ilocPayload(0)))
+	offset := uint32(len(ftyp) + len(meta) + 8)
+	meta = bmffBox("meta", []byte{0, 0, 0, 0}, iinf, bmffBox("iloc", ilocPayload(offset)))
+
+	return append(append(ftyp, meta...), bmffBox("mdat", payload)...)
+}
+
+func TestParseEXIF_HEIC(t *testing.T) {
+	e, err := ParseEXIF(sampleHEIC())
+	require.NoError(t, err)
+	assertSample(t, e)
+}
+
+func TestParseEXIF_NoEXIF(t *testing.T) {
+	_, err := ParseEXIF([]byte("just some text"))
+	assert.ErrorIs(t, err, ErrNoEXIF)
+
+	// JPEG without APP1 segment
+	_, err = ParseEXIF([]byte{0xFF, 0xD8, 0xFF, 0xDA, 0, 2, 0xFF, 0xD9})
+	assert.ErrorIs(t, err, ErrNoEXIF)
+}
+
+func TestParseEXIF_Truncated(t *testing.T) {
+	jpeg := buildJPEG(sampleTIFF())
+	_, err := ParseEXIF(jpeg[:20])
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNoEXIF)
+}
+
+func TestParseEXIF_MalformedDoesNotPanic(t *testing.T) {
+	ftyp := bmffBox("ftyp", []byte("heic"), []byte{0, 0, 0, 0})
+	heic := func(children ...[]byte) []byte {
+		return append(append([]byte{}, ftyp...), bmffBox("meta", append([][]byte{{0, 0, 0, 0}}, children...)...)...)
+	}
+	exifIinf := bmffBox("iinf", []byte{0, 0, 0, 0, 0, 1},
+		bmffBox("infe", []byte{2, 0, 0, 0}, []byte{0, 1, 0, 0}, []byte("Exif"), []byte{0}))
+
+	tests := map[string][]byte{
+		"jpeg segment longer than data": {0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x10},
+		"jpeg short exif header":        {0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x10, 'E', 'x'},
+		"iinf version 1 too short":      heic(bmffBox("iinf", []byte{1, 0, 0, 0, 0, 1})),
+		"iloc truncated item":           heic(exifIinf, bmffBox("iloc", []byte{1, 0, 0, 0, 0x44, 0x04, 0, 1, 0, 1})),
+		"iloc truncated extent":         heic(exifIinf, bmffBox("iloc", []byte{1, 0, 0, 0, 0x44, 0x04, 0, 1, 0, 1, 0, 0, 0, 0, 0, 1})),
+		"iloc offset overflow": heic(exifIinf, bmffBox("iloc", []byte{
+			0, 0, 0, 0, 0x88, 0x00, 0, 1, 0, 1, 0, 0, 0, 1,
+			0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xF0,
+			0, 0, 0, 0, 0, 0, 0, 0x20,
+		})),
+		"box size overflow": append(append([]byte{}, ftyp...),
+			0, 0, 0, 1, 'm', 'e', 't', 'a', 0x7F, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			var err error
+			require.NotPanics(t, func() { _, err = ParseEXIF(data) })
+			assert.Error(t, err)
+		})
+	}
+}
+
+func FuzzParseEXIF(f *testing.F) {
+	f.Add(buildJPEG(sampleTIFF()))
+	f.Add(sampleTIFF())
+	f.Add(sampleHEIC())
+	f.Add([]byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x10})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _ = ParseEXIF(data)
+	})
+}
+
+func TestEXIF_TakenWithOffset(t *testing.T) {
+	e := &EXIF{DateTimeOriginal: "2023:04:01 10:15:00", OffsetTimeOriginal: "+02:00"}
+	taken, ok := e.Taken(time.UTC)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2023, 4, 1, 8, 15, 0, 0, time.UTC), taken.UTC())
+}
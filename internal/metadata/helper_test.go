@@ -0,0 +1,138 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package metadata
+
+import (
+	"encoding/binary"
+)
+
+// ifdEntry is a TIFF directory entry used to build test fixtures.
+type ifdEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+func asciiEntry(tag uint16, s string) ifdEntry {
+	v := append([]byte(s), 0)
+	return ifdEntry{tag: tag, typ: 2, count: uint32(len(v)), value: v}
+}
+
+func shortEntry(tag, v uint16) ifdEntry {
+	b := make([]byte, 2)
+	binary.LittleEndian.PutUint16(b, v)
+	return ifdEntry{tag: tag, typ: 3, count: 1, value: b}
+}
+
+func longEntry(tag uint16, v uint32) ifdEntry {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return ifdEntry{tag: tag, typ: 4, count: 1, value: b}
+}
+
+func rationalEntry(tag uint16, vals ...uint32) ifdEntry {
+	b := make([]byte, 0, len(vals)*8)
+	for _, v := range vals {
+		b = binary.LittleEndian.AppendUint32(b, v)
+		b = binary.LittleEndian.AppendUint32(b, 1)
+	}
+	return ifdEntry{tag: tag, typ: 5, count: uint32(len(vals)), value: b}
+}
+
+func ifdSize(entries []ifdEntry) int {
+	size := 2 + 12*len(entries) + 4
+	for _, e := range entries {
+		if len(e.value) > 4 {
+			size += len(e.value)
+		}
+	}
+	return size
+}
+
+func appendIFD(buf []byte, entries []ifdEntry) []byte {
+	start := len(buf)
+	data := start + 2 + 12*len(entries) + 4
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(len(entries)))
+	var blobs []byte
+	for _, e := range entries {
+		buf = binary.LittleEndian.AppendUint16(buf, e.tag)
+		buf = binary.LittleEndian.AppendUint16(buf, e.typ)
+		buf = binary.LittleEndian.AppendUint32(buf, e.count)
+		if len(e.value) <= 4 {
+			v := make([]byte, 4)
+			copy(v, e.value)
+			buf = append(buf, v...)
+			continue
+		}
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(data+len(blobs)))
+		blobs = append(blobs, e.value...)
+	}
+	buf = binary.LittleEndian.AppendUint32(buf, 0)
+	return append(buf, blobs...)
+}
+
+// buildTIFF builds a little-endian TIFF structure with optional EXIF and GPS sub-IFDs.
+func buildTIFF(ifd0, exif, gps []ifdEntry) []byte {
+	if len(exif) > 0 {
+		ifd0 = append(ifd0, longEntry(tagExifIFD, 0))
+	}
+	if len(gps) > 0 {
+		ifd0 = append(ifd0, longEntry(tagGPSIFD, 0))
+	}
+	exifOff := 8 + ifdSize(ifd0)
+	gpsOff := exifOff + ifdSize(exif)
+	for i := range ifd0 {
+		switch ifd0[i].tag {
+		case tagExifIFD:
+			ifd0[i] = longEntry(tagExifIFD, uint32(exifOff))
+		case tagGPSIFD:
+			ifd0[i] = longEntry(tagGPSIFD, uint32(gpsOff))
+		}
+	}
+
+	buf := []byte("II*\x00")
+	buf = binary.LittleEndian.AppendUint32(buf, 8)
+	buf = appendIFD(buf, ifd0)
+	if len(exif) > 0 {
+		buf = appendIFD(buf, exif)
+	}
+	if len(gps) > 0 {
+		buf = appendIFD(buf, gps)
+	}
+	return buf
+}
+
+// buildJPEG wraps a TIFF structure into a minimal JPEG APP1 segment.
+func buildJPEG(tiff []byte) []byte {
+	buf := []byte{0xFF, 0xD8, 0xFF, 0xE1}
+	buf = binary.BigEndian.AppendUint16(buf, uint16(2+6+len(tiff)))
+	buf = append(buf, "Exif\x00\x00"...)
+	buf = append(buf, tiff...)
+	return append(buf, 0xFF, 0xD9)
+}
+
+// bmffBox serializes an ISO BMFF box.
+func bmffBox(typ string, payload ...[]byte) []byte {
+	size := 8
+	for _, p := range payload {
+		size += len(p)
+	}
+	buf := binary.BigEndian.AppendUint32(nil, uint32(size))
+	buf = append(buf, typ...)
+	for _, p := range payload {
+		buf = append(buf, p...)
+	}
+	return buf
+}
@@ -0,0 +1,446 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// mpegSyncWindow is how far after the ID3v2 tag the first MPEG frame is looked for.
+const mpegSyncWindow = 64 << 10
+
+// id3v1Size is the size of the ID3v1 tag stored at the end of MP3 files.
+const id3v1Size = 128
+
+func readID3File(s *stream, size int64) (*AudioTags, error) {
+	tags := &AudioTags{Format: FormatMP3}
+
+	header, err := s.readFull(10)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read ID3v2 header: %w", err)
+	}
+	major, flags := header[3], header[5]
+	tagSize := int64(syncsafe(header[6:10]))
+	body, err := s.readFull(tagSize)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read ID3v2 tag: %w", err)
+	}
+	if flags&0x10 != 0 { // footer
+		if err := s.skip(10); err != nil {
+			return nil, err
+		}
+	}
+
+	var tlen time.Duration
+	if major >= 2 && major <= 4 {
+		tlen = parseID3v2(body, major, flags, tags)
+	}
+
+	// A FLAC stream may be prefixed by an ID3v2 tag
+	if next, err := s.peek(4); err == nil && bytes.Equal(next, []byte("fLaC")) {
+		return readFLAC(s)
+	}
+
+	if _, err := readMP3(s, size, tags); err != nil {
+		return nil, err
+	}
+	if tags.Duration == 0 {
+		tags.Duration = tlen
+	}
+	return tags, nil
+}
+
+// parseID3v2 fills tags from an ID3v2 tag body and returns the TLEN duration if any.
+func parseID3v2(body []byte, major, flags byte, tags *AudioTags) time.Duration {
+	if flags&0x80 != 0 && major < 4 {
+		body = removeUnsync(body)
+	}
+	pos := 0
+	if flags&0x40 != 0 && len(body) >= 4 { // extended header
+		if major == 4 {
+			pos = int(syncsafe(body[:4]))
+		} else {
+			pos = 4 + int(binary.BigEndian.Uint32(body))
+		}
+	}
+
+	idLen, headerLen := 4, 10
+	if major == 2 {
+		idLen, headerLen = 3, 6
+	}
+
+	var tlen time.Duration
+	for pos+headerLen <= len(body) && body[pos] != 0 {
+		id := string(body[pos : pos+idLen])
+		var size int
+		var formatFlags byte
+		switch major {
+		case 2:
+			size = int(body[pos+3])<<16 | int(body[pos+4])<<8 | int(body[pos+5])
+		case 3:
+			size = int(binary.BigEndian.Uint32(body[pos+4:]))
+			formatFlags = body[pos+9]
+		default:
+			size = int(syncsafe(body[pos+4 : pos+8]))
+			formatFlags = body[pos+9]
+		}
+		pos += headerLen
+		if size < 0 || pos+size > len(body) {
+			break
+		}
+		frame := body[pos : pos+size]
+		pos += size
+
+		frame, ok := decodeID3Frame(frame, major, formatFlags)
+		if !ok {
+			continue
+		}
+		if id == "TLEN" || id == "TLE" {
+			if ms, err := strconv.Atoi(id3Text(frame)); err == nil {
+				tlen = time.Duration(ms) * time.Millisecond
+			}
+			continue
+		}
+		setID3Field(tags, id, frame)
+	}
+	return tlen
+}
+
+// decodeID3Frame removes frame level encodings. It returns false for
+// compressed or encrypted frames, which are not supported.
+func decodeID3Frame(frame []byte, major, flags byte) ([]byte, bool) {
+	switch major {
+	case 3:
+		if flags&0xC0 != 0 {
+			return nil, false
+		}
+		if flags&0x20 != 0 && len(frame) > 0 { // grouping identity
+			frame = frame[1:]
+		}
+	case 4:
+		if flags&0x0C != 0 {
+			return nil, false
+		}
+		if flags&0x40 != 0 && len(frame) > 0 { // grouping identity
+			frame = frame[1:]
+		}
+		if flags&0x01 != 0 && len(frame) >= 4 { // data length indicator
+			frame = frame[4:]
+		}
+		if flags&0x02 != 0 {
+			frame = removeUnsync(frame)
+		}
+	}
+	return frame, true
+}
+
+func setID3Field(tags *AudioTags, id string, frame []byte) {
+	switch id {
+	case "TIT2", "TT2":
+		tags.Title = id3Text(frame)
+	case "TPE1", "TP1":
+		tags.Artist = id3Text(frame)
+	case "TPE2", "TP2":
+		tags.AlbumArtist = id3Text(frame)
+	case "TALB", "TAL":
+		tags.Album = id3Text(frame)
+	case "TCON", "TCO":
+		tags.Genre = parseID3Genre(id3Text(frame))
+	case "TYER", "TYE", "TDRC", "TDOR":
+		if tags.Year == 0 {
+			tags.Year = parseYear(id3Text(frame))
+		}
+	case "TRCK", "TRK":
+		tags.Track, tags.TrackTotal = parseNumberPair(id3Text(frame), tags.TrackTotal)
+	case "TPOS", "TPA":
+		tags.Disc, tags.DiscTotal = parseNumberPair(id3Text(frame), tags.DiscTotal)
+	case "TCMP", "TCP":
+		tags.Compilation = id3Text(frame) == "1"
+	}
+}
+
+// id3Text decodes a text frame. Multiple values are joined with "; ".
+func id3Text(frame []byte) string {
+	if len(frame) == 0 {
+		return ""
+	}
+	var text string
+	data := frame[1:]
+	switch frame[0] {
+	case 0: // ISO-8859-1
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		text = string(runes)
+	case 1: // UTF-16 with BOM
+		text = decodeUTF16(data, true)
+	case 2: // UTF-16BE
+		text = decodeUTF16(data, false)
+	default: // UTF-8
+		text = string(data)
+	}
+
+	var values []string
+	for _, v := range strings.Split(text, "\x00") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return strings.Join(values, "; ")
+}
+
+func decodeUTF16(b []byte, withBOM bool) string {
+	var order binary.ByteOrder = binary.BigEndian
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		if withBOM {
+			switch {
+			case b[i] == 0xFF && b[i+1] == 0xFE:
+				order = binary.LittleEndian
+				continue
+			case b[i] == 0xFE && b[i+1] == 0xFF:
+				order = binary.BigEndian
+				continue
+			}
+		}
+		units = append(units, order.Uint16(b[i:]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// parseID3Genre resolves numeric references such as "(13)", "13" or "(13)Pop".
+func parseID3Genre(s string) string {
+	if strings.HasPrefix(s, "(") {
+		if end := strings.IndexByte(s, ')'); end > 0 {
+			if rest := strings.TrimSpace(s[end+1:]); rest != "" {
+				return rest
+			}
+			s = s[1:end]
+		}
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return id3v1Genre(n)
+	}
+	return s
+}
+
+func syncsafe(b []byte) uint32 {
+	return uint32(b[0]&0x7F)<<21 | uint32(b[1]&0x7F)<<14 | uint32(b[2]&0x7F)<<7 | uint32(b[3]&0x7F)
+}
+
+// removeUnsync reverts the ID3 unsynchronisation scheme (0xFF 0x00 -> 0xFF).
+func removeUnsync(b []byte) []byte {
+	return bytes.ReplaceAll(b, []byte{0xFF, 0x00}, []byte{0xFF})
+}
+
+// --- ID3v1 ---
+
+func parseID3v1(b []byte, tags *AudioTags) bool {
+	if len(b) != id3v1Size || string(b[:3]) != "TAG" {
+		return false
+	}
+	field := func(f []byte) string {
+		if i := bytes.IndexByte(f, 0); i >= 0 {
+			f = f[:i]
+		}
+		return id3Text(append([]byte{0}, f...))
+	}
+	tags.Title = firstNonEmpty(tags.Title, field(b[3:33]))
+	tags.Artist = firstNonEmpty(tags.Artist, field(b[33:63]))
+	tags.Album = firstNonEmpty(tags.Album, field(b[63:93]))
+	if tags.Year == 0 {
+		tags.Year = parseYear(field(b[93:97]))
+	}
+	if b[125] == 0 && b[126] != 0 && tags.Track == 0 { // ID3v1.1
+		tags.Track = int(b[126])
+	}
+	if tags.Genre == "" && b[127] != 0xFF {
+		tags.Genre = id3v1Genre(int(b[127]))
+	}
+	return true
+}
+
+var id3v1Genres = []string{
+	"Blues", "Classic Rock", "Country", "Dance", "Disco", "Funk", "Grunge", "Hip-Hop",
+	"Jazz", "Metal", "New Age", "Oldies", "Other", "Pop", "R&B", "Rap",
+	"Reggae", "Rock", "Techno", "Industrial", "Alternative", "Ska", "Death Metal", "Pranks",
+	"Soundtrack", "Euro-Techno", "Ambient", "Trip-Hop", "Vocal", "Jazz+Funk", "Fusion", "Trance",
+	"Classical", "Instrumental", "Acid", "House", "Game", "Sound Clip", "Gospel", "Noise",
+	"AlternRock", "Bass", "Soul", "Punk", "Space", "Meditative", "Instrumental Pop", "Instrumental Rock",
+	"Ethnic", "Gothic", "Darkwave", "Techno-Industrial", "Electronic", "Pop-Folk", "Eurodance", "Dream",
+	"Southern Rock", "Comedy", "Cult", "Gangsta", "Top 40", "Christian Rap", "Pop/Funk", "Jungle",
+	"Native American", "Cabaret", "New Wave", "Psychadelic", "Rave", "Showtunes", "Trailer", "Lo-Fi",
+	"Tribal", "Acid Punk", "Acid Jazz", "Polka", "Retro", "Musical", "Rock & Roll", "Hard Rock",
+	// Winamp extensions
+	"Folk", "Folk-Rock", "National Folk", "Swing", "Fast Fusion", "Bebop", "Latin", "Revival",
+	"Celtic", "Bluegrass", "Avantgarde", "Gothic Rock", "Progressive Rock", "Psychedelic Rock", "Symphonic Rock", "Slow Rock",
+	"Big Band", "Chorus", "Easy Listening", "Acoustic", "Humour", "Speech", "Chanson", "Opera",
+	"Chamber Music", "Sonata", "Symphony", "Booty Bass", "Primus", "Porn Groove", "Satire", "Slow Jam",
+	"Club", "Tango", "Samba", "Folklore", "Ballad", "Power Ballad", "Rhythmic Soul", "Freestyle",
+	"Duet", "Punk Rock", "Drum Solo", "A Cappella", "Euro-House", "Dance Hall", "Goa", "Drum & Bass",
+	"Club-House", "Hardcore", "Terror", "Indie", "BritPop", "Afro-Punk", "Polsk Punk", "Beat",
+	"Christian Gangsta Rap", "Heavy Metal", "Black Metal", "Crossover", "Contemporary Christian", "Christian Rock", "Merengue", "Salsa",
+	"Thrash Metal", "Anime", "JPop", "Synthpop", "Abstract", "Art Rock", "Baroque", "Bhangra",
+	"Big Beat", "Breakbeat", "Chillout", "Downtempo", "Dub", "EBM", "Eclectic", "Electro",
+	"Electroclash", "Emo", "Experimental", "Garage", "Global", "IDM", "Illbient", "Industro-Goth",
+	"Jam Band", "Krautrock", "Leftfield", "Lounge", "Math Rock", "New Romantic", "Nu-Breakz", "Post-Punk",
+	"Post-Rock", "Psytrance", "Shoegaze", "Space Rock", "Trop Rock", "World Music", "Neoclassical", "Audiobook",
+	"Audio Theatre", "Neue Deutsche Welle", "Podcast", "Indie Rock", "G-Funk", "Dubstep", "Garage Rock", "Psybient",
+}
+
+func id3v1Genre(n int) string {
+	if n < 0 || n >= len(id3v1Genres) {
+		return ""
+	}
+	return id3v1Genres[n]
+}
+
+// --- MPEG audio frames ---
+
+var mpegBitrates = [2][3][16]int{
+	{ // MPEG 1: layer I, II, III
+		{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448, 0},
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384, 0},
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+	},
+	{ // MPEG 2 and 2.5: layer I, II, III
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256, 0},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+	},
+}
+
+var mpegSampleRates = map[byte][3]int{
+	3: {44100, 48000, 32000}, // MPEG 1
+	2: {22050, 24000, 16000}, // MPEG 2
+	0: {11025, 12000, 8000},  // MPEG 2.5
+}
+
+type mpegFrame struct {
+	version    byte // 3: MPEG 1, 2: MPEG 2, 0: MPEG 2.5
+	layer      int  // 1, 2 or 3
+	bitrate    int  // kbps
+	sampleRate int
+	mono       bool
+}
+
+func parseMPEGFrame(h []byte) (mpegFrame, bool) {
+	if len(h) < 4 || h[0] != 0xFF || h[1]&0xE0 != 0xE0 {
+		return mpegFrame{}, false
+	}
+	version := (h[1] >> 3) & 0x03
+	layerBits := (h[1] >> 1) & 0x03
+	bitrateIdx := h[2] >> 4
+	rateIdx := (h[2] >> 2) & 0x03
+	if version == 1 || layerBits == 0 || bitrateIdx == 0 || bitrateIdx == 15 || rateIdx == 3 {
+		return mpegFrame{}, false
+	}
+
+	f := mpegFrame{
+		version:    version,
+		layer:      int(4 - layerBits),
+		sampleRate: mpegSampleRates[version][rateIdx],
+		mono:       h[3]>>6 == 3,
+	}
+	table := 0
+	if version != 3 {
+		table = 1
+	}
+	f.bitrate = mpegBitrates[table][f.layer-1][bitrateIdx]
+	return f, true
+}
+
+func isMPEGFrame(h []byte) bool {
+	_, ok := parseMPEGFrame(h)
+	return ok
+}
+
+func (f mpegFrame) samplesPerFrame() int {
+	switch {
+	case f.layer == 1:
+		return 384
+	case f.layer == 3 && f.version != 3:
+		return 576
+	default:
+		return 1152
+	}
+}
+
+// xingOffset is the offset of the Xing/Info header from the start of the frame.
+func (f mpegFrame) xingOffset() int {
+	switch {
+	case f.version == 3 && !f.mono:
+		return 4 + 32
+	case f.version == 3 || !f.mono:
+		return 4 + 17
+	default:
+		return 4 + 9
+	}
+}
+
+// readMP3 finds the first MPEG frame to compute the duration, then reads the ID3v1 tag.
+func readMP3(s *stream, size int64, tags *AudioTags) (*AudioTags, error) {
+	window, err := s.peek(mpegSyncWindow)
+	if err != nil {
+		return nil, err
+	}
+	audioStart := s.pos
+
+	for i := 0; i+4 <= len(window); i++ {
+		frame, ok := parseMPEGFrame(window[i:])
+		if !ok {
+			continue
+		}
+		tags.Duration = mp3Duration(window[i:], frame, size-audioStart-int64(i))
+		break
+	}
+
+	if size >= id3v1Size && (tags.Title == "" || tags.Artist == "") {
+		if tail, err := s.tail(size, id3v1Size); err == nil {
+			parseID3v1(tail, tags)
+		}
+	}
+	return tags, nil
+}
+
+// mp3Duration uses the Xing/Info or VBRI header when present and falls back to a
+// constant bitrate estimation over the audio data size.
+func mp3Duration(data []byte, f mpegFrame, audioSize int64) time.Duration {
+	spf := time.Duration(f.samplesPerFrame())
+	rate := time.Duration(f.sampleRate)
+
+	if off := f.xingOffset(); off+12 <= len(data) {
+		tag := string(data[off : off+4])
+		if (tag == "Xing" || tag == "Info") && binary.BigEndian.Uint32(data[off+4:])&0x01 != 0 {
+			frames := time.Duration(binary.BigEndian.Uint32(data[off+8:]))
+			return frames * spf * time.Second / rate
+		}
+	}
+	if off := 4 + 32; off+18 <= len(data) && string(data[off:off+4]) == "VBRI" {
+		frames := time.Duration(binary.BigEndian.Uint32(data[off+14:]))
+		return frames * spf * time.Second / rate
+	}
+	if f.bitrate > 0 && audioSize > 0 {
+		seconds := float64(audioSize) * 8 / float64(f.bitrate*1000)
+		return time.Duration(seconds * float64(time.Second))
+	}
+	return 0
+}
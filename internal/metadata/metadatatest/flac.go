@@ -0,0 +1,38 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+// Package metadatatest builds minimal media files for the tests of the
+// packages reading metadata, such as the audio_tags filter and the music strategy.
+package metadatatest
+
+import "encoding/binary"
+
+// FLAC builds a minimal FLAC file lasting 10 seconds, with the given Vorbis
+// comments such as "ARTIST=Daft Punk".
+func FLAC(comments ...string) []byte {
+	streamInfo := make([]byte, 34)
+	streamInfo[10], streamInfo[11], streamInfo[12] = 0x0A, 0xC4, 0x40 // 44100 Hz
+	binary.BigEndian.PutUint32(streamInfo[14:], 441000)
+
+	vorbis := binary.LittleEndian.AppendUint32(nil, 0)
+	vorbis = binary.LittleEndian.AppendUint32(vorbis, uint32(len(comments)))
+	for _, c := range comments {
+		vorbis = binary.LittleEndian.AppendUint32(vorbis, uint32(len(c)))
+		vorbis = append(vorbis, c...)
+	}
+
+	file := []byte("fLaC\x00\x00\x00\x22")
+	file = append(file, streamInfo...)
+	file = append(file, 0x84, 0, byte(len(vorbis)>>8), byte(len(vorbis)))
+	return append(file, vorbis...)
+}
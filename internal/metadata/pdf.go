@@ -0,0 +1,526 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package metadata
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	// pdfTailSize is the amount of data searched for the last startxref keyword.
+	pdfTailSize = 4096
+	// pdfSectionSize is the amount of data read at an xref section or an object.
+	pdfSectionSize = 16 << 10
+	// pdfScanChunk is the size of the chunks read when searching the whole file.
+	pdfScanChunk = 64 << 10
+	// maxXMPSize bounds the size of an XMP packet.
+	maxXMPSize = 256 << 10
+)
+
+var (
+	pdfStartXref = regexp.MustCompile(`startxref\s+(\d+)`)
+	pdfInfoRef   = regexp.MustCompile(`/Info\s+(\d+)\s+(\d+)\s+R`)
+	pdfXrefSub   = regexp.MustCompile(`^(\d+)\s+(\d+)\s*$`)
+)
+
+// readPDFInfo reads the document information dictionary referenced by the
+// last trailer, then completes missing fields from the XMP metadata packet
+// when it is stored uncompressed.
+func readPDFInfo(r io.ReaderAt, size int64) (*DocumentInfo, error) {
+	info := &DocumentInfo{Format: FormatPDF}
+
+	tail, err := readSection(r, max(size-pdfTailSize, 0), pdfTailSize)
+	if err != nil {
+		return nil, err
+	}
+	matches := pdfStartXref.FindAllSubmatch(tail, -1)
+	if len(matches) > 0 {
+		offset, _ := strconv.ParseInt(string(matches[len(matches)-1][1]), 10, 64)
+		if err := readPDFInfoDict(r, size, offset, tail, info); err != nil {
+			return nil, err
+		}
+	}
+
+	if info.Title == "" || info.Author == "" || info.Created.IsZero() {
+		if err := readPDFXMP(r, size, info); err != nil {
+			return nil, err
+		}
+	}
+	trimDocumentInfo(info)
+	return info, nil
+}
+
+// readPDFInfoDict locates the Info dictionary from the trailer (or the xref
+// stream dictionary) at offset and decodes its string entries.
+func readPDFInfoDict(r io.ReaderAt, size, offset int64, tail []byte, info *DocumentInfo) error {
+	if offset <= 0 || offset >= size {
+		return nil
+	}
+	section, err := readSection(r, offset, pdfSectionSize)
+	if err != nil {
+		return err
+	}
+
+	// Encrypted documents have encrypted strings
+	trailer := section
+	if i := bytes.Index(section, []byte("trailer")); i >= 0 {
+		trailer = section[i:]
+	}
+	if bytes.Contains(trailer, []byte("/Encrypt")) || bytes.Contains(tail, []byte("/Encrypt")) {
+		return nil
+	}
+
+	ref := pdfInfoRef.FindSubmatch(trailer)
+	if ref == nil {
+		ref = pdfInfoRef.FindSubmatch(tail)
+	}
+	if ref == nil {
+		return nil
+	}
+	number, _ := strconv.Atoi(string(ref[1]))
+	generation, _ := strconv.Atoi(string(ref[2]))
+
+	objOffset := int64(-1)
+	if bytes.HasPrefix(bytes.TrimLeft(section, " \t\r\n"), []byte("xref")) {
+		objOffset = xrefTableOffset(section, number)
+	}
+	if objOffset <= 0 {
+		// Cross-reference streams are compressed: search the object instead
+		if objOffset, err = findPDFObject(r, size, number, generation); err != nil || objOffset < 0 {
+			return err
+		}
+	}
+
+	obj, err := readSection(r, objOffset, pdfSectionSize)
+	if err != nil {
+		return err
+	}
+	start := bytes.Index(obj, []byte("<<"))
+	if start < 0 {
+		return nil
+	}
+	dict := parsePDFDict(obj[start+2:])
+	info.Title = dict["Title"]
+	info.Subject = dict["Subject"]
+	info.Author = dict["Author"]
+	info.Keywords = dict["Keywords"]
+	info.Company = dict["Company"]
+	info.Created = parsePDFDate(dict["CreationDate"])
+	info.Modified = parsePDFDate(dict["ModDate"])
+	return nil
+}
+
+// xrefTableOffset returns the offset of an object from a classic
+// cross-reference table, or -1 when it is not listed in this section.
+func xrefTableOffset(section []byte, number int) int64 {
+	lines := strings.FieldsFunc(string(section), func(r rune) bool { return r == '\n' || r == '\r' })
+	for i := 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(line, "trailer") {
+			break
+		}
+		sub := pdfXrefSub.FindStringSubmatch(line)
+		if sub == nil {
+			continue
+		}
+		first, _ := strconv.Atoi(sub[1])
+		count, _ := strconv.Atoi(sub[2])
+		if number < first || number >= first+count {
+			i += count
+			continue
+		}
+		if entry := i + 1 + number - first; entry < len(lines) {
+			fields := strings.Fields(lines[entry])
+			if len(fields) == 3 && fields[2] == "n" {
+				offset, _ := strconv.ParseInt(fields[0], 10, 64)
+				return offset
+			}
+		}
+		return -1
+	}
+	return -1
+}
+
+// findPDFObject searches the file for the last definition of an object.
+func findPDFObject(r io.ReaderAt, size int64, number, generation int) (int64, error) {
+	needle := []byte(fmt.Sprintf("%d %d obj", number, generation))
+	found := int64(-1)
+	err := scanPDF(r, size, func(chunk []byte, base int64) bool {
+		for i := 0; ; {
+			j := bytes.Index(chunk[i:], needle)
+			if j < 0 {
+				return true
+			}
+			pos := i + j
+			// "12 0 obj" must not match "112 0 obj"
+			if pos == 0 || isPDFSpace(chunk[pos-1]) {
+				found = base + int64(pos)
+			}
+			i = pos + len(needle)
+		}
+	})
+	return found, err
+}
+
+// scanPDF passes the file to fn in overlapping chunks, with the offset of
+// each chunk, until fn returns false.
+func scanPDF(r io.ReaderAt, size int64, fn func(chunk []byte, base int64) bool) error {
+	const overlap = 64
+	buf := make([]byte, pdfScanChunk)
+	for base := int64(0); base < size; base += pdfScanChunk - overlap {
+		n, err := r.ReadAt(buf, base)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if !fn(buf[:n], base) || int64(n) < pdfScanChunk {
+			return nil
+		}
+	}
+	return nil
+}
+
+// readPDFXMP searches an uncompressed XMP packet and fills the empty fields of info.
+func readPDFXMP(r io.ReaderAt, size int64, info *DocumentInfo) error {
+	start := int64(-1)
+	err := scanPDF(r, size, func(chunk []byte, base int64) bool {
+		if i := bytes.Index(chunk, []byte("<x:xmpmeta")); i >= 0 {
+			start = base + int64(i)
+			return false
+		}
+		return true
+	})
+	if err != nil || start < 0 {
+		return err
+	}
+
+	packet, err := readSection(r, start, maxXMPSize)
+	if err != nil {
+		return err
+	}
+	end := bytes.Index(packet, []byte("</x:xmpmeta>"))
+	if end < 0 {
+		return nil
+	}
+	xmp := parseXMP(packet[:end+len("</x:xmpmeta>")])
+
+	fill := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+	fill(&info.Title, xmp["title"])
+	fill(&info.Subject, xmp["description"])
+	fill(&info.Author, xmp["creator"])
+	fill(&info.Keywords, xmp["Keywords"])
+	if info.Created.IsZero() {
+		info.Created = parseDocumentDate(xmp["CreateDate"])
+	}
+	if info.Modified.IsZero() {
+		info.Modified = parseDocumentDate(xmp["ModifyDate"])
+	}
+	return nil
+}
+
+// parseXMP returns the text of the XMP properties by local name. Properties
+// are element content (rdf:Alt and rdf:Seq lists are joined) or attributes of rdf:Description.
+func parseXMP(data []byte) map[string]string {
+	values := make(map[string]string)
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+
+	var property string
+	var items []string
+	var text strings.Builder
+	depth := 0
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return values
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if t.Name.Local == "Description" {
+				for _, attr := range t.Attr {
+					if attr.Name.Space != "xmlns" && attr.Name.Local != "about" {
+						values[attr.Name.Local] = attr.Value
+					}
+				}
+				continue
+			}
+			switch t.Name.Local {
+			case "Alt", "Seq", "Bag", "li":
+			default:
+				property, items = t.Name.Local, nil
+			}
+			text.Reset()
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			depth--
+			switch t.Name.Local {
+			case "li":
+				items = append(items, strings.TrimSpace(text.String()))
+			case property:
+				if len(items) > 0 {
+					values[property] = strings.Join(items, ", ")
+				} else if value := strings.TrimSpace(text.String()); value != "" {
+					values[property] = value
+				}
+				property = ""
+			}
+			text.Reset()
+		}
+	}
+}
+
+// parsePDFDict decodes the name and string entries of a dictionary body.
+// Nested dictionaries, arrays and references are skipped.
+func parsePDFDict(data []byte) map[string]string {
+	values := make(map[string]string)
+	var key string
+	for i := 0; i < len(data); {
+		switch c := data[i]; {
+		case isPDFSpace(c):
+			i++
+		case c == '>' && i+1 < len(data) && data[i+1] == '>':
+			return values
+		case c == '/':
+			j := i + 1
+			for j < len(data) && !isPDFSpace(data[j]) && !strings.ContainsRune("/<>[]()", rune(data[j])) {
+				j++
+			}
+			if key == "" {
+				key = string(data[i+1 : j])
+			} else {
+				key = "" // name value
+			}
+			i = j
+		case c == '(':
+			s, n := readPDFLiteral(data[i:])
+			if key != "" {
+				values[key] = decodePDFText(s)
+			}
+			key, i = "", i+n
+		case c == '<' && i+1 < len(data) && data[i+1] == '<':
+			// Nested dictionary: skip it
+			depth := 0
+			for ; i+1 < len(data); i++ {
+				if data[i] == '<' && data[i+1] == '<' {
+					depth++
+					i++
+				} else if data[i] == '>' && data[i+1] == '>' {
+					depth--
+					i++
+					if depth == 0 {
+						i++
+						break
+					}
+				}
+			}
+			key = ""
+		case c == '<':
+			end := bytes.IndexByte(data[i:], '>')
+			if end < 0 {
+				return values
+			}
+			if key != "" {
+				values[key] = decodePDFText(decodePDFHex(data[i+1 : i+end]))
+			}
+			key, i = "", i+end+1
+		case c == '[':
+			// Arrays are not text values: skip them
+			depth := 0
+			for ; i < len(data); i++ {
+				if data[i] == '[' {
+					depth++
+				} else if data[i] == ']' {
+					depth--
+					if depth == 0 {
+						i++
+						break
+					}
+				}
+			}
+			key = ""
+		default:
+			// Numbers, booleans and references are not text values
+			j := i + 1
+			for j < len(data) && !isPDFSpace(data[j]) && !strings.ContainsRune("/<>[]()", rune(data[j])) {
+				j++
+			}
+			key, i = "", j
+		}
+	}
+	return values
+}
+
+// readPDFLiteral decodes a literal string starting at '(' and returns the
+// number of bytes consumed.
+func readPDFLiteral(data []byte) ([]byte, int) {
+	var out []byte
+	depth := 0
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '\\' && i+1 < len(data):
+			i++
+			switch e := data[i]; e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r', '\n':
+				// Line continuation
+				if e == '\r' && i+1 < len(data) && data[i+1] == '\n' {
+					i++
+				}
+			default:
+				if e >= '0' && e <= '7' {
+					v := 0
+					for k := 0; k < 3 && i < len(data) && data[i] >= '0' && data[i] <= '7'; k++ {
+						v = v*8 + int(data[i]-'0')
+						i++
+					}
+					i--
+					out = append(out, byte(v))
+				} else {
+					out = append(out, e)
+				}
+			}
+		case c == '(':
+			if depth > 0 {
+				out = append(out, c)
+			}
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return out, i + 1
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out, len(data)
+}
+
+func decodePDFHex(data []byte) []byte {
+	var digits []byte
+	for _, c := range data {
+		if !isPDFSpace(c) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, 0, len(digits)/2)
+	for i := 0; i+1 < len(digits); i += 2 {
+		v, err := strconv.ParseUint(string(digits[i:i+2]), 16, 8)
+		if err != nil {
+			return out
+		}
+		out = append(out, byte(v))
+	}
+	return out
+}
+
+// decodePDFText decodes UTF-16BE text strings (with a byte order mark) and
+// PDFDocEncoding, read as Latin-1.
+func decodePDFText(s []byte) string {
+	if len(s) >= 2 && s[0] == 0xFE && s[1] == 0xFF {
+		units := make([]uint16, 0, len(s)/2)
+		for i := 2; i+1 < len(s); i += 2 {
+			units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+	if len(s) >= 3 && s[0] == 0xEF && s[1] == 0xBB && s[2] == 0xBF {
+		return string(s[3:]) // UTF-8, allowed since PDF 2.0
+	}
+	runes := make([]rune, len(s))
+	for i, c := range s {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// parsePDFDate parses dates such as "D:20240102153000+01'00'".
+func parsePDFDate(s string) time.Time {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "D:")
+	if len(s) < 4 {
+		return time.Time{}
+	}
+
+	digits := s
+	zone := ""
+	if i := strings.IndexAny(s, "Zz+-"); i >= 0 {
+		digits, zone = s[:i], s[i:]
+	}
+	// Missing fields default to January 1st, midnight
+	layout := "20060102150405"
+	if len(digits) > len(layout) || len(digits)%2 == 1 {
+		return time.Time{}
+	}
+	t, err := time.Parse(layout[:len(digits)], digits)
+	if err != nil {
+		return time.Time{}
+	}
+
+	zone = strings.ReplaceAll(zone, "'", "")
+	if len(zone) >= 3 && (zone[0] == '+' || zone[0] == '-') {
+		hours, _ := strconv.Atoi(zone[1:3])
+		minutes := 0
+		if len(zone) >= 5 {
+			minutes, _ = strconv.Atoi(zone[3:5])
+		}
+		offset := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute
+		if zone[0] == '+' {
+			t = t.Add(-offset)
+		} else {
+			t = t.Add(offset)
+		}
+	}
+	return t.UTC()
+}
+
+func readSection(r io.ReaderAt, offset int64, n int) ([]byte, error) {
+	buf := make([]byte, n)
+	read, err := r.ReadAt(buf, offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return buf[:read], nil
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxBlockSize bounds the size of a single metadata block loaded in memory.
+const maxBlockSize = 64 << 20
+
+// errBlockTooLarge is returned when a metadata block exceeds maxBlockSize.
+var errBlockTooLarge = errors.New("metadata block is too large")
+
+// stream is a forward reader that tracks its position and skips data by
+// seeking when the underlying reader supports it, or by discarding it otherwise.
+type stream struct {
+	r      io.Reader
+	seeker io.Seeker
+	pos    int64
+	peeked []byte
+}
+
+func newStream(r io.Reader) *stream {
+	s := &stream{r: r}
+	if seeker, ok := r.(io.Seeker); ok {
+		s.seeker = seeker
+	}
+	return s
+}
+
+func (s *stream) Read(p []byte) (int, error) {
+	if len(s.peeked) > 0 {
+		n := copy(p, s.peeked)
+		s.peeked = s.peeked[n:]
+		s.pos += int64(n)
+		return n, nil
+	}
+	n, err := s.r.Read(p)
+	s.pos += int64(n)
+	return n, err
+}
+
+// peek returns the next n bytes without consuming them.
+// It returns fewer bytes only at the end of the input.
+func (s *stream) peek(n int) ([]byte, error) {
+	if len(s.peeked) < n {
+		buf := make([]byte, n-len(s.peeked))
+		read, err := io.ReadFull(s.r, buf)
+		s.peeked = append(s.peeked, buf[:read]...)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+			return s.peeked, err
+		}
+	}
+	if len(s.peeked) < n {
+		return s.peeked, nil
+	}
+	return s.peeked[:n], nil
+}
+
+// readFull reads exactly n bytes.
+func (s *stream) readFull(n int64) ([]byte, error) {
+	if n < 0 || n > maxBlockSize {
+		return nil, fmt.Errorf("%w: %d bytes", errBlockTooLarge, n)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(s, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// skip advances the stream by n bytes.
+func (s *stream) skip(n int64) error {
+	if n <= 0 {
+		return nil
+	}
+	if p := int64(len(s.peeked)); p > 0 {
+		if n <= p {
+			s.peeked = s.peeked[n:]
+			s.pos += n
+			return nil
+		}
+		s.peeked = nil
+		s.pos += p
+		n -= p
+	}
+	if s.seeker != nil {
+		if _, err := s.seeker.Seek(n, io.SeekCurrent); err != nil {
+			return err
+		}
+		s.pos += n
+		return nil
+	}
+	copied, err := io.CopyN(io.Discard, s.r, n)
+	s.pos += copied
+	return err
+}
+
+// seekTo moves the stream to the absolute offset off.
+// Without an underlying seeker only forward moves are possible.
+func (s *stream) seekTo(off int64) error {
+	if off >= s.pos {
+		return s.skip(off - s.pos)
+	}
+	if s.seeker == nil {
+		return fmt.Errorf("cannot seek backward to offset %d on a non seekable input", off)
+	}
+	if _, err := s.seeker.Seek(off, io.SeekStart); err != nil {
+		return err
+	}
+	s.peeked = nil
+	s.pos = off
+	return nil
+}
+
+// tail returns the last n bytes of an input of the given size.
+func (s *stream) tail(size int64, n int64) ([]byte, error) {
+	start := size - n
+	if start < s.pos && s.seeker == nil {
+		start = s.pos
+	}
+	if start < 0 {
+		start = 0
+	}
+	if err := s.seekTo(start); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(io.LimitReader(s, size-start))
+}
@@ -0,0 +1,529 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// ErrNotVideo is returned when the data is not a supported video container
+// or when the container has no video track.
+var ErrNotVideo = errors.New("not a supported video file")
+
+// Video containers reported in VideoInfo.Format, in addition to FormatMP4.
+const (
+	FormatMOV      = "mov"
+	FormatMatroska = "matroska"
+	FormatWebM     = "webm"
+)
+
+// VideoInfo holds the properties of the first video track of a container.
+// Fields are left empty when the container does not define them.
+type VideoInfo struct {
+	Format string
+	// Codec is the sample entry FourCC (avc1, hvc1, vp09...). Matroska codec
+	// IDs are mapped to the equivalent FourCC when one exists.
+	Codec     string
+	Width     int
+	Height    int
+	FrameRate float64
+	Duration  time.Duration
+	// Created is the recording time stored in the container, in UTC.
+	Created time.Time
+}
+
+// ReadVideoInfo reads the header of MP4/MOV (ISO BMFF) and Matroska/WebM files.
+// Only the metadata boxes and elements are loaded; media data is skipped by
+// seeking when r implements io.Seeker.
+func ReadVideoInfo(r io.Reader) (*VideoInfo, error) {
+	s := newStream(r)
+	head, err := s.peek(12)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(head) >= 8 && string(head[4:8]) == "ftyp":
+		return readMP4Video(s)
+	case len(head) >= 8 && (string(head[4:8]) == "moov" || string(head[4:8]) == "wide" ||
+		string(head[4:8]) == "mdat"):
+		// Old QuickTime files have no ftyp box
+		return readMP4Video(s)
+	case bytes.HasPrefix(head, ebmlMagic):
+		return readMatroska(s)
+	default:
+		return nil, ErrNotVideo
+	}
+}
+
+// --- MP4 / QuickTime ---
+
+func readMP4Video(s *stream) (*VideoInfo, error) {
+	info := &VideoInfo{Format: FormatMP4}
+	head, err := s.peek(12)
+	if err != nil {
+		return nil, err
+	}
+	if len(head) < 12 || string(head[4:8]) != "ftyp" || string(head[8:12]) == "qt  " {
+		info.Format = FormatMOV
+	}
+
+	moov, err := readTopLevelBox(s, "moov")
+	if errors.Is(err, errBoxNotFound) {
+		return nil, ErrNotVideo
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if mvhd, ok := findBox(moov.data, moov.start, "mvhd"); ok {
+		if header, ok := parseMovieHeader(mvhd.data); ok {
+			info.Duration = header.duration
+			info.Created = header.created
+		}
+	}
+
+	found := false
+	_ = walkBoxes(moov.data, moov.start, func(trak box) bool {
+		if trak.typ != "trak" {
+			return true
+		}
+		found = parseVideoTrack(trak, info)
+		return !found
+	})
+	if !found {
+		return nil, ErrNotVideo
+	}
+	return info, nil
+}
+
+// parseVideoTrack fills info from a trak box and reports whether it is a video track.
+func parseVideoTrack(trak box, info *VideoInfo) bool {
+	mdia, ok := findBox(trak.data, trak.start, "mdia")
+	if !ok {
+		return false
+	}
+	hdlr, ok := findBox(mdia.data, mdia.start, "hdlr")
+	if !ok || len(hdlr.data) < 12 || string(hdlr.data[8:12]) != "vide" {
+		return false
+	}
+
+	var trackDuration float64
+	if mdhd, ok := findBox(mdia.data, mdia.start, "mdhd"); ok {
+		if header, ok := parseMovieHeader(mdhd.data); ok {
+			trackDuration = header.duration.Seconds()
+			if info.Duration == 0 {
+				info.Duration = header.duration
+			}
+		}
+	}
+
+	if tkhd, ok := findBox(trak.data, trak.start, "tkhd"); ok && len(tkhd.data) >= 84 {
+		// Display size, 16.16 fixed point numbers at the end of the box
+		n := len(tkhd.data)
+		info.Width = int(binary.BigEndian.Uint32(tkhd.data[n-8:]) >> 16)
+		info.Height = int(binary.BigEndian.Uint32(tkhd.data[n-4:]) >> 16)
+	}
+
+	minf, ok := findBox(mdia.data, mdia.start, "minf")
+	if !ok {
+		return true
+	}
+	stbl, ok := findBox(minf.data, minf.start, "stbl")
+	if !ok {
+		return true
+	}
+
+	if stsd, ok := findBox(stbl.data, stbl.start, "stsd"); ok && len(stsd.data) >= 8 {
+		// Full box header and entry count precede the first sample entry
+		if entry, ok := findFirstBox(stsd.data[8:]); ok {
+			info.Codec = entry.typ
+			if len(entry.data) >= 28 && (info.Width == 0 || info.Height == 0) {
+				info.Width = int(binary.BigEndian.Uint16(entry.data[24:]))
+				info.Height = int(binary.BigEndian.Uint16(entry.data[26:]))
+			}
+		}
+	}
+
+	if stts, ok := findBox(stbl.data, stbl.start, "stts"); ok && trackDuration > 0 {
+		if samples := sampleCount(stts.data); samples > 0 {
+			info.FrameRate = roundFrameRate(float64(samples) / trackDuration)
+		}
+	}
+	return true
+}
+
+func findFirstBox(buf []byte) (box, bool) {
+	var first box
+	var ok bool
+	_ = walkBoxes(buf, 0, func(b box) bool {
+		first, ok = b, true
+		return false
+	})
+	return first, ok
+}
+
+// sampleCount sums the sample counts of a time-to-sample (stts) box.
+func sampleCount(stts []byte) uint64 {
+	if len(stts) < 8 {
+		return 0
+	}
+	entries := int(binary.BigEndian.Uint32(stts[4:]))
+	var total uint64
+	for i := 0; i < entries && 8+i*8+8 <= len(stts); i++ {
+		total += uint64(binary.BigEndian.Uint32(stts[8+i*8:]))
+	}
+	return total
+}
+
+// roundFrameRate rounds to two decimals so that 29.97 fps is reported as such.
+func roundFrameRate(fps float64) float64 {
+	return math.Round(fps*100) / 100
+}
+
+// --- Matroska / WebM ---
+
+var ebmlMagic = []byte{0x1A, 0x45, 0xDF, 0xA3}
+
+// EBML element IDs, with their marker bits.
+const (
+	ebmlDocType          = 0x4282
+	mkvSegment           = 0x18538067
+	mkvSeekHead          = 0x114D9B74
+	mkvSeek              = 0x4DBB
+	mkvSeekID            = 0x53AB
+	mkvSeekPosition      = 0x53AC
+	mkvInfo              = 0x1549A966
+	mkvTimestampScale    = 0x2AD7B1
+	mkvDuration          = 0x4489
+	mkvDateUTC           = 0x4461
+	mkvTracks            = 0x1654AE6B
+	mkvTrackEntry        = 0xAE
+	mkvTrackType         = 0x83
+	mkvCodecID           = 0x86
+	mkvDefaultDuration   = 0x23E383
+	mkvVideo             = 0xE0
+	mkvPixelWidth        = 0xB0
+	mkvPixelHeight       = 0xBA
+	mkvCluster           = 0x1F43B675
+	mkvTrackTypeVideo    = 1
+	mkvDefaultTimescale  = 1000000
+	ebmlUnknownSize      = -1
+	maxEBMLElementHeader = 12
+)
+
+// mkvEpoch is the origin of Matroska dates.
+var mkvEpoch = time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// mkvCodecs maps Matroska codec IDs to the equivalent MP4 FourCC.
+var mkvCodecs = map[string]string{
+	"V_MPEG4/ISO/AVC":  "avc1",
+	"V_MPEGH/ISO/HEVC": "hvc1",
+	"V_MPEG4/ISO/SP":   "mp4v",
+	"V_MPEG4/ISO/ASP":  "mp4v",
+	"V_VP8":            "vp08",
+	"V_VP9":            "vp09",
+	"V_AV1":            "av01",
+	"V_MJPEG":          "mjpg",
+	"V_PRORES":         "apcn",
+}
+
+// ebmlElement is an EBML element header.
+type ebmlElement struct {
+	id uint64
+	// size is ebmlUnknownSize for live streams and unfinished recordings
+	size   int64
+	header int64
+}
+
+// readVint decodes an EBML variable length integer. Marker bits are kept for IDs.
+func readVint(b []byte, keepMarker bool) (uint64, int, bool) {
+	if len(b) == 0 || b[0] == 0 {
+		return 0, 0, false
+	}
+	length := 1
+	for mask := byte(0x80); b[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if len(b) < length {
+		return 0, 0, false
+	}
+	value := uint64(b[0])
+	if !keepMarker {
+		value &= uint64(0xFF >> length)
+	}
+	for _, c := range b[1:length] {
+		value = value<<8 | uint64(c)
+	}
+	return value, length, true
+}
+
+func parseEBMLHeader(b []byte) (ebmlElement, bool) {
+	id, idLen, ok := readVint(b, true)
+	if !ok || idLen > 4 {
+		return ebmlElement{}, false
+	}
+	size, sizeLen, ok := readVint(b[idLen:], false)
+	if !ok || sizeLen > 8 {
+		return ebmlElement{}, false
+	}
+	e := ebmlElement{id: id, size: int64(size), header: int64(idLen + sizeLen)}
+	if size == 1<<(7*sizeLen)-1 { // all value bits set
+		e.size = ebmlUnknownSize
+	}
+	return e, true
+}
+
+func (s *stream) readEBMLHeader() (ebmlElement, error) {
+	head, err := s.peek(maxEBMLElementHeader)
+	if err != nil {
+		return ebmlElement{}, err
+	}
+	if len(head) == 0 {
+		return ebmlElement{}, io.EOF
+	}
+	e, ok := parseEBMLHeader(head)
+	if !ok {
+		return ebmlElement{}, io.ErrUnexpectedEOF
+	}
+	return e, s.skip(e.header)
+}
+
+// walkEBML iterates over the elements of an in-memory EBML master element.
+func walkEBML(buf []byte, fn func(id uint64, data []byte)) {
+	for pos := 0; pos < len(buf); {
+		e, ok := parseEBMLHeader(buf[pos:])
+		if !ok || e.size == ebmlUnknownSize {
+			return
+		}
+		start := pos + int(e.header)
+		end := start + int(e.size)
+		if e.size > int64(len(buf)) || end > len(buf) {
+			return
+		}
+		fn(e.id, buf[start:end])
+		pos = end
+	}
+}
+
+func ebmlUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+func ebmlFloat(b []byte) float64 {
+	switch len(b) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(b))
+	default:
+		return 0
+	}
+}
+
+func readMatroska(s *stream) (*VideoInfo, error) {
+	header, err := s.readEBMLHeader()
+	if err != nil {
+		return nil, err
+	}
+	if header.size == ebmlUnknownSize {
+		return nil, ErrNotVideo
+	}
+	body, err := s.readFull(header.size)
+	if err != nil {
+		return nil, err
+	}
+	info := &VideoInfo{Format: FormatMatroska}
+	walkEBML(body, func(id uint64, data []byte) {
+		if id == ebmlDocType && strings.TrimRight(string(data), "\x00") == "webm" {
+			info.Format = FormatWebM
+		}
+	})
+
+	segment, err := s.readEBMLHeader()
+	if err != nil {
+		return nil, err
+	}
+	if segment.id != mkvSegment {
+		return nil, ErrNotVideo
+	}
+	segmentStart := s.pos
+
+	var infoData, tracksData []byte
+	seekPositions := map[uint64]int64{}
+scan:
+	for infoData == nil || tracksData == nil {
+		e, err := s.readEBMLHeader()
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if e.size == ebmlUnknownSize {
+			// Clusters of live recordings: metadata elements come first
+			break scan
+		}
+
+		switch e.id {
+		case mkvInfo, mkvTracks, mkvSeekHead:
+			data, err := s.readFull(e.size)
+			if err != nil {
+				return nil, err
+			}
+			switch e.id {
+			case mkvInfo:
+				infoData = data
+			case mkvTracks:
+				tracksData = data
+			default:
+				parseSeekHead(data, seekPositions)
+			}
+		case mkvCluster:
+			// Media data: jump to the metadata elements indexed by the seek head
+			target, ok := nextSeekTarget(seekPositions, infoData == nil, tracksData == nil)
+			if !ok {
+				break scan
+			}
+			if err := s.seekTo(segmentStart + target); err != nil {
+				return nil, err
+			}
+		default:
+			if err := s.skip(e.size); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if infoData != nil {
+		parseMatroskaInfo(infoData, info)
+	}
+	if tracksData == nil || !parseMatroskaTracks(tracksData, info) {
+		return nil, ErrNotVideo
+	}
+	return info, nil
+}
+
+// nextSeekTarget returns the segment position of the next metadata element
+// still to read, and removes it from positions so that it is visited once.
+func nextSeekTarget(positions map[uint64]int64, needInfo, needTracks bool) (int64, bool) {
+	for _, id := range []uint64{mkvInfo, mkvTracks} {
+		pos, ok := positions[id]
+		if !ok || (id == mkvInfo && !needInfo) || (id == mkvTracks && !needTracks) {
+			continue
+		}
+		delete(positions, id)
+		return pos, true
+	}
+	return 0, false
+}
+
+func parseSeekHead(data []byte, positions map[uint64]int64) {
+	walkEBML(data, func(id uint64, seek []byte) {
+		if id != mkvSeek {
+			return
+		}
+		var target uint64
+		var position int64 = -1
+		walkEBML(seek, func(id uint64, value []byte) {
+			switch id {
+			case mkvSeekID:
+				target = ebmlUint(value)
+			case mkvSeekPosition:
+				position = int64(ebmlUint(value))
+			}
+		})
+		if (target == mkvInfo || target == mkvTracks) && position >= 0 {
+			positions[target] = position
+		}
+	})
+}
+
+func parseMatroskaInfo(data []byte, info *VideoInfo) {
+	scale := uint64(mkvDefaultTimescale)
+	var duration float64
+	walkEBML(data, func(id uint64, value []byte) {
+		switch id {
+		case mkvTimestampScale:
+			if v := ebmlUint(value); v > 0 {
+				scale = v
+			}
+		case mkvDuration:
+			duration = ebmlFloat(value)
+		case mkvDateUTC:
+			if len(value) == 8 {
+				info.Created = mkvEpoch.Add(time.Duration(int64(binary.BigEndian.Uint64(value))))
+			}
+		}
+	})
+	if duration > 0 {
+		info.Duration = time.Duration(duration * float64(scale))
+	}
+}
+
+// parseMatroskaTracks fills info from the first video track and reports whether one exists.
+func parseMatroskaTracks(data []byte, info *VideoInfo) bool {
+	found := false
+	walkEBML(data, func(id uint64, entry []byte) {
+		if id != mkvTrackEntry || found {
+			return
+		}
+		var isVideo bool
+		var codec string
+		var width, height int
+		var frameDuration uint64
+		walkEBML(entry, func(id uint64, value []byte) {
+			switch id {
+			case mkvTrackType:
+				isVideo = ebmlUint(value) == mkvTrackTypeVideo
+			case mkvCodecID:
+				codec = strings.TrimRight(string(value), "\x00")
+			case mkvDefaultDuration:
+				frameDuration = ebmlUint(value)
+			case mkvVideo:
+				walkEBML(value, func(id uint64, value []byte) {
+					switch id {
+					case mkvPixelWidth:
+						width = int(ebmlUint(value))
+					case mkvPixelHeight:
+						height = int(ebmlUint(value))
+					}
+				})
+			}
+		})
+		if !isVideo {
+			return
+		}
+		found = true
+		info.Codec = codec
+		if fourCC, ok := mkvCodecs[codec]; ok {
+			info.Codec = fourCC
+		}
+		info.Width, info.Height = width, height
+		if frameDuration > 0 {
+			info.FrameRate = roundFrameRate(float64(time.Second) / float64(frameDuration))
+		}
+	})
+	return found
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fullBox(version byte, fields ...uint32) []byte {
+	b := []byte{version, 0, 0, 0}
+	for _, f := range fields {
+		b = binary.BigEndian.AppendUint32(b, f)
+	}
+	return b
+}
+
+func mp4VideoFile(brand string, created time.Time) []byte {
+	createdSecs := uint32(created.Sub(mp4Epoch) / time.Second)
+	mvhd := append(fullBox(0, createdSecs, createdSecs, 1000, 10000), make([]byte, 80)...)
+
+	tkhd := make([]byte, 84)
+	binary.BigEndian.PutUint32(tkhd[76:], 1920<<16)
+	binary.BigEndian.PutUint32(tkhd[80:], 1080<<16)
+
+	mdhd := append(fullBox(0, 0, 0, 30000, 300000), 0, 0, 0, 0)
+	hdlr := append(fullBox(0, 0), []byte("vide")...)
+	hdlr = append(hdlr, make([]byte, 13)...)
+
+	sampleEntry := make([]byte, 78)
+	binary.BigEndian.PutUint16(sampleEntry[24:], 1920)
+	binary.BigEndian.PutUint16(sampleEntry[26:], 1080)
+	stsd := append(fullBox(0, 1), bmffBox("avc1", sampleEntry)...)
+	stts := fullBox(0, 1, 300, 1000)
+
+	stbl := bmffBox("stbl", bmffBox("stsd", stsd), bmffBox("stts", stts))
+	mdia := bmffBox("mdia", bmffBox("mdhd", mdhd), bmffBox("hdlr", hdlr), bmffBox("minf", stbl))
+	moov := bmffBox("moov", bmffBox("mvhd", mvhd), bmffBox("trak", bmffBox("tkhd", tkhd), mdia))
+
+	file := bmffBox("ftyp", []byte(brand), []byte{0, 0, 0, 0})
+	file = append(file, bmffBox("mdat", make([]byte, 8192))...)
+	return append(file, moov...)
+}
+
+func TestReadVideoInfo_MP4(t *testing.T) {
+	created := time.Date(2023, time.April, 1, 10, 15, 0, 0, time.UTC)
+	info, err := ReadVideoInfo(bytes.NewReader(mp4VideoFile("isom", created)))
+	require.NoError(t, err)
+
+	assert.Equal(t, FormatMP4, info.Format)
+	assert.Equal(t, "avc1", info.Codec)
+	assert.Equal(t, 1920, info.Width)
+	assert.Equal(t, 1080, info.Height)
+	assert.Equal(t, 30.0, info.FrameRate)
+	assert.Equal(t, 10*time.Second, info.Duration)
+	assert.Equal(t, created, info.Created)
+
+	info, err = ReadVideoInfo(bytes.NewReader(mp4VideoFile("qt  ", created)))
+	require.NoError(t, err)
+	assert.Equal(t, FormatMOV, info.Format)
+}
+
+func TestReadVideoInfo_AudioOnlyMP4(t *testing.T) {
+	moov := bmffBox("moov", bmffBox("mvhd", fullBox(0, 0, 0, 1000, 1000)))
+	file := append(bmffBox("ftyp", []byte("M4A "), []byte{0, 0, 0, 0}), moov...)
+
+	_, err := ReadVideoInfo(bytes.NewReader(file))
+	assert.ErrorIs(t, err, ErrNotVideo)
+}
+
+func ebmlElem(id uint64, payload ...[]byte) []byte {
+	var idBytes []byte
+	for shift := 24; shift >= 0; shift -= 8 {
+		if b := byte(id >> shift); b != 0 || len(idBytes) > 0 {
+			idBytes = append(idBytes, b)
+		}
+	}
+	body := bytes.Join(payload, nil)
+	size := binary.BigEndian.AppendUint64(nil, uint64(len(body)))
+	size[0] = 0x01 // 8 byte vint marker
+	return append(append(idBytes, size...), body...)
+}
+
+func ebmlUintBytes(v uint64, n int) []byte {
+	return binary.BigEndian.AppendUint64(nil, v)[8-n:]
+}
+
+func matroskaFile(docType string, clusterFirst bool) []byte {
+	created := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+	info := ebmlElem(mkvInfo,
+		ebmlElem(mkvTimestampScale, ebmlUintBytes(1000000, 3)),
+		ebmlElem(mkvDuration, binary.BigEndian.AppendUint64(nil, math.Float64bits(12000))),
+		ebmlElem(mkvDateUTC, ebmlUintBytes(uint64(created.Sub(mkvEpoch)), 8)),
+	)
+	tracks := ebmlElem(mkvTracks,
+		ebmlElem(mkvTrackEntry,
+			ebmlElem(mkvTrackType, []byte{2}),
+			ebmlElem(mkvCodecID, []byte("A_OPUS")),
+		),
+		ebmlElem(mkvTrackEntry,
+			ebmlElem(mkvTrackType, []byte{1}),
+			ebmlElem(mkvCodecID, []byte("V_VP9")),
+			ebmlElem(mkvDefaultDuration, ebmlUintBytes(33366667, 4)),
+			ebmlElem(mkvVideo,
+				ebmlElem(mkvPixelWidth, ebmlUintBytes(3840, 2)),
+				ebmlElem(mkvPixelHeight, ebmlUintBytes(2160, 2)),
+			),
+		),
+	)
+	cluster := ebmlElem(mkvCluster, make([]byte, 4096))
+
+	var children []byte
+	if clusterFirst {
+		// The seek head has a fixed size, so positions can be computed up front
+		seekHead := func(infoPos, tracksPos uint64) []byte {
+			return ebmlElem(mkvSeekHead,
+				ebmlElem(mkvSeek, ebmlElem(mkvSeekID, ebmlUintBytes(mkvInfo, 4)), ebmlElem(mkvSeekPosition, ebmlUintBytes(infoPos, 8))),
+				ebmlElem(mkvSeek, ebmlElem(mkvSeekID, ebmlUintBytes(mkvTracks, 4)), ebmlElem(mkvSeekPosition, ebmlUintBytes(tracksPos, 8))),
+			)
+		}
+		headSize := uint64(len(seekHead(0, 0)))
+		infoPos := headSize + uint64(len(cluster))
+		children = bytes.Join([][]byte{seekHead(infoPos, infoPos+uint64(len(info))), cluster, info, tracks}, nil)
+	} else {
+		children = bytes.Join([][]byte{info, tracks, cluster}, nil)
+	}
+
+	file := ebmlElem(0x1A45DFA3, ebmlElem(ebmlDocType, []byte(docType)))
+	return append(file, ebmlElem(mkvSegment, children)...)
+}
+
+func TestReadVideoInfo_Matroska(t *testing.T) {
+	for _, clusterFirst := range []bool{false, true} {
+		info, err := ReadVideoInfo(bytes.NewReader(matroskaFile("webm", clusterFirst)))
+		require.NoError(t, err)
+
+		assert.Equal(t, FormatWebM, info.Format)
+		assert.Equal(t, "vp09", info.Codec)
+		assert.Equal(t, 3840, info.Width)
+		assert.Equal(t, 2160, info.Height)
+		assert.Equal(t, 29.97, info.FrameRate)
+		assert.Equal(t, 12*time.Second, info.Duration)
+		assert.Equal(t, time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC), info.Created.UTC())
+	}
+
+	info, err := ReadVideoInfo(bytes.NewReader(matroskaFile("matroska", false)))
+	require.NoError(t, err)
+	assert.Equal(t, FormatMatroska, info.Format)
+}
+
+func TestReadVideoInfo_NotVideo(t *testing.T) {
+	_, err := ReadVideoInfo(bytes.NewReader([]byte("definitely not a video")))
+	assert.ErrorIs(t, err, ErrNotVideo)
+}
@@ -138,6 +138,22 @@ type ErrorStats struct {
 	mu     sync.Mutex
 }
 
+// ValidationFailure describes a file rejected by a validation filter.
+type ValidationFailure struct {
+	Path   string
+	Format string
+	Reason string
+}
+
+type ValidationStats struct {
+	Invalid int64
+
+	ByFormat map[string]int64
+	Failures []ValidationFailure
+	seen     map[string]struct{}
+	mu       sync.Mutex
+}
+
 type Stats struct {
 	Run        RunStats
 	Operations OperationStats
@@ -145,6 +161,7 @@ type Stats struct {
 	Hash       HashStats
 	Timing     TimingStats
 	Errors     ErrorStats
+	Validation ValidationStats
 }
 
 func (s *Stats) FileMoved(size int64) {
@@ -259,6 +276,13 @@ func (s *Stats) String() string {
 		fmt.Fprintf(&b, "Errors: %d\n", s.Run.Errors)
 	}
 
+	if s.Validation.Invalid > 0 {
+		fmt.Fprintf(&b, "Invalid files: %d\n", s.Validation.Invalid)
+		for _, failure := range s.Validation.Failures {
+			fmt.Fprintf(&b, "  %s (%s): %s\n", failure.Path, failure.Format, failure.Reason)
+		}
+	}
+
 	return b.String()
 }
 
@@ -282,6 +306,31 @@ func (s *Stats) Error(err error) {
 	s.Errors.mu.Unlock()
 }
 
+// FileInvalid records a file that failed validation. A file checked for
+// several destinations is recorded once; FileInvalid reports whether it was new.
+func (s *Stats) FileInvalid(path, format, reason string) bool {
+	s.Validation.mu.Lock()
+	defer s.Validation.mu.Unlock()
+
+	if s.Validation.seen == nil {
+		s.Validation.seen = make(map[string]struct{})
+		s.Validation.ByFormat = make(map[string]int64)
+	}
+	if _, ok := s.Validation.seen[path]; ok {
+		return false
+	}
+	s.Validation.seen[path] = struct{}{}
+
+	s.Validation.Invalid++
+	s.Validation.ByFormat[format]++
+	s.Validation.Failures = append(s.Validation.Failures, ValidationFailure{
+		Path:   path,
+		Format: format,
+		Reason: reason,
+	})
+	return true
+}
+
 func humanBytes(n int64) string {
 	const unit = 1024
 	if n < unit {
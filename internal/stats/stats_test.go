@@ -17,6 +17,7 @@ import (
 	"context"
 	"errors"
 	"io/fs"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -193,3 +194,28 @@ func TestStringDoesNotPanic(t *testing.T) {
 
 	_ = s.String() // must not panic
 }
+
+func TestFileInvalidRecordedOnce(t *testing.T) {
+	var s Stats
+
+	if !s.FileInvalid("/src/a.zip", "zip", "zip: checksum error") {
+		t.Error("first failure should be new")
+	}
+	if s.FileInvalid("/src/a.zip", "zip", "zip: checksum error") {
+		t.Error("failure of the same file should not be recorded twice")
+	}
+	s.FileInvalid("/src/b.pdf", "pdf", "missing %%EOF marker")
+
+	if s.Validation.Invalid != 2 {
+		t.Errorf("Invalid = %d, want 2", s.Validation.Invalid)
+	}
+	if s.Validation.ByFormat["zip"] != 1 || s.Validation.ByFormat["pdf"] != 1 {
+		t.Errorf("ByFormat = %v, want zip=1 pdf=1", s.Validation.ByFormat)
+	}
+	if len(s.Validation.Failures) != 2 || s.Validation.Failures[0].Reason != "zip: checksum error" {
+		t.Errorf("Failures = %v", s.Validation.Failures)
+	}
+	if !strings.Contains(s.String(), "Invalid files: 2") {
+		t.Errorf("String() does not report invalid files:\n%s", s.String())
+	}
+}
@@ -0,0 +1,343 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package strategy
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/polocto/FolderFlow/pkg/ffplugin/strategy"
+)
+
+const (
+	defaultBucketMaxFiles = 1000
+	defaultBucketWidth    = 4
+	// bucketOther holds the names matching no alphabetic range.
+	bucketOther = "other"
+)
+
+var defaultBucketRanges = []string{"0-9", "a-f", "g-l", "m-r", "s-z"}
+
+// BucketStrategy keeps at most MaxFiles files, or MaxBytes bytes, per leaf
+// directory by placing files in numbered sub-buckets such as "0001/".
+// With Naming "alpha", files are first grouped by the first character of
+// their name, as in "a-f/0001/".
+type BucketStrategy struct {
+	MaxFiles int   `yaml:"max_files"`
+	MaxBytes int64 `yaml:"max_bytes"`
+	// Naming is numbered (the default) or alpha
+	Naming string   `yaml:"naming"`
+	Ranges []string `yaml:"ranges"`
+	Width  int      `yaml:"width"`
+
+	ranges []bucketRange
+	state  *bucketState
+}
+
+// bucketRange is an inclusive range of first characters, such as "a-f".
+type bucketRange struct {
+	name     string
+	from, to rune
+}
+
+// bucketState is shared by the workers classifying files concurrently.
+type bucketState struct {
+	// mu only guards dirs: each directory has its own lock, so that scanning or
+	// hashing in one directory does not hold back the others
+	mu sync.Mutex
+	// dirs holds the buckets of each directory, by absolute path
+	dirs map[string]*bucketDir
+}
+
+// bucketDir tracks the numbered buckets of one directory. Files are only
+// added to the last bucket: earlier ones are not filled again, even when
+// files were removed from them.
+type bucketDir struct {
+	mu sync.Mutex
+	// scanned is set once the buckets of a previous run have been read
+	scanned bool
+	current int
+	files   int
+	bytes   int64
+	// names maps the file names already placed to their files, so that a
+	// file classified again goes to the same bucket
+	names map[string][]*bucketFile
+}
+
+// bucketFile is a file placed in a bucket by this run or a previous one.
+type bucketFile struct {
+	bucket int
+	size   int64
+	// hash is only computed when another file with the same name and size is placed
+	hash *[sha256.Size]byte
+}
+
+func (s *BucketStrategy) Selector() string {
+	return "bucket"
+}
+
+func (s *BucketStrategy) LoadConfig(config map[string]interface{}) error {
+	var cfg BucketStrategy
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	if cfg.MaxFiles < 0 || cfg.MaxBytes < 0 {
+		return fmt.Errorf("'max_files' and 'max_bytes' cannot be negative")
+	}
+	if cfg.MaxFiles == 0 && cfg.MaxBytes == 0 {
+		cfg.MaxFiles = defaultBucketMaxFiles
+	}
+	switch {
+	case cfg.Width == 0:
+		cfg.Width = defaultBucketWidth
+	case cfg.Width < 1 || cfg.Width > 9:
+		return fmt.Errorf("'width' must be between 1 and 9")
+	}
+
+	switch cfg.Naming = strings.ToLower(cfg.Naming); cfg.Naming {
+	case "":
+		cfg.Naming = "numbered"
+	case "numbered", "alpha":
+	default:
+		return fmt.Errorf("invalid naming %q, must be numbered or alpha", cfg.Naming)
+	}
+	if cfg.Naming == "numbered" && len(cfg.Ranges) > 0 {
+		return fmt.Errorf("'ranges' requires naming alpha")
+	}
+	if cfg.Naming == "alpha" {
+		ranges := cfg.Ranges
+		if len(ranges) == 0 {
+			ranges = defaultBucketRanges
+		}
+		for _, spec := range ranges {
+			r, err := parseBucketRange(spec)
+			if err != nil {
+				return err
+			}
+			for _, other := range cfg.ranges {
+				if r.from <= other.to && other.from <= r.to {
+					return fmt.Errorf("ranges %q and %q overlap", other.name, r.name)
+				}
+			}
+			cfg.ranges = append(cfg.ranges, r)
+		}
+	}
+	cfg.state = &bucketState{dirs: make(map[string]*bucketDir)}
+
+	*s = cfg
+
+	slog.Debug("Loading bucket was successful", "config", config)
+	return nil
+}
+
+// parseBucketRange parses "a-f" or a single character such as "x".
+func parseBucketRange(spec string) (bucketRange, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	from, size := utf8.DecodeRuneInString(spec)
+	to := from
+	switch rest := spec[size:]; {
+	case rest == "":
+	case strings.HasPrefix(rest, "-") && utf8.RuneCountInString(rest) == 2:
+		to, _ = utf8.DecodeRuneInString(rest[1:])
+	default:
+		from = utf8.RuneError
+	}
+	if from == utf8.RuneError || to < from || strings.ContainsAny(spec, "/\\.") {
+		return bucketRange{}, fmt.Errorf("invalid range %q, must be a character or two separated by '-', as in \"a-f\"", spec)
+	}
+	return bucketRange{name: spec, from: from, to: to}, nil
+}
+
+// rangeDir returns the alphabetic bucket of a file name.
+func (s *BucketStrategy) rangeDir(name string) string {
+	first, _ := utf8.DecodeRuneInString(name)
+	first = unicode.ToLower(first)
+	for _, r := range s.ranges {
+		if first >= r.from && first <= r.to {
+			return r.name
+		}
+	}
+	return bucketOther
+}
+
+func (s *BucketStrategy) FinalDirPath(ctx strategy.Context) (string, error) {
+	if ctx.Info().IsDir() {
+		return "", fmt.Errorf("filePath %s is a directory, expected a file", ctx.PathFromSource())
+	}
+
+	dir, name := path.Split(filepath.ToSlash(inputPath(ctx)))
+	segments := []string{ctx.DstDir()}
+	for _, segment := range strings.Split(dir, "/") {
+		if segment != "" && segment != "." && segment != ".." {
+			segments = append(segments, segment)
+		}
+	}
+	if s.Naming == "alpha" {
+		segments = append(segments, s.rangeDir(name))
+	}
+	parent := filepath.Join(segments...)
+
+	bucket, err := s.place(parent, name, ctx)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(parent, bucket, name), nil
+}
+
+// place returns the bucket of a file and counts it in that bucket.
+func (s *BucketStrategy) place(parent, name string, ctx strategy.Context) (string, error) {
+	s.state.mu.Lock()
+	d, ok := s.state.dirs[parent]
+	if !ok {
+		d = &bucketDir{}
+		s.state.dirs[parent] = d
+	}
+	s.state.mu.Unlock()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.scanned {
+		if err := s.scan(parent, d); err != nil {
+			return "", err
+		}
+		d.scanned = true
+	}
+
+	// Only the same file goes back to its bucket: another file with the same
+	// name is counted like a new one, the bucket it would go to may be full
+	for _, placed := range d.names[name] {
+		if sameFile(filepath.Join(parent, s.bucketName(placed.bucket), name), placed, ctx) {
+			return s.bucketName(placed.bucket), nil
+		}
+	}
+
+	size := ctx.Info().Size()
+	if !s.fits(d, size) {
+		d.current, d.files, d.bytes = d.current+1, 0, 0
+	}
+	d.files++
+	d.bytes += size
+	d.names[name] = append(d.names[name], &bucketFile{bucket: d.current, size: size})
+	return s.bucketName(d.current), nil
+}
+
+// sameFile reports whether ctx is the file placed at path, comparing sizes then
+// contents. A placed file that cannot be read, for instance because it has not
+// been moved yet, is considered different.
+func sameFile(path string, placed *bucketFile, ctx strategy.Context) bool {
+	if placed.size != ctx.Info().Size() {
+		return false
+	}
+	if placed.hash == nil {
+		sum, err := hashFile(path)
+		if err != nil {
+			return false
+		}
+		placed.hash = &sum
+	}
+	sum, err := ctx.Hash()
+	return err == nil && sum == *placed.hash
+}
+
+func hashFile(path string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	f, err := os.Open(path)
+	if err != nil {
+		return sum, err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+// fits reports whether a file can be added to the current bucket. A file
+// larger than MaxBytes still goes to an empty bucket.
+func (s *BucketStrategy) fits(d *bucketDir, size int64) bool {
+	if d.files == 0 {
+		return true
+	}
+	if s.MaxFiles > 0 && d.files >= s.MaxFiles {
+		return false
+	}
+	return s.MaxBytes == 0 || d.bytes+size <= s.MaxBytes
+}
+
+func (s *BucketStrategy) bucketName(n int) string {
+	return fmt.Sprintf("%0*d", s.Width, n)
+}
+
+// scan reads into d the buckets already present in parent, from a previous run.
+func (s *BucketStrategy) scan(parent string, d *bucketDir) error {
+	d.current, d.files, d.bytes = 1, 0, 0
+	d.names = make(map[string][]*bucketFile)
+	entries, err := os.ReadDir(parent)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read buckets of %q: %w", parent, err)
+	}
+
+	for _, entry := range entries {
+		// Only the buckets named with the configured width are counted
+		n, err := strconv.Atoi(entry.Name())
+		if err != nil || n < 1 || entry.Name() != s.bucketName(n) || !entry.IsDir() {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(parent, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("cannot read bucket %q: %w", filepath.Join(parent, entry.Name()), err)
+		}
+
+		var count int
+		var bytes int64
+		for _, file := range files {
+			info, err := file.Info()
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			count++
+			bytes += info.Size()
+			d.names[file.Name()] = append(d.names[file.Name()], &bucketFile{bucket: n, size: info.Size()})
+		}
+		if n > d.current || (n == d.current && d.files == 0) {
+			d.current, d.files, d.bytes = n, count, bytes
+		}
+	}
+	slog.Debug("Buckets scanned", "dir", parent, "current", d.current, "files", d.files, "bytes", d.bytes)
+	return nil
+}
+
+func init() {
+	strategy.RegisterStrategy("bucket", func() strategy.Strategy {
+		slog.Debug("Create a strategy", "name", "bucket")
+		return &BucketStrategy{}
+	})
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package strategy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bucketPath(t *testing.T, s *BucketStrategy, dst, relPath string, size int64) string {
+	t.Helper()
+	return bucketPathContent(t, s, dst, relPath, make([]byte, size))
+}
+
+func bucketPathContent(t *testing.T, s *BucketStrategy, dst, relPath string, content []byte) string {
+	t.Helper()
+	path, err := relFinalPath(t, s, &mockContext{
+		pathFromSource:       filepath.FromSlash(relPath),
+		destinationDirectory: dst,
+		info:                 mockFileInfo{name: filepath.Base(relPath), size: int64(len(content))},
+		content:              content,
+	})
+	require.NoError(t, err)
+	return path
+}
+
+func TestBucketStrategy_MaxFiles(t *testing.T) {
+	s := &BucketStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"max_files": 2}))
+	dst := t.TempDir()
+
+	var paths []string
+	for _, name := range []string{"a.txt", "b.txt", "c.txt", "d.txt", "e.txt"} {
+		paths = append(paths, bucketPath(t, s, dst, "photos/"+name, 1))
+	}
+	assert.Equal(t, []string{
+		"photos/0001/a.txt", "photos/0001/b.txt", "photos/0002/c.txt", "photos/0002/d.txt", "photos/0003/e.txt",
+	}, paths)
+
+	// Each directory has its own buckets
+	assert.Equal(t, "music/0001/a.txt", bucketPath(t, s, dst, "music/a.txt", 1))
+}
+
+func TestBucketStrategy_MaxBytes(t *testing.T) {
+	s := &BucketStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"max_bytes": 10, "width": 2}))
+	dst := t.TempDir()
+
+	assert.Equal(t, "01/a", bucketPath(t, s, dst, "a", 6))
+	assert.Equal(t, "02/b", bucketPath(t, s, dst, "b", 5))
+	assert.Equal(t, "02/c", bucketPath(t, s, dst, "c", 5))
+	assert.Equal(t, "03/big", bucketPath(t, s, dst, "big", 20), "a large file gets its own bucket")
+	assert.Equal(t, "04/d", bucketPath(t, s, dst, "d", 1))
+}
+
+func TestBucketStrategy_Alpha(t *testing.T) {
+	s := &BucketStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"naming": "alpha", "max_files": 1}))
+	dst := t.TempDir()
+
+	assert.Equal(t, "a-f/0001/apple.jpg", bucketPath(t, s, dst, "apple.jpg", 1))
+	assert.Equal(t, "a-f/0002/Banana.jpg", bucketPath(t, s, dst, "Banana.jpg", 1))
+	assert.Equal(t, "s-z/0001/zebra.jpg", bucketPath(t, s, dst, "zebra.jpg", 1))
+	assert.Equal(t, "0-9/0001/42.jpg", bucketPath(t, s, dst, "42.jpg", 1))
+	assert.Equal(t, "other/0001/_x.jpg", bucketPath(t, s, dst, "_x.jpg", 1))
+
+	s = &BucketStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"naming": "alpha", "ranges": []string{"a-m", "n-z", "é"}}))
+	assert.Equal(t, "n-z/0001/zebra.jpg", bucketPath(t, s, dst, "zebra.jpg", 1))
+	assert.Equal(t, "é/0001/Été.jpg", bucketPath(t, s, dst, "Été.jpg", 1))
+}
+
+func TestBucketStrategy_ExistingBuckets(t *testing.T) {
+	dst := t.TempDir()
+	for _, file := range []string{"0001/a.txt", "0001/b.txt", "0002/c.txt", "002/x.txt", "9/y.txt"} {
+		path := filepath.Join(dst, "docs", filepath.FromSlash(file))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte("data"), 0o644))
+	}
+
+	s := &BucketStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"max_files": 2}))
+
+	assert.Equal(t, "docs/0002/d.txt", bucketPath(t, s, dst, "docs/d.txt", 4))
+	assert.Equal(t, "docs/0003/e.txt", bucketPath(t, s, dst, "docs/e.txt", 4))
+	assert.Equal(t, "docs/0001/a.txt", bucketPathContent(t, s, dst, "docs/a.txt", []byte("data")),
+		"same bucket as the previous run")
+	assert.Equal(t, "docs/0003/f.txt", bucketPath(t, s, dst, "docs/f.txt", 4), "the file was not counted again")
+}
+
+func TestBucketStrategy_SameNameDifferentFile(t *testing.T) {
+	s := &BucketStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"max_files": 2}))
+	dst := t.TempDir()
+
+	place := func(content string) string {
+		t.Helper()
+		rel := bucketPathContent(t, s, dst, "photos/x.jpg", []byte(content))
+		// Move the file to its destination, as the classifier does
+		path := filepath.Join(dst, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		}
+		return rel
+	}
+
+	assert.Equal(t, "photos/0001/x.jpg", place("first"))
+	assert.Equal(t, "photos/0001/y.jpg", bucketPath(t, s, dst, "photos/y.jpg", 1))
+
+	// Bucket 0001 is full: a different x.jpg, even of the same size, goes to the next bucket
+	assert.Equal(t, "photos/0002/x.jpg", place("other"))
+	assert.Equal(t, "photos/0002/x.jpg", place("other"), "the same file goes back to its bucket")
+	assert.Equal(t, "photos/0001/x.jpg", place("first"))
+	assert.Equal(t, "photos/0002/z.jpg", bucketPath(t, s, dst, "photos/z.jpg", 1))
+	assert.Equal(t, "photos/0003/w.jpg", bucketPath(t, s, dst, "photos/w.jpg", 1))
+}
+
+func TestBucketStrategy_Candidate(t *testing.T) {
+	s := &BucketStrategy{}
+	require.NoError(t, s.LoadConfig(nil))
+
+	candidate := filepath.Join("2024", "03", "a.jpg")
+	path, err := s.FinalDirPath(&mockContext{
+		pathFromSource:       "a.jpg",
+		destinationDirectory: "dst",
+		info:                 mockFileInfo{name: "a.jpg"},
+		candidate:            &candidate,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("dst", "2024", "03", "0001", "a.jpg"), path)
+}
+
+func TestBucketStrategy_Concurrent(t *testing.T) {
+	s := &BucketStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"max_files": 10}))
+	dst := t.TempDir()
+
+	var mu sync.Mutex
+	counts := make(map[string]int)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			path, err := s.FinalDirPath(&mockContext{
+				pathFromSource:       fmt.Sprintf("%03d.txt", i),
+				destinationDirectory: dst,
+				info:                 mockFileInfo{name: fmt.Sprintf("%03d.txt", i)},
+			})
+			assert.NoError(t, err)
+			mu.Lock()
+			counts[filepath.Dir(path)]++
+			mu.Unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(t, counts, 10)
+	for dir, count := range counts {
+		assert.Equal(t, 10, count, dir)
+	}
+}
+
+func TestBucketStrategy_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"max_files": -1},
+		{"max_bytes": -1},
+		{"width": 12},
+		{"naming": "hex"},
+		{"ranges": []string{"a-f"}},
+		{"naming": "alpha", "ranges": []string{"f-a"}},
+		{"naming": "alpha", "ranges": []string{"abc"}},
+		{"naming": "alpha", "ranges": []string{"a-f", "e-g"}},
+		{"naming": "alpha", "ranges": []string{"."}},
+		{"naming": "alpha", "ranges": []string{""}},
+		{"size": 10},
+	}
+	for _, config := range invalid {
+		s := &BucketStrategy{}
+		assert.Error(t, s.LoadConfig(config), "config %v", config)
+	}
+}
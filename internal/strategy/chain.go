@@ -0,0 +1,111 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package strategy
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/polocto/FolderFlow/pkg/ffplugin/strategy"
+)
+
+// ChainStrategy runs strategies one after the other, each step transforming
+// the destination computed by the previous one, as in "date, then flatten".
+type ChainStrategy struct {
+	Steps []chainStep `yaml:"steps"`
+
+	strategies []strategy.Strategy
+}
+
+// chainStep is configured with the same name and config keys as the strategy
+// of a destination.
+type chainStep struct {
+	Name   string                 `yaml:"name"`
+	Config map[string]interface{} `yaml:"config"`
+}
+
+// chainContext passes the result of the previous step to the next one.
+// Everything else still describes the source file.
+type chainContext struct {
+	strategy.Context
+	candidate string
+}
+
+func (c *chainContext) Candidate() (string, bool) {
+	return c.candidate, true
+}
+
+func (s *ChainStrategy) Selector() string {
+	return "chain"
+}
+
+func (s *ChainStrategy) LoadConfig(config map[string]interface{}) error {
+	var cfg ChainStrategy
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	if len(cfg.Steps) == 0 {
+		return fmt.Errorf("'steps' config cannot be empty")
+	}
+	for i, step := range cfg.Steps {
+		if step.Name == "" {
+			return fmt.Errorf("step %d: 'name' config cannot be empty", i+1)
+		}
+		strat, err := strategy.NewStrategy(step.Name)
+		if err != nil {
+			return fmt.Errorf("step %d: failed to create strategy '%s': %w", i+1, step.Name, err)
+		}
+		if err := strat.LoadConfig(step.Config); err != nil {
+			return fmt.Errorf("step %d: failed to load config for strategy '%s': %w", i+1, step.Name, err)
+		}
+		cfg.strategies = append(cfg.strategies, strat)
+	}
+
+	*s = cfg
+
+	slog.Debug("Loading chain was successful", "config", config)
+	return nil
+}
+
+func (s *ChainStrategy) FinalDirPath(ctx strategy.Context) (string, error) {
+	if ctx.Info().IsDir() {
+		return "", fmt.Errorf("filePath %s is a directory, expected a file", ctx.PathFromSource())
+	}
+
+	stepCtx := ctx
+	var candidate string
+	for i, step := range s.strategies {
+		dst, err := step.FinalDirPath(stepCtx)
+		if err != nil {
+			return "", fmt.Errorf("step %d (%s): %w", i+1, step.Selector(), err)
+		}
+		rel, err := filepath.Rel(ctx.DstDir(), dst)
+		if err != nil || rel == "." || !filepath.IsLocal(rel) {
+			return "", fmt.Errorf("step %d (%s): path %q is not inside the destination directory", i+1, step.Selector(), dst)
+		}
+		candidate = rel
+		stepCtx = &chainContext{Context: ctx, candidate: candidate}
+		slog.Debug("Chain step done", "step", i+1, "strategy", step.Selector(), "candidate", candidate)
+	}
+	return filepath.Join(ctx.DstDir(), candidate), nil
+}
+
+func init() {
+	strategy.RegisterStrategy("chain", func() strategy.Strategy {
+		slog.Debug("Create a strategy", "name", "chain")
+		return &ChainStrategy{}
+	})
+}
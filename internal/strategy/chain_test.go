@@ -0,0 +1,127 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package strategy
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/polocto/FolderFlow/pkg/ffplugin/strategy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainStrategy_FinalDirPath(t *testing.T) {
+	modTime := time.Date(2024, time.March, 2, 8, 0, 0, 0, time.Local)
+	step := func(name string, config map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"name": name, "config": config}
+	}
+
+	testCases := []struct {
+		name     string
+		steps    []interface{}
+		relPath  string
+		expected string
+	}{
+		{
+			"dirchain then flatten",
+			[]interface{}{
+				step("dirchain", map[string]interface{}{"strip_leading": 1}),
+				step("flatten", map[string]interface{}{"encode_path": true}),
+			},
+			"clientA/2023/q1/report.pdf", "2023__q1__report.pdf",
+		},
+		{
+			"date then template",
+			[]interface{}{
+				step("date", map[string]interface{}{"format": "2006/01"}),
+				step("template", map[string]interface{}{"pattern": "{dir}/{stem|lower}{ext}"}),
+			},
+			"Photos/IMG_0001.JPG", filepath.Join("2024", "03", "img_0001.JPG"),
+		},
+		{
+			"regex on the candidate path",
+			[]interface{}{
+				step("template", map[string]interface{}{"pattern": "{year}/{name}"}),
+				step("regex", map[string]interface{}{"pattern": `^(?P<year>\d{4})/`, "match": "path", "destination": "archive-{year}"}),
+			},
+			"a/b.txt", filepath.Join("archive-2024", "b.txt"),
+		},
+		{
+			"nested chain",
+			[]interface{}{
+				step("chain", map[string]interface{}{"steps": []interface{}{step("dirchain", nil)}}),
+				step("dirchain", map[string]interface{}{"prefix": "sorted"}),
+			},
+			"a/b.txt", filepath.Join("sorted", "a", "b.txt"),
+		},
+		{"single step", []interface{}{step("flatten", nil)}, "a/b.txt", "b.txt"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &ChainStrategy{}
+			require.NoError(t, s.LoadConfig(map[string]interface{}{"steps": tc.steps}))
+
+			path, err := s.FinalDirPath(&mockContext{
+				pathFromSource:       filepath.FromSlash(tc.relPath),
+				destinationDirectory: "dst",
+				info:                 mockFileInfo{name: filepath.Base(tc.relPath), modTime: modTime},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join("dst", tc.expected), path)
+		})
+	}
+}
+
+func TestChainStrategy_Errors(t *testing.T) {
+	ctx := &mockContext{pathFromSource: "a.txt", destinationDirectory: "dst", info: mockFileInfo{name: "a.txt"}}
+
+	testCases := []struct {
+		name string
+		step *mockStrategy
+	}{
+		{"step error", &mockStrategy{selector: "mock", err: errors.New("boom")}},
+		{"outside destination", &mockStrategy{selector: "mock", finalDirPath: filepath.Join("other", "a.txt")}},
+		{"destination itself", &mockStrategy{selector: "mock", finalDirPath: "dst"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &ChainStrategy{strategies: []strategy.Strategy{tc.step}}
+			_, err := s.FinalDirPath(ctx)
+			assert.Error(t, err)
+		})
+	}
+
+	s := &ChainStrategy{strategies: []strategy.Strategy{&mockStrategy{finalDirPath: filepath.Join("dst", "a.txt")}}}
+	_, err := s.FinalDirPath(&mockContext{pathFromSource: "dir", destinationDirectory: "dst", info: mockFileInfo{isDir: true}})
+	assert.Error(t, err)
+}
+
+func TestChainStrategy_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{},
+		{"steps": []interface{}{}},
+		{"steps": []interface{}{map[string]interface{}{"config": map[string]interface{}{}}}},
+		{"steps": []interface{}{map[string]interface{}{"name": "unknown"}}},
+		{"steps": []interface{}{map[string]interface{}{"name": "template", "config": map[string]interface{}{"pattern": "{nope}"}}}},
+		{"steps": []interface{}{map[string]interface{}{"name": "flatten", "options": map[string]interface{}{}}}},
+	}
+	for _, config := range invalid {
+		s := &ChainStrategy{}
+		assert.Error(t, s.LoadConfig(config), "config %v", config)
+	}
+}
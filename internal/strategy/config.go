@@ -0,0 +1,74 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package strategy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// decodeConfig decodes a raw plugin configuration into out.
+// Unknown keys are rejected so that typos do not silently change a layout.
+func decodeConfig(config map[string]interface{}, out interface{}) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// decodeLenientConfig is decodeConfig for strategies that have always accepted
+// any key: unknown keys are logged and ignored rather than rejected.
+func decodeLenientConfig(config map[string]interface{}, out interface{}) error {
+	strictErr := decodeConfig(config, out)
+	if strictErr == nil {
+		return nil
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(data, out); err != nil {
+		return err
+	}
+	slog.Warn("Unknown strategy options ignored", "err", strictErr)
+	return nil
+}
+
+// checkRelativeDir rejects directories that could leave the destination directory.
+func checkRelativeDir(dir string) error {
+	if filepath.IsAbs(dir) || strings.HasPrefix(dir, "/") {
+		return fmt.Errorf("must be relative to the destination directory")
+	}
+	for _, segment := range strings.Split(filepath.ToSlash(dir), "/") {
+		if segment == ".." {
+			return fmt.Errorf("'..' segments are not allowed")
+		}
+	}
+	return nil
+}
@@ -13,9 +13,14 @@
 package strategy
 
 import (
+	"crypto/sha256"
 	"fmt"
+	"io"
 	"io/fs"
+	"log/slog"
+	"os"
 	"path/filepath"
+	"time"
 
 	filehandler "github.com/polocto/FolderFlow/internal/fileHandler"
 	"github.com/polocto/FolderFlow/pkg/ffplugin/strategy"
@@ -26,6 +31,9 @@ type ContextStrategy struct {
 	relPath string      // Relative Path from the Source Directory
 	dstDir  string      // Destination Directory
 	info    fs.FileInfo // File's informations
+	file    filehandler.Context
+	// attributes set by the filters that matched the file
+	attributes map[string]string
 }
 
 // NewContext creates a new Context for the given path.
@@ -45,6 +53,7 @@ func NewContextStrategy(file filehandler.Context, srcDir, dstDir string) (strate
 		relPath: subPath,
 		dstDir:  dstDir,
 		info:    file,
+		file:    file,
 	}, nil
 }
 
@@ -59,3 +68,75 @@ func (ctx *ContextStrategy) DstDir() string {
 func (ctx *ContextStrategy) Info() fs.FileInfo {
 	return ctx.info
 }
+
+// Hash returns the SHA-256 of the file, shared with filters and duplicate detection.
+func (ctx *ContextStrategy) Hash() ([sha256.Size]byte, error) {
+	return ctx.file.GetHash()
+}
+
+// WithInput opens the file for reading and passes it to the callback.
+func (ctx *ContextStrategy) WithInput(fn func(r io.Reader) error) error {
+	if ctx.info.IsDir() {
+		return fmt.Errorf("cannot open directory %q for reading", ctx.path)
+	}
+	f, err := os.Open(ctx.path)
+	if err != nil {
+		return fmt.Errorf("cannot open file %q: %w", ctx.path, err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			slog.Warn("failed to close file : ", "path", ctx.path, "err", err)
+		}
+	}()
+
+	return fn(f)
+}
+
+// WithInputLimited reads only the first maxBytes bytes of the file.
+func (ctx *ContextStrategy) WithInputLimited(maxBytes int64, fn func(r io.Reader) error) error {
+	return ctx.WithInput(func(f io.Reader) error {
+		return fn(io.LimitReader(f, maxBytes))
+	})
+}
+
+// BirthTime returns the creation time of the file when the filesystem records it.
+func (ctx *ContextStrategy) BirthTime() (time.Time, bool) {
+	return filehandler.BirthTime(ctx.file)
+}
+
+// Attribute returns a value set by the filters that matched the file.
+func (ctx *ContextStrategy) Attribute(name string) (string, bool) {
+	value, ok := ctx.attributes[name]
+	return value, ok
+}
+
+// Candidate returns false: a context created by NewContextStrategy starts
+// from the source file.
+func (ctx *ContextStrategy) Candidate() (string, bool) {
+	return "", false
+}
+
+// inputPath returns the path a strategy transforms: the candidate of a chain
+// or, for the first step, the path from the source directory.
+func inputPath(ctx strategy.Context) string {
+	if candidate, ok := ctx.Candidate(); ok {
+		return candidate
+	}
+	return ctx.PathFromSource()
+}
+
+// inputName returns the file name of inputPath.
+func inputName(ctx strategy.Context) string {
+	if candidate, ok := ctx.Candidate(); ok {
+		return filepath.Base(candidate)
+	}
+	return ctx.Info().Name()
+}
+
+// SetAttributes passes the attributes of the filters that matched the file to
+// the strategy. It does nothing for contexts not created by NewContextStrategy.
+func SetAttributes(ctx strategy.Context, attrs map[string]string) {
+	if c, ok := ctx.(*ContextStrategy); ok {
+		c.attributes = attrs
+	}
+}
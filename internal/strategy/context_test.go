@@ -14,6 +14,7 @@
 package strategy_test
 
 import (
+	"crypto/sha256"
 	"os"
 	"path/filepath"
 	"testing"
@@ -69,6 +70,32 @@ func TestContext_Getters(t *testing.T) {
 	assert.Equal(t, ctx.Info(), ctxFile)
 }
 
+func TestContext_Hash(t *testing.T) {
+	ctxFile := newTempContextFile(t, "file.txt", []byte("content"))
+
+	ctx, err := strategy.NewContextStrategy(ctxFile, filepath.Dir(ctxFile.Path()), t.TempDir())
+	assert.NoError(t, err)
+
+	sum, err := ctx.Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, sha256.Sum256([]byte("content")), sum)
+}
+
+func TestContext_Attributes(t *testing.T) {
+	ctxFile := newTempContextFile(t, "file.txt", []byte("content"))
+
+	ctx, err := strategy.NewContextStrategy(ctxFile, filepath.Dir(ctxFile.Path()), t.TempDir())
+	assert.NoError(t, err)
+
+	_, ok := ctx.Attribute("exif.make")
+	assert.False(t, ok)
+
+	strategy.SetAttributes(ctx, map[string]string{"exif.make": "Canon"})
+	value, ok := ctx.Attribute("exif.make")
+	assert.True(t, ok)
+	assert.Equal(t, "Canon", value)
+}
+
 func TestNewContext_RelativePaths(t *testing.T) {
 	tmpDir := t.TempDir()
 	subDir := filepath.Join(tmpDir, "folder", "sub")
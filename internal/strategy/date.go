@@ -14,15 +14,58 @@
 package strategy
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/polocto/FolderFlow/internal/metadata"
 	"github.com/polocto/FolderFlow/pkg/ffplugin/strategy"
 )
 
-// plugin/date_strategy.go
+// Date sources, in the order they are usually the most reliable.
+const (
+	DateSourceEXIF      = "exif"
+	DateSourceVideo     = "video"
+	DateSourceFilename  = "filename"
+	DateSourceBirthTime = "birthtime"
+	DateSourceMTime     = "mtime"
+)
+
+// dateEXIFMaxBytes is enough for the EXIF block of JPEG and most HEIC files.
+const dateEXIFMaxBytes = 1 << 20
+
+// minUsableDate rejects the zero timestamps written in metadata by devices without a clock.
+var minUsableDate = time.Date(1970, time.January, 2, 0, 0, 0, 0, time.UTC)
+
+// filenameDatePatterns recognize the names given by cameras, phones and
+// screenshot tools. Time groups are optional.
+var filenameDatePatterns = []*regexp.Regexp{
+	// IMG_20230401_101500, PXL_20230401_101500123, Screenshot_20230401-101500, IMG-20230401-WA0001
+	regexp.MustCompile(`(?:^|\D)(?P<year>\d{4})(?P<month>\d{2})(?P<day>\d{2})` +
+		`(?:[_\-T ]?(?P<hour>\d{2})(?P<minute>\d{2})(?P<second>\d{2})\d{0,3})?(?:\D|$)`),
+	// Screenshot 2023-04-01 at 10.15.00, WhatsApp Image 2023-04-01 at 10.15.00, 2023_04_01
+	regexp.MustCompile(`(?:^|\D)(?P<year>\d{4})[-_.](?P<month>\d{2})[-_.](?P<day>\d{2})` +
+		`(?:(?:\s+at\s+|[ _T-])(?P<hour>\d{2})[-.:h](?P<minute>\d{2})(?:[-.:m](?P<second>\d{2}))?)?(?:\D|$)`),
+}
+
+// DateStrategy places files in directories named after a date, "2006/01" by
+// default. The date comes from the first source of Source providing one.
 type DateStrategy struct {
-	Format string `yaml:"format"`
+	Format           string   `yaml:"format"`
+	Source           []string `yaml:"source"`
+	Timezone         string   `yaml:"timezone"`
+	Fallback         string   `yaml:"fallback"`
+	FilenamePatterns []string `yaml:"filename_patterns"`
+
+	loc      *time.Location
+	patterns []*regexp.Regexp
 }
 
 func (s *DateStrategy) Selector() string {
@@ -30,20 +73,205 @@ func (s *DateStrategy) Selector() string {
 }
 
 func (s *DateStrategy) LoadConfig(config map[string]interface{}) error {
-	if format, ok := config["format"].(string); ok {
-		s.Format = format
-	} else {
-		s.Format = "2006/01" // default
+	var cfg DateStrategy
+	if err := decodeLenientConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	if cfg.Format == "" {
+		cfg.Format = "2006/01" // default
+	}
+	if len(cfg.Source) == 0 {
+		cfg.Source = []string{DateSourceMTime}
+	}
+	for i, source := range cfg.Source {
+		cfg.Source[i] = strings.ToLower(strings.TrimSpace(source))
+		if !slices.Contains([]string{DateSourceEXIF, DateSourceVideo, DateSourceFilename,
+			DateSourceBirthTime, DateSourceMTime}, cfg.Source[i]) {
+			return fmt.Errorf("invalid source %q, must be one of exif, video, filename, birthtime or mtime", source)
+		}
+	}
+
+	cfg.loc = time.Local
+	if cfg.Timezone != "" {
+		loc, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", cfg.Timezone, err)
+		}
+		cfg.loc = loc
+	}
+
+	if cfg.Fallback != "" {
+		if err := checkRelativeDir(cfg.Fallback); err != nil {
+			return fmt.Errorf("invalid fallback %q: %w", cfg.Fallback, err)
+		}
 	}
+
+	for _, pattern := range cfg.FilenamePatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid filename pattern %q: %w", pattern, err)
+		}
+		for _, group := range []string{"year", "month", "day"} {
+			if re.SubexpIndex(group) < 0 {
+				return fmt.Errorf("invalid filename pattern %q: missing named group %q", pattern, group)
+			}
+		}
+		cfg.patterns = append(cfg.patterns, re)
+	}
+	cfg.patterns = append(cfg.patterns, filenameDatePatterns...)
+
+	*s = cfg
+
+	slog.Debug("Loading date was successful", "config", config)
 	return nil
 }
 
 func (s *DateStrategy) FinalDirPath(ctx strategy.Context) (string, error) {
-	yearMonth := ctx.Info().ModTime().Format(s.Format)
-	finalDest := filepath.Join(ctx.DstDir(), yearMonth, ctx.Info().Name())
+	date, source, err := s.date(ctx)
+	if err != nil {
+		return "", err
+	}
+	if source == "" {
+		if s.Fallback == "" {
+			return "", fmt.Errorf("no usable date for %q from sources %v", ctx.PathFromSource(), s.Source)
+		}
+		return filepath.Join(ctx.DstDir(), s.Fallback, inputName(ctx)), nil
+	}
+
+	slog.Debug("Date found", "path", ctx.PathFromSource(), "source", source, "date", date)
+	finalDest := filepath.Join(ctx.DstDir(), date.In(s.loc).Format(s.Format), inputName(ctx))
 	return finalDest, nil
 }
 
+// date returns the date given by the first usable source, and that source.
+// The source is empty when none provides a date.
+func (s *DateStrategy) date(ctx strategy.Context) (time.Time, string, error) {
+	for _, source := range s.Source {
+		date, ok, err := s.dateFrom(ctx, source)
+		if err != nil {
+			return time.Time{}, "", err
+		}
+		if !ok {
+			continue
+		}
+		// Only metadata can hold the zero timestamps of devices without a clock:
+		// file names are checked when parsed and the modification time is always used
+		switch source {
+		case DateSourceEXIF, DateSourceVideo, DateSourceBirthTime:
+			if !date.After(minUsableDate) {
+				continue
+			}
+		}
+		return date, source, nil
+	}
+	return time.Time{}, "", nil
+}
+
+func (s *DateStrategy) dateFrom(ctx strategy.Context, source string) (time.Time, bool, error) {
+	info := ctx.Info()
+	switch source {
+	case DateSourceEXIF:
+		if !info.Mode().IsRegular() {
+			return time.Time{}, false, nil
+		}
+		return s.exifDate(ctx)
+	case DateSourceVideo:
+		if !info.Mode().IsRegular() {
+			return time.Time{}, false, nil
+		}
+		return videoDate(ctx)
+	case DateSourceFilename:
+		date, ok := s.filenameDate(info.Name())
+		return date, ok, nil
+	case DateSourceBirthTime:
+		date, ok := ctx.BirthTime()
+		return date, ok, nil
+	default:
+		return info.ModTime(), true, nil
+	}
+}
+
+func (s *DateStrategy) exifDate(ctx strategy.Context) (time.Time, bool, error) {
+	var data []byte
+	err := ctx.WithInputLimited(dateEXIFMaxBytes, func(r io.Reader) error {
+		var err error
+		data, err = io.ReadAll(r)
+		return err
+	})
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	meta, err := metadata.ParseEXIF(data)
+	if err != nil {
+		if !errors.Is(err, metadata.ErrNoEXIF) {
+			slog.Debug("Invalid EXIF ignored", "path", ctx.PathFromSource(), "err", err)
+		}
+		return time.Time{}, false, nil
+	}
+	date, ok := meta.Taken(s.loc)
+	return date, ok, nil
+}
+
+func videoDate(ctx strategy.Context) (time.Time, bool, error) {
+	var video *metadata.VideoInfo
+	err := ctx.WithInput(func(r io.Reader) error {
+		var err error
+		if video, err = metadata.ReadVideoInfo(r); err != nil && !errors.Is(err, metadata.ErrNotVideo) {
+			slog.Debug("Invalid video header ignored", "path", ctx.PathFromSource(), "err", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if video == nil || video.Created.IsZero() {
+		return time.Time{}, false, nil
+	}
+	return video.Created, true, nil
+}
+
+// filenameDate returns the first valid date found in name. Dates in file
+// names have no time zone: they are read in the configured one.
+func (s *DateStrategy) filenameDate(name string) (time.Time, bool) {
+	for _, re := range s.patterns {
+		for _, m := range re.FindAllStringSubmatch(name, -1) {
+			if date, ok := s.matchDate(re, m); ok {
+				return date, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func (s *DateStrategy) matchDate(re *regexp.Regexp, m []string) (time.Time, bool) {
+	field := func(name string) int {
+		i := re.SubexpIndex(name)
+		if i < 0 || m[i] == "" {
+			return 0
+		}
+		n, err := strconv.Atoi(m[i])
+		if err != nil {
+			return -1
+		}
+		return n
+	}
+	year, month, day := field("year"), field("month"), field("day")
+	hour, minute, second := field("hour"), field("minute"), field("second")
+	if year < 1970 || year > 2100 || hour < 0 || minute < 0 || second < 0 {
+		return time.Time{}, false
+	}
+
+	date := time.Date(year, time.Month(month), day, hour, minute, second, 0, s.loc)
+	// time.Date normalizes out of range values such as month 13: reject them
+	if date.Year() != year || int(date.Month()) != month || date.Day() != day ||
+		date.Hour() != hour || date.Minute() != minute || date.Second() != second {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
 func init() {
 	strategy.RegisterStrategy("date", func() strategy.Strategy {
 		slog.Debug("Create a strategy", "name", "date")
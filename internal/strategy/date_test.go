@@ -14,10 +14,13 @@
 package strategy
 
 import (
+	"encoding/binary"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -43,3 +46,151 @@ func TestDateStrategy(t *testing.T) {
 		t.Fatalf("expected non-empty path")
 	}
 }
+
+// exifTIFF returns a TIFF header whose IFD0 only holds a DateTime tag.
+func exifTIFF(date string) []byte {
+	b := []byte("II*\x00")
+	b = binary.LittleEndian.AppendUint32(b, 8)
+	b = binary.LittleEndian.AppendUint16(b, 1)
+	b = binary.LittleEndian.AppendUint16(b, 0x0132) // DateTime
+	b = binary.LittleEndian.AppendUint16(b, 2)      // ASCII
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(date)+1))
+	b = binary.LittleEndian.AppendUint32(b, 26)
+	b = binary.LittleEndian.AppendUint32(b, 0)
+	return append(append(b, date...), 0)
+}
+
+func TestDateStrategy_Sources(t *testing.T) {
+	modTime := time.Date(2025, time.June, 10, 12, 0, 0, 0, time.UTC)
+	birthTime := time.Date(2022, time.February, 1, 12, 0, 0, 0, time.UTC)
+	photo := exifTIFF("2023:04:01 10:15:00")
+
+	testCases := []struct {
+		name      string
+		config    map[string]interface{}
+		fileName  string
+		content   []byte
+		birthTime time.Time
+		expected  string
+	}{
+		{"default is mtime", map[string]interface{}{}, "a.jpg", photo, birthTime, "2025/06"},
+		{"exif", map[string]interface{}{"source": []string{"exif", "mtime"}}, "a.jpg", photo, time.Time{}, "2023/04"},
+		{"no exif", map[string]interface{}{"source": []string{"exif", "mtime"}}, "a.jpg", []byte("text"), time.Time{}, "2025/06"},
+		{"not a video", map[string]interface{}{"source": []string{"video", "mtime"}}, "a.mp4", []byte("text"), time.Time{}, "2025/06"},
+		{
+			"filename before exif", map[string]interface{}{"source": []string{"filename", "exif"}},
+			"IMG_20210312_101500.jpg", photo, time.Time{}, "2021/03",
+		},
+		{"birth time", map[string]interface{}{"source": []string{"birthtime", "mtime"}}, "a.txt", nil, birthTime, "2022/02"},
+		{"no birth time", map[string]interface{}{"source": []string{"birthtime", "mtime"}}, "a.txt", nil, time.Time{}, "2025/06"},
+		{
+			"format", map[string]interface{}{"source": []string{"exif"}, "format": "2006/01-January/02"},
+			"a.jpg", photo, time.Time{}, "2023/04-April/01",
+		},
+		{
+			"fallback", map[string]interface{}{"source": []string{"exif", "filename"}, "fallback": "unknown-date"},
+			"notes.txt", nil, time.Time{}, "unknown-date",
+		},
+		{
+			"timezone", map[string]interface{}{"source": []string{"mtime"}, "timezone": "Asia/Tokyo", "format": "2006/01/02-15"},
+			"a.txt", nil, time.Time{}, "2025/06/10-21",
+		},
+		{
+			"custom filename pattern",
+			map[string]interface{}{
+				"source":            []string{"filename"},
+				"filename_patterns": []string{`scan-(?P<day>\d{2})(?P<month>\d{2})(?P<year>\d{4})`},
+			},
+			"scan-31012020.pdf", nil, time.Time{}, "2020/01",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &DateStrategy{}
+			require.NoError(t, s.LoadConfig(tc.config))
+
+			ctx := &mockContext{
+				pathFromSource:       tc.fileName,
+				destinationDirectory: "dst",
+				info:                 mockFileInfo{name: tc.fileName, modTime: modTime},
+				content:              tc.content,
+				birthTime:            tc.birthTime,
+			}
+			path, err := s.FinalDirPath(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join("dst", filepath.FromSlash(tc.expected), tc.fileName), path)
+		})
+	}
+}
+
+func TestDateStrategy_NoUsableDate(t *testing.T) {
+	s := &DateStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"source": []string{"filename"}}))
+
+	_, err := s.FinalDirPath(&mockContext{
+		pathFromSource: "notes.txt", destinationDirectory: "dst", info: mockFileInfo{name: "notes.txt"},
+	})
+	assert.Error(t, err)
+}
+
+func TestDateStrategy_EpochModTime(t *testing.T) {
+	s := &DateStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"format": "2006/01", "timezone": "UTC"}))
+
+	path, err := s.FinalDirPath(&mockContext{
+		pathFromSource: "a.txt", destinationDirectory: "dst",
+		info: mockFileInfo{name: "a.txt", modTime: time.Unix(0, 0)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("dst", "1970", "01", "a.txt"), path)
+}
+
+func TestDateStrategy_FilenameDate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected time.Time
+	}{
+		{"IMG_20230401_101500.jpg", time.Date(2023, 4, 1, 10, 15, 0, 0, time.UTC)},
+		{"PXL_20230401_101500123.jpg", time.Date(2023, 4, 1, 10, 15, 0, 0, time.UTC)},
+		{"VID-20230401-WA0001.mp4", time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"Screenshot_20230401-101500.png", time.Date(2023, 4, 1, 10, 15, 0, 0, time.UTC)},
+		{"Screenshot 2023-04-01 at 10.15.00.png", time.Date(2023, 4, 1, 10, 15, 0, 0, time.UTC)},
+		{"WhatsApp Image 2023-04-01 at 10.15.00.jpeg", time.Date(2023, 4, 1, 10, 15, 0, 0, time.UTC)},
+		{"2023-04-01 10.15.00.jpg", time.Date(2023, 4, 1, 10, 15, 0, 0, time.UTC)},
+		{"report_2023_04_01.pdf", time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"invoice 12345678.pdf", time.Time{}},
+		{"IMG_20231399_000000.jpg", time.Time{}},
+		{"order-1234567890123.pdf", time.Time{}},
+		{"notes.txt", time.Time{}},
+	}
+
+	s := &DateStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"timezone": "UTC"}))
+	for _, tc := range testCases {
+		date, ok := s.filenameDate(tc.name)
+		assert.Equal(t, !tc.expected.IsZero(), ok, tc.name)
+		assert.True(t, tc.expected.Equal(date), "%s: got %v", tc.name, date)
+	}
+}
+
+func TestDateStrategy_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"source": []string{"ctime"}},
+		{"timezone": "Mars/Olympus_Mons"},
+		{"fallback": "/unknown"},
+		{"fallback": "../unknown"},
+		{"filename_patterns": []string{"("}},
+		{"filename_patterns": []string{`(?P<year>\d{4})`}},
+	}
+	for _, config := range invalid {
+		s := &DateStrategy{}
+		assert.Error(t, s.LoadConfig(config), "config %v", config)
+	}
+}
+
+func TestDateStrategy_IgnoresUnknownKeys(t *testing.T) {
+	s := &DateStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"format": "2006", "layout": "yearly"}))
+	assert.Equal(t, "2006", s.Format)
+}
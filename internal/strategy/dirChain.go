@@ -16,30 +16,114 @@ package strategy
 import (
 	"fmt"
 	"log/slog"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/polocto/FolderFlow/pkg/ffplugin/strategy"
 )
 
-// plugin/date_strategy.go
-type DirChainStrategy struct{}
+// DirChainStrategy recreates the directories of the file relative to its
+// source directory. Leading levels, levels matching DropSegments and levels
+// above the last KeepDepth can be removed, and a Prefix template added.
+type DirChainStrategy struct {
+	StripLeading int      `yaml:"strip_leading"`
+	KeepDepth    *int     `yaml:"keep_depth"`
+	DropSegments []string `yaml:"drop_segments"`
+	Prefix       string   `yaml:"prefix"`
+
+	drop   []*regexp.Regexp
+	prefix *pathTemplate
+}
 
 func (s *DirChainStrategy) Selector() string {
 	return "dirchain"
 }
 
 func (s *DirChainStrategy) LoadConfig(config map[string]interface{}) error {
+	var cfg DirChainStrategy
+	if err := decodeLenientConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	if cfg.StripLeading < 0 {
+		return fmt.Errorf("'strip_leading' cannot be negative")
+	}
+	if cfg.KeepDepth != nil && *cfg.KeepDepth < 0 {
+		return fmt.Errorf("'keep_depth' cannot be negative")
+	}
+	for _, pattern := range cfg.DropSegments {
+		// Segments must match as a whole: "tmp" does not drop "tmp-2023"
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return fmt.Errorf("invalid drop_segments pattern %q: %w", pattern, err)
+		}
+		cfg.drop = append(cfg.drop, re)
+	}
+	if cfg.Prefix != "" {
+		tmpl, err := newPathTemplate(cfg.Prefix)
+		if err != nil {
+			return fmt.Errorf("invalid prefix %q: %w", cfg.Prefix, err)
+		}
+		cfg.prefix = tmpl
+	}
+
+	*s = cfg
+
+	slog.Debug("Loading dirchain was successful", "config", config)
 	return nil
 }
 
+// chain applies strip_leading, drop_segments and keep_depth, in this order.
+func (s *DirChainStrategy) chain(dirs []string) []string {
+	dirs = dirs[min(s.StripLeading, len(dirs)):]
+
+	var kept []string
+	for _, dir := range dirs {
+		if !s.dropped(dir) {
+			kept = append(kept, dir)
+		}
+	}
+	if s.KeepDepth != nil && len(kept) > *s.KeepDepth {
+		kept = kept[len(kept)-*s.KeepDepth:]
+	}
+	return kept
+}
+
+func (s *DirChainStrategy) dropped(dir string) bool {
+	for _, re := range s.drop {
+		if re.MatchString(dir) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *DirChainStrategy) FinalDirPath(ctx strategy.Context) (string, error) {
 	if ctx.Info().IsDir() {
 		return "", fmt.Errorf("filePath %s is a directory, expected a file", ctx.PathFromSource())
 	}
 	// Nettoyer les chemins pour éviter les problèmes avec les slashes finaux
+	dir, name := path.Split(filepath.ToSlash(inputPath(ctx)))
+	var dirs []string
+	for _, segment := range strings.Split(dir, "/") {
+		if segment != "" && segment != "." {
+			dirs = append(dirs, segment)
+		}
+	}
+
+	segments := []string{ctx.DstDir()}
+	if s.prefix != nil {
+		prefix, err := s.prefix.render(ctx, nil)
+		if err != nil {
+			return "", fmt.Errorf("prefix %q for %q: %w", s.Prefix, ctx.PathFromSource(), err)
+		}
+		segments = append(segments, prefix...)
+	}
+	segments = append(append(segments, s.chain(dirs)...), name)
 
-	finalDest := filepath.Join(ctx.DstDir(), ctx.PathFromSource())
+	finalDest := filepath.Join(segments...)
 
 	// Vérifier que la destination reste dans destDir (défense en profondeur)
 	relFromDest, err := filepath.Rel(ctx.DstDir(), finalDest)
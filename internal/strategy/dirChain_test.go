@@ -17,8 +17,10 @@ import (
 	"io/fs"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDirChainStrategy_FinalDirPath(t *testing.T) {
@@ -122,6 +124,88 @@ func TestDirChainStrategy_LoadConfig(t *testing.T) {
 	assert.NoError(t, err, "LoadConfig should not return an error")
 }
 
+func TestDirChainStrategy_Options(t *testing.T) {
+	modTime := time.Date(2024, time.March, 2, 8, 0, 0, 0, time.Local)
+	relPath := "inbox/2023/export-tool/clientA/report.pdf"
+
+	testCases := []struct {
+		name     string
+		config   map[string]interface{}
+		relPath  string
+		expected string
+	}{
+		{"no option", map[string]interface{}{}, relPath, "inbox/2023/export-tool/clientA/report.pdf"},
+		{"strip leading", map[string]interface{}{"strip_leading": 1}, relPath, "2023/export-tool/clientA/report.pdf"},
+		{"strip everything", map[string]interface{}{"strip_leading": 10}, relPath, "report.pdf"},
+		{"keep depth", map[string]interface{}{"keep_depth": 2}, relPath, "export-tool/clientA/report.pdf"},
+		{"keep no directory", map[string]interface{}{"keep_depth": 0}, relPath, "report.pdf"},
+		{"drop segments", map[string]interface{}{"drop_segments": []string{`export-.*`}}, relPath, "inbox/2023/clientA/report.pdf"},
+		{"drop whole segments only", map[string]interface{}{"drop_segments": []string{`export`}}, relPath, relPath},
+		{
+			"combined",
+			map[string]interface{}{"strip_leading": 1, "drop_segments": []string{`export-.*`}, "keep_depth": 1},
+			relPath, "clientA/report.pdf",
+		},
+		{
+			"prefix",
+			map[string]interface{}{"prefix": "{year}/{dir1|upper}", "strip_leading": 1},
+			relPath, "2024/INBOX/2023/export-tool/clientA/report.pdf",
+		},
+		{"empty prefix", map[string]interface{}{"prefix": "{parent}"}, "report.pdf", "report.pdf"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &DirChainStrategy{}
+			require.NoError(t, s.LoadConfig(tc.config))
+
+			dest, err := s.FinalDirPath(&mockContext{
+				pathFromSource:       filepath.FromSlash(tc.relPath),
+				destinationDirectory: "dst",
+				info:                 mockFileInfo{name: filepath.Base(tc.relPath), modTime: modTime},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join("dst", filepath.FromSlash(tc.expected)), dest)
+		})
+	}
+}
+
+func TestDirChainStrategy_EscapeCheck(t *testing.T) {
+	s := &DirChainStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"prefix": "{dir}"}))
+
+	_, err := s.FinalDirPath(&mockContext{
+		pathFromSource:       filepath.Join("..", "outside", "a.txt"),
+		destinationDirectory: "dst",
+		info:                 mockFileInfo{name: "a.txt"},
+	})
+	assert.Error(t, err)
+
+	require.NoError(t, s.LoadConfig(map[string]interface{}{}))
+	_, err = s.FinalDirPath(&mockContext{
+		pathFromSource:       filepath.Join("..", "..", "a.txt"),
+		destinationDirectory: "dst",
+		info:                 mockFileInfo{name: "a.txt"},
+	})
+	assert.Error(t, err)
+}
+
+func TestDirChainStrategy_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"strip_leading": -1},
+		{"keep_depth": -1},
+		{"drop_segments": []string{"("}},
+		{"prefix": "/abs"},
+		{"prefix": "../up"},
+		{"prefix": "{unknown}"},
+		{"strip_leading": "two"},
+	}
+	for _, config := range invalid {
+		s := &DirChainStrategy{}
+		assert.Error(t, s.LoadConfig(config), "config %v", config)
+	}
+}
+
 // func TestDirChainStrategy_Registration(t *testing.T) {
 // 	// Save the original registry
 // 	originalRegistry := strategyRegistry
@@ -0,0 +1,126 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package strategy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/polocto/FolderFlow/pkg/ffplugin/strategy"
+)
+
+const (
+	defaultFlattenSeparator = "__"
+	// defaultMaxNameLength is the file name limit of most filesystems, in bytes.
+	defaultMaxNameLength = 255
+	// minMaxNameLength leaves room for a part of the name, the extension and the hash suffix.
+	minMaxNameLength = 32
+	// maxExtensionLength is the longest suffix kept as an extension when truncating.
+	maxExtensionLength = 16
+)
+
+// FlattenStrategy places every file directly in the destination directory.
+// With EncodePath, the source directories are kept in the file name, as in
+// "clientA__2023__report.pdf".
+type FlattenStrategy struct {
+	EncodePath bool   `yaml:"encode_path"`
+	Separator  string `yaml:"separator"`
+	MaxLength  int    `yaml:"max_length"`
+}
+
+func (s *FlattenStrategy) Selector() string {
+	return "flatten"
+}
+
+func (s *FlattenStrategy) LoadConfig(config map[string]interface{}) error {
+	var cfg FlattenStrategy
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	if cfg.Separator == "" {
+		cfg.Separator = defaultFlattenSeparator
+	}
+	if strings.ContainsAny(cfg.Separator, "/\\\x00") {
+		return fmt.Errorf("invalid separator %q: cannot contain path separators", cfg.Separator)
+	}
+	switch {
+	case cfg.MaxLength == 0:
+		cfg.MaxLength = defaultMaxNameLength
+	case cfg.MaxLength < minMaxNameLength:
+		return fmt.Errorf("'max_length' must be at least %d", minMaxNameLength)
+	}
+
+	*s = cfg
+
+	slog.Debug("Loading flatten was successful", "config", config)
+	return nil
+}
+
+func (s *FlattenStrategy) FinalDirPath(ctx strategy.Context) (string, error) {
+	if ctx.Info().IsDir() {
+		return "", fmt.Errorf("filePath %s is a directory, expected a file", ctx.PathFromSource())
+	}
+
+	name := inputName(ctx)
+	if s.EncodePath {
+		var segments []string
+		for _, segment := range strings.Split(filepath.ToSlash(inputPath(ctx)), "/") {
+			if segment != "" && segment != "." && segment != ".." {
+				segments = append(segments, segment)
+			}
+		}
+		if len(segments) > 0 {
+			// The input path ends with the file name
+			name = strings.Join(segments, s.Separator)
+		}
+	}
+	return filepath.Join(ctx.DstDir(), truncateName(name, s.MaxLength)), nil
+}
+
+// truncateName shortens name to at most maxLen bytes, keeping its extension.
+// Truncated names end with a hash of the full name, so that names sharing a
+// long prefix stay distinct.
+func truncateName(name string, maxLen int) string {
+	if len(name) <= maxLen {
+		return name
+	}
+
+	ext := filepath.Ext(name)
+	if len(ext) > maxExtensionLength || ext == name {
+		ext = ""
+	}
+	sum := sha256.Sum256([]byte(name))
+	suffix := "~" + hex.EncodeToString(sum[:4])
+
+	stem := strings.TrimSuffix(name, ext)
+	keep := maxLen - len(ext) - len(suffix)
+	// Cut on a rune boundary so that the name stays valid UTF-8
+	for keep > 0 && !utf8.RuneStart(stem[keep]) {
+		keep--
+	}
+	return stem[:keep] + suffix + ext
+}
+
+func init() {
+	strategy.RegisterStrategy("flatten", func() strategy.Strategy {
+		slog.Debug("Create a strategy", "name", "flatten")
+		return &FlattenStrategy{}
+	})
+}
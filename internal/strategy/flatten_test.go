@@ -0,0 +1,107 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package strategy
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlattenStrategy_FinalDirPath(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   map[string]interface{}
+		relPath  string
+		expected string
+	}{
+		{"drop directories", map[string]interface{}{}, "clientA/2023/report.pdf", "report.pdf"},
+		{"encode path", map[string]interface{}{"encode_path": true}, "clientA/2023/report.pdf", "clientA__2023__report.pdf"},
+		{"separator", map[string]interface{}{"encode_path": true, "separator": "-"}, "clientA/2023/report.pdf", "clientA-2023-report.pdf"},
+		{"root file", map[string]interface{}{"encode_path": true}, "report.pdf", "report.pdf"},
+		{"outside source", map[string]interface{}{"encode_path": true}, "../other/report.pdf", "other__report.pdf"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &FlattenStrategy{}
+			require.NoError(t, s.LoadConfig(tc.config))
+
+			path, err := s.FinalDirPath(&mockContext{
+				pathFromSource:       filepath.FromSlash(tc.relPath),
+				destinationDirectory: "dst",
+				info:                 mockFileInfo{name: filepath.Base(tc.relPath)},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join("dst", tc.expected), path)
+		})
+	}
+}
+
+func TestFlattenStrategy_MaxLength(t *testing.T) {
+	s := &FlattenStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"encode_path": true, "max_length": 40}))
+
+	flatten := func(relPath string) string {
+		path, err := s.FinalDirPath(&mockContext{
+			pathFromSource:       filepath.FromSlash(relPath),
+			destinationDirectory: "dst",
+			info:                 mockFileInfo{name: filepath.Base(relPath)},
+		})
+		require.NoError(t, err)
+		return filepath.Base(path)
+	}
+
+	first := flatten("clientA/projets été 2023/rapport annuel/final.pdf")
+	second := flatten("clientA/projets été 2023/rapport annuel/draft.pdf")
+	for _, name := range []string{first, second} {
+		assert.LessOrEqual(t, len(name), 40, name)
+		assert.True(t, utf8.ValidString(name), name)
+		assert.True(t, strings.HasPrefix(name, "clientA__projets"), name)
+		assert.True(t, strings.HasSuffix(name, ".pdf"), name)
+	}
+	assert.NotEqual(t, first, second)
+}
+
+func TestTruncateName(t *testing.T) {
+	assert.Equal(t, "short.txt", truncateName("short.txt", 32))
+
+	// Extensions longer than maxExtensionLength are not kept
+	name := truncateName(strings.Repeat("a", 40)+".thisisnotanextension", 32)
+	assert.Len(t, name, 32)
+	assert.False(t, strings.HasSuffix(name, ".thisisnotanextension"))
+
+	// Multibyte runes are not split
+	name = truncateName(strings.Repeat("é", 40)+".txt", 32)
+	assert.True(t, utf8.ValidString(name))
+	assert.LessOrEqual(t, len(name), 32)
+}
+
+func TestFlattenStrategy_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"separator": "/"},
+		{"separator": "a\\b"},
+		{"max_length": 10},
+		{"max_length": -1},
+		{"depth": 1},
+	}
+	for _, config := range invalid {
+		s := &FlattenStrategy{}
+		assert.Error(t, s.LoadConfig(config), "config %v", config)
+	}
+}
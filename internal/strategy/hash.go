@@ -0,0 +1,134 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package strategy
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/polocto/FolderFlow/pkg/ffplugin/strategy"
+)
+
+const (
+	defaultHashDepth = 2
+	defaultHashWidth = 2
+)
+
+// hashBase32 is lowercase so that paths do not depend on case sensitivity.
+var hashBase32 = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// HashStrategy lays files out by the SHA-256 of their content, as in
+// "ab/cd/abcdef....jpg". Identical files always get the same path.
+type HashStrategy struct {
+	// Depth is the number of fan-out directories, Width the characters of each
+	Depth         *int   `yaml:"depth"`
+	Width         *int   `yaml:"width"`
+	Encoding      string `yaml:"encoding"`
+	KeepExtension *bool  `yaml:"keep_extension"`
+}
+
+func (s *HashStrategy) Selector() string {
+	return "hash"
+}
+
+func (s *HashStrategy) LoadConfig(config map[string]interface{}) error {
+	var cfg HashStrategy
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	switch cfg.Encoding = strings.ToLower(cfg.Encoding); cfg.Encoding {
+	case "":
+		cfg.Encoding = "hex"
+	case "hex", "base32":
+	default:
+		return fmt.Errorf("invalid encoding %q, must be hex or base32", cfg.Encoding)
+	}
+	if cfg.Depth != nil && *cfg.Depth < 0 {
+		return fmt.Errorf("'depth' cannot be negative")
+	}
+	if cfg.Width != nil && *cfg.Width < 1 {
+		return fmt.Errorf("'width' must be at least 1")
+	}
+	// Fan-out directories are taken from the beginning of the digest
+	if length := len(cfg.encode(make([]byte, sha256.Size))); cfg.depth()*cfg.width() > length {
+		return fmt.Errorf("'depth' times 'width' cannot exceed %d with %s encoding", length, cfg.Encoding)
+	}
+
+	*s = cfg
+
+	slog.Debug("Loading hash was successful", "config", config)
+	return nil
+}
+
+func (s *HashStrategy) depth() int {
+	if s.Depth == nil {
+		return defaultHashDepth
+	}
+	return *s.Depth
+}
+
+func (s *HashStrategy) width() int {
+	if s.Width == nil {
+		return defaultHashWidth
+	}
+	return *s.Width
+}
+
+func (s *HashStrategy) keepExtension() bool {
+	return s.KeepExtension == nil || *s.KeepExtension
+}
+
+func (s *HashStrategy) encode(sum []byte) string {
+	if s.Encoding == "base32" {
+		return hashBase32.EncodeToString(sum)
+	}
+	return hex.EncodeToString(sum)
+}
+
+func (s *HashStrategy) FinalDirPath(ctx strategy.Context) (string, error) {
+	if ctx.Info().IsDir() {
+		return "", fmt.Errorf("filePath %s is a directory, expected a file", ctx.PathFromSource())
+	}
+
+	sum, err := ctx.Hash()
+	if err != nil {
+		return "", fmt.Errorf("cannot hash %q: %w", ctx.PathFromSource(), err)
+	}
+	digest := s.encode(sum[:])
+
+	segments := []string{ctx.DstDir()}
+	for i := 0; i < s.depth(); i++ {
+		segments = append(segments, digest[i*s.width():(i+1)*s.width()])
+	}
+
+	name := digest
+	if s.keepExtension() {
+		// Lowercase so that "a.JPG" and "b.jpg" with the same content share a path
+		name += strings.ToLower(filepath.Ext(inputName(ctx)))
+	}
+	return filepath.Join(append(segments, name)...), nil
+}
+
+func init() {
+	strategy.RegisterStrategy("hash", func() strategy.Strategy {
+		slog.Debug("Create a strategy", "name", "hash")
+		return &HashStrategy{}
+	})
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package strategy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashStrategy_FinalDirPath(t *testing.T) {
+	content := []byte("holiday photo")
+	sum := sha256.Sum256(content)
+	digest := hex.EncodeToString(sum[:])
+	b32 := hashBase32.EncodeToString(sum[:])
+
+	testCases := []struct {
+		name     string
+		config   map[string]interface{}
+		fileName string
+		expected string
+	}{
+		{"default", map[string]interface{}{}, "IMG_0001.JPG", filepath.Join(digest[:2], digest[2:4], digest+".jpg")},
+		{"depth and width", map[string]interface{}{"depth": 1, "width": 3}, "a.jpg", filepath.Join(digest[:3], digest+".jpg")},
+		{"flat", map[string]interface{}{"depth": 0}, "a.jpg", digest + ".jpg"},
+		{"no extension", map[string]interface{}{"keep_extension": false}, "a.jpg", filepath.Join(digest[:2], digest[2:4], digest)},
+		{"file without extension", map[string]interface{}{}, "Makefile", filepath.Join(digest[:2], digest[2:4], digest)},
+		{"base32", map[string]interface{}{"encoding": "base32"}, "a.jpg", filepath.Join(b32[:2], b32[2:4], b32+".jpg")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &HashStrategy{}
+			require.NoError(t, s.LoadConfig(tc.config))
+
+			path, err := s.FinalDirPath(&mockContext{
+				pathFromSource:       filepath.Join("a", tc.fileName),
+				destinationDirectory: "vault",
+				info:                 mockFileInfo{name: tc.fileName},
+				content:              content,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join("vault", tc.expected), path)
+		})
+	}
+}
+
+func TestHashStrategy_SameContentSamePath(t *testing.T) {
+	s := &HashStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{}))
+
+	first, err := s.FinalDirPath(&mockContext{
+		pathFromSource: "x.png", destinationDirectory: "vault", info: mockFileInfo{name: "x.png"}, content: []byte("same"),
+	})
+	require.NoError(t, err)
+	second, err := s.FinalDirPath(&mockContext{
+		pathFromSource: "y.PNG", destinationDirectory: "vault", info: mockFileInfo{name: "y.PNG"}, content: []byte("same"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+}
+
+func TestHashStrategy_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"depth": -1},
+		{"width": 0},
+		{"depth": 33, "width": 2},
+		{"encoding": "base32", "depth": 27, "width": 2},
+		{"encoding": "base64"},
+		{"fanout": 2},
+	}
+	for _, config := range invalid {
+		s := &HashStrategy{}
+		assert.Error(t, s.LoadConfig(config), "config %v", config)
+	}
+}
@@ -14,10 +14,16 @@
 package strategy
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"io"
 	"io/fs"
+	"path/filepath"
+	"testing"
 	"time"
 
 	"github.com/polocto/FolderFlow/pkg/ffplugin/strategy"
+	"github.com/stretchr/testify/require"
 )
 
 type mockStrategy struct {
@@ -64,6 +70,10 @@ type mockContext struct {
 	pathFromSource       string
 	destinationDirectory string
 	info                 fs.FileInfo
+	content              []byte
+	birthTime            time.Time
+	attributes           map[string]string
+	candidate            *string
 }
 
 func (mc *mockContext) PathFromSource() string {
@@ -77,3 +87,44 @@ func (mc *mockContext) DstDir() string {
 func (mc *mockContext) Info() fs.FileInfo {
 	return mc.info
 }
+
+func (mc *mockContext) Hash() ([sha256.Size]byte, error) {
+	return sha256.Sum256(mc.content), nil
+}
+
+func (mc *mockContext) WithInput(fn func(r io.Reader) error) error {
+	return fn(bytes.NewReader(mc.content))
+}
+
+func (mc *mockContext) WithInputLimited(maxBytes int64, fn func(r io.Reader) error) error {
+	return fn(io.LimitReader(bytes.NewReader(mc.content), maxBytes))
+}
+
+func (mc *mockContext) BirthTime() (time.Time, bool) {
+	return mc.birthTime, !mc.birthTime.IsZero()
+}
+
+func (mc *mockContext) Candidate() (string, bool) {
+	if mc.candidate == nil {
+		return "", false
+	}
+	return *mc.candidate, true
+}
+
+func (mc *mockContext) Attribute(name string) (string, bool) {
+	value, ok := mc.attributes[name]
+	return value, ok
+}
+
+// relFinalPath returns the path chosen by s for ctx, relative to its
+// destination directory and with forward slashes.
+func relFinalPath(t *testing.T, s strategy.Strategy, ctx *mockContext) (string, error) {
+	t.Helper()
+	path, err := s.FinalDirPath(ctx)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(ctx.destinationDirectory, path)
+	require.NoError(t, err)
+	return filepath.ToSlash(rel), nil
+}
@@ -0,0 +1,415 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package strategy
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/polocto/FolderFlow/pkg/ffplugin/strategy"
+	"gopkg.in/yaml.v3"
+)
+
+// lookupCheckInterval limits how often the table file is checked for changes.
+const lookupCheckInterval = 2 * time.Second
+
+// LookupStrategy places files in the directory a CSV or YAML table maps
+// their name or path to, as in "ACME" to "Clients/ACME/Invoices".
+type LookupStrategy struct {
+	Table string `yaml:"table"`
+	// Format is csv or yaml, guessed from the extension of Table by default
+	Format string `yaml:"format"`
+	// Match is exact (the default), prefix or regex
+	Match string `yaml:"match"`
+	// Against is name (the default), stem or path, the path relative to the source directory
+	Against    string `yaml:"against"`
+	IgnoreCase bool   `yaml:"ignore_case"`
+	Default    string `yaml:"default"`
+	// CSV options
+	Delimiter  string `yaml:"delimiter"`
+	Header     bool   `yaml:"header"`
+	KeyColumn  string `yaml:"key_column"`
+	PathColumn string `yaml:"path_column"`
+
+	delimiter rune
+	state     *lookupState
+}
+
+// lookupEntry is a row of the table, in file order.
+type lookupEntry struct {
+	key string
+	re  *regexp.Regexp
+	dir string
+}
+
+type lookupTable struct {
+	exact   map[string]string
+	entries []lookupEntry
+}
+
+// lookupState is shared by the workers classifying files concurrently.
+type lookupState struct {
+	mu        sync.RWMutex
+	table     *lookupTable
+	modTime   time.Time
+	size      int64
+	lastCheck time.Time
+}
+
+func (s *LookupStrategy) Selector() string {
+	return "lookup"
+}
+
+func (s *LookupStrategy) LoadConfig(config map[string]interface{}) error {
+	var cfg LookupStrategy
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	if cfg.Table == "" {
+		return fmt.Errorf("'table' config cannot be empty")
+	}
+	if cfg.Format == "" {
+		cfg.Format = strings.TrimPrefix(strings.ToLower(filepath.Ext(cfg.Table)), ".")
+	}
+	switch cfg.Format = strings.ToLower(cfg.Format); cfg.Format {
+	case "csv":
+	case "yaml", "yml":
+		cfg.Format = "yaml"
+	default:
+		return fmt.Errorf("invalid format %q, must be csv or yaml", cfg.Format)
+	}
+	switch cfg.Match = strings.ToLower(cfg.Match); cfg.Match {
+	case "":
+		cfg.Match = "exact"
+	case "exact", "prefix", "regex":
+	default:
+		return fmt.Errorf("invalid match %q, must be exact, prefix or regex", cfg.Match)
+	}
+	switch cfg.Against = strings.ToLower(cfg.Against); cfg.Against {
+	case "":
+		cfg.Against = "name"
+	case "name", "stem", "path":
+	default:
+		return fmt.Errorf("invalid against %q, must be name, stem or path", cfg.Against)
+	}
+	if cfg.Default != "" {
+		if err := checkRelativeDir(cfg.Default); err != nil {
+			return fmt.Errorf("invalid default %q: %w", cfg.Default, err)
+		}
+	}
+
+	if cfg.Format == "yaml" && (cfg.Delimiter != "" || cfg.Header || cfg.KeyColumn != "" || cfg.PathColumn != "") {
+		return fmt.Errorf("'delimiter', 'header', 'key_column' and 'path_column' only apply to csv tables")
+	}
+	cfg.delimiter = ','
+	if cfg.Delimiter != "" {
+		r, size := utf8.DecodeRuneInString(cfg.Delimiter)
+		if size != len(cfg.Delimiter) || r == '"' || r == '\r' || r == '\n' {
+			return fmt.Errorf("invalid delimiter %q, must be a single character", cfg.Delimiter)
+		}
+		cfg.delimiter = r
+	}
+	if (cfg.KeyColumn == "") != (cfg.PathColumn == "") {
+		return fmt.Errorf("'key_column' and 'path_column' must be set together")
+	}
+
+	table, modTime, size, err := cfg.load()
+	if err != nil {
+		return err
+	}
+	cfg.state = &lookupState{table: table, modTime: modTime, size: size, lastCheck: time.Now()}
+
+	*s = cfg
+
+	slog.Debug("Loading lookup was successful", "table", s.Table, "entries", len(table.entries))
+	return nil
+}
+
+// load reads and parses the table file.
+func (s *LookupStrategy) load() (*lookupTable, time.Time, int64, error) {
+	file, err := os.Open(s.Table)
+	if err != nil {
+		return nil, time.Time{}, 0, fmt.Errorf("cannot open lookup table %q: %w", s.Table, err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			slog.Warn("failed to close file : ", "path", s.Table, "err", err)
+		}
+	}()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, time.Time{}, 0, fmt.Errorf("cannot stat lookup table %q: %w", s.Table, err)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, time.Time{}, 0, fmt.Errorf("cannot read lookup table %q: %w", s.Table, err)
+	}
+	// Spreadsheets often save CSV files with a byte order mark
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	var table *lookupTable
+	if s.Format == "csv" {
+		table, err = s.parseCSV(data)
+	} else {
+		table, err = s.parseYAML(data)
+	}
+	if err != nil {
+		return nil, time.Time{}, 0, fmt.Errorf("invalid lookup table %q: %w", s.Table, err)
+	}
+	return table, info.ModTime(), info.Size(), nil
+}
+
+func (s *LookupStrategy) parseCSV(data []byte) (*lookupTable, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = s.delimiter
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	keyCol, pathCol := 0, 1
+	if s.Header || s.KeyColumn != "" {
+		header, err := r.Read()
+		if err != nil {
+			return nil, fmt.Errorf("cannot read header: %w", err)
+		}
+		if s.KeyColumn != "" {
+			if keyCol = columnIndex(header, s.KeyColumn); keyCol < 0 {
+				return nil, fmt.Errorf("column %q not found in header %v", s.KeyColumn, header)
+			}
+			if pathCol = columnIndex(header, s.PathColumn); pathCol < 0 {
+				return nil, fmt.Errorf("column %q not found in header %v", s.PathColumn, header)
+			}
+		}
+	}
+
+	table := newLookupTable()
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := r.FieldPos(0)
+		if keyCol >= len(record) || pathCol >= len(record) {
+			return nil, fmt.Errorf("line %d: expected at least %d columns", line, max(keyCol, pathCol)+1)
+		}
+		if err := s.add(table, record[keyCol], record[pathCol]); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return table, nil
+}
+
+func columnIndex(header []string, name string) int {
+	for i, column := range header {
+		if strings.EqualFold(strings.TrimSpace(column), strings.TrimSpace(name)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseYAML reads a mapping of keys to directories, keeping the file order.
+func (s *LookupStrategy) parseYAML(data []byte) (*lookupTable, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	table := newLookupTable()
+	if len(doc.Content) == 0 {
+		return table, nil
+	}
+	mapping := doc.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected a mapping of keys to directories", mapping.Line)
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		if key.Kind != yaml.ScalarNode || value.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("line %d: keys and directories must be strings", key.Line)
+		}
+		if err := s.add(table, key.Value, value.Value); err != nil {
+			return nil, fmt.Errorf("line %d: %w", key.Line, err)
+		}
+	}
+	return table, nil
+}
+
+func newLookupTable() *lookupTable {
+	return &lookupTable{exact: make(map[string]string)}
+}
+
+func (s *LookupStrategy) add(table *lookupTable, key, dir string) error {
+	key, dir = strings.TrimSpace(key), strings.TrimSpace(dir)
+	if key == "" {
+		return fmt.Errorf("empty key")
+	}
+	if dir == "" {
+		return fmt.Errorf("empty directory for key %q", key)
+	}
+	if err := checkRelativeDir(dir); err != nil {
+		return fmt.Errorf("invalid directory %q for key %q: %w", dir, key, err)
+	}
+
+	entry := lookupEntry{key: key, dir: dir}
+	switch s.Match {
+	case "regex":
+		pattern := key
+		if s.IgnoreCase {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", key, err)
+		}
+		entry.re = re
+	case "exact":
+		if s.IgnoreCase {
+			key = strings.ToLower(key)
+		}
+		if _, ok := table.exact[key]; ok {
+			return fmt.Errorf("duplicate key %q", entry.key)
+		}
+		table.exact[key] = dir
+	default:
+		if s.IgnoreCase {
+			entry.key = strings.ToLower(key)
+		}
+	}
+	table.entries = append(table.entries, entry)
+	return nil
+}
+
+// lookup returns the directory mapped to subject. Prefixes are matched
+// longest first, regular expressions in the order of the table.
+func (s *LookupStrategy) lookup(table *lookupTable, subject string) (string, bool) {
+	if s.IgnoreCase && s.Match != "regex" {
+		subject = strings.ToLower(subject)
+	}
+	switch s.Match {
+	case "exact":
+		dir, ok := table.exact[subject]
+		return dir, ok
+	case "prefix":
+		best := -1
+		for i, entry := range table.entries {
+			if strings.HasPrefix(subject, entry.key) && (best < 0 || len(entry.key) > len(table.entries[best].key)) {
+				best = i
+			}
+		}
+		if best < 0 {
+			return "", false
+		}
+		return table.entries[best].dir, true
+	default:
+		for _, entry := range table.entries {
+			if entry.re.MatchString(subject) {
+				return entry.dir, true
+			}
+		}
+		return "", false
+	}
+}
+
+// reloadIfChanged reloads the table when its file was modified.
+// On failure the previous table is kept, so that a file being saved
+// does not send every file to the default directory.
+func (s *LookupStrategy) reloadIfChanged() {
+	st := s.state
+	st.mu.RLock()
+	recent := time.Since(st.lastCheck) < lookupCheckInterval
+	st.mu.RUnlock()
+	if recent {
+		return
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if time.Since(st.lastCheck) < lookupCheckInterval {
+		return
+	}
+	st.lastCheck = time.Now()
+
+	info, err := os.Stat(s.Table)
+	if err == nil && info.ModTime().Equal(st.modTime) && info.Size() == st.size {
+		return
+	}
+	table, modTime, size, err := s.load()
+	if err != nil {
+		slog.Warn("Cannot reload lookup table, keeping the previous one", "table", s.Table, "err", err)
+		return
+	}
+	st.table, st.modTime, st.size = table, modTime, size
+	slog.Info("Lookup table reloaded", "table", s.Table, "entries", len(table.entries))
+}
+
+func (s *LookupStrategy) FinalDirPath(ctx strategy.Context) (string, error) {
+	if ctx.Info().IsDir() {
+		return "", fmt.Errorf("filePath %s is a directory, expected a file", ctx.PathFromSource())
+	}
+
+	name := inputName(ctx)
+	var subject string
+	switch s.Against {
+	case "path":
+		subject = filepath.ToSlash(inputPath(ctx))
+	case "stem":
+		subject = name
+		// A leading dot starts a hidden file name, not an extension
+		if ext := path.Ext(name); ext != name {
+			subject = strings.TrimSuffix(name, ext)
+		}
+	default:
+		subject = name
+	}
+
+	s.reloadIfChanged()
+	s.state.mu.RLock()
+	table := s.state.table
+	s.state.mu.RUnlock()
+
+	dir, ok := s.lookup(table, subject)
+	if !ok {
+		if s.Default == "" {
+			return "", fmt.Errorf("no entry of %q matches %q", s.Table, subject)
+		}
+		slog.Debug("No lookup entry, using default", "subject", subject, "default", s.Default)
+		dir = s.Default
+	}
+	return filepath.Join(ctx.DstDir(), filepath.FromSlash(dir), name), nil
+}
+
+func init() {
+	strategy.RegisterStrategy("lookup", func() strategy.Strategy {
+		slog.Debug("Create a strategy", "name", "lookup")
+		return &LookupStrategy{}
+	})
+}
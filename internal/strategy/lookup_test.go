@@ -0,0 +1,175 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package strategy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTable(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func lookupPath(t *testing.T, s *LookupStrategy, relPath string) (string, error) {
+	t.Helper()
+	return relFinalPath(t, s, &mockContext{
+		pathFromSource:       filepath.FromSlash(relPath),
+		destinationDirectory: "dst",
+		info:                 mockFileInfo{name: filepath.Base(relPath)},
+	})
+}
+
+func TestLookupStrategy_FinalDirPath(t *testing.T) {
+	csvTable := writeTable(t, "vendors.csv", "\xef\xbb\xbfVendor;Label;Folder\n# comment\nACME;Acme Corp;Clients/ACME\nACME-EU;Acme Europe;Clients/ACME/EU\nGLOBEX;Globex;Clients/Globex\n")
+	yamlTable := writeTable(t, "routes.yaml", "'^INV-\\d+': Invoices\n'(?i)receipt': Receipts\n'.*': Misc\n")
+
+	testCases := []struct {
+		name     string
+		config   map[string]interface{}
+		relPath  string
+		expected string
+	}{
+		{
+			"exact on stem", map[string]interface{}{"against": "stem"},
+			"in/GLOBEX.pdf", "Clients/Globex/GLOBEX.pdf",
+		},
+		{
+			"longest prefix", map[string]interface{}{"match": "prefix"},
+			"ACME-EU-2024-001.pdf", "Clients/ACME/EU/ACME-EU-2024-001.pdf",
+		},
+		{
+			"prefix", map[string]interface{}{"match": "prefix"},
+			"ACME-2024-001.pdf", "Clients/ACME/ACME-2024-001.pdf",
+		},
+		{
+			"prefix ignore case", map[string]interface{}{"match": "prefix", "ignore_case": true},
+			"globex_q1.pdf", "Clients/Globex/globex_q1.pdf",
+		},
+		{
+			"default", map[string]interface{}{"match": "prefix", "default": "Unknown"},
+			"INITECH-1.pdf", "Unknown/INITECH-1.pdf",
+		},
+		{
+			"prefix on path", map[string]interface{}{"match": "prefix", "against": "path"},
+			"GLOBEX/2024/a.pdf", "Clients/Globex/a.pdf",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := map[string]interface{}{
+				"table": csvTable, "delimiter": ";", "key_column": "vendor", "path_column": "FOLDER",
+			}
+			for k, v := range tc.config {
+				config[k] = v
+			}
+			s := &LookupStrategy{}
+			require.NoError(t, s.LoadConfig(config))
+
+			path, err := lookupPath(t, s, tc.relPath)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, path)
+		})
+	}
+
+	t.Run("yaml regex in table order", func(t *testing.T) {
+		s := &LookupStrategy{}
+		require.NoError(t, s.LoadConfig(map[string]interface{}{"table": yamlTable, "match": "regex"}))
+
+		for relPath, expected := range map[string]string{
+			"INV-0012.pdf":         "Invoices/INV-0012.pdf",
+			"INV-receipt.pdf":      "Receipts/INV-receipt.pdf",
+			"Receipt-INV-0012.pdf": "Receipts/Receipt-INV-0012.pdf",
+			"notes.txt":            "Misc/notes.txt",
+		} {
+			path, err := lookupPath(t, s, relPath)
+			require.NoError(t, err)
+			assert.Equal(t, expected, path)
+		}
+	})
+}
+
+func TestLookupStrategy_NoMatch(t *testing.T) {
+	s := &LookupStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"table": writeTable(t, "t.csv", "a.pdf,A\n")}))
+
+	path, err := lookupPath(t, s, "a.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "A/a.pdf", path)
+
+	_, err = lookupPath(t, s, "b.pdf")
+	assert.Error(t, err)
+}
+
+func TestLookupStrategy_Reload(t *testing.T) {
+	table := writeTable(t, "t.yml", "ACME: Clients/ACME\n")
+	s := &LookupStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"table": table, "match": "prefix", "default": "Unknown"}))
+
+	path, err := lookupPath(t, s, "GLOBEX-1.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "Unknown/GLOBEX-1.pdf", path)
+
+	require.NoError(t, os.WriteFile(table, []byte("ACME: Clients/ACME\nGLOBEX: Clients/Globex\n"), 0o644))
+	s.state.lastCheck = time.Time{}
+
+	path, err = lookupPath(t, s, "GLOBEX-1.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "Clients/Globex/GLOBEX-1.pdf", path)
+
+	// A broken table keeps the previous entries
+	require.NoError(t, os.WriteFile(table, []byte("GLOBEX: ../outside\n"), 0o644))
+	s.state.lastCheck = time.Time{}
+
+	path, err = lookupPath(t, s, "GLOBEX-1.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "Clients/Globex/GLOBEX-1.pdf", path)
+}
+
+func TestLookupStrategy_LoadConfigErrors(t *testing.T) {
+	csvTable := writeTable(t, "t.csv", "key,path\nACME,Clients/ACME\n")
+	invalid := []map[string]interface{}{
+		{},
+		{"table": filepath.Join(t.TempDir(), "missing.csv")},
+		{"table": writeTable(t, "t.json", "{}")},
+		{"table": csvTable, "match": "fuzzy"},
+		{"table": csvTable, "against": "content"},
+		{"table": csvTable, "default": "/abs"},
+		{"table": csvTable, "delimiter": ";;"},
+		{"table": csvTable, "key_column": "key"},
+		{"table": csvTable, "key_column": "vendor", "path_column": "path"},
+		{"table": writeTable(t, "t.yaml", "a: b\n"), "header": true},
+		{"table": writeTable(t, "abs.csv", "ACME,/srv/clients\n")},
+		{"table": writeTable(t, "dotdot.csv", "ACME,../clients\n")},
+		{"table": writeTable(t, "empty.csv", "ACME,\n")},
+		{"table": writeTable(t, "short.csv", "ACME\n")},
+		{"table": writeTable(t, "dup.csv", "ACME,a\nacme,b\n"), "ignore_case": true},
+		{"table": writeTable(t, "re.csv", "(,a\n"), "match": "regex"},
+		{"table": writeTable(t, "list.yaml", "- a\n- b\n")},
+		{"table": writeTable(t, "nested.yaml", "a:\n  b: c\n")},
+	}
+	for _, config := range invalid {
+		s := &LookupStrategy{}
+		assert.Error(t, s.LoadConfig(config), "config %v", config)
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package strategy
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/polocto/FolderFlow/internal/metadata"
+	"github.com/polocto/FolderFlow/pkg/ffplugin/strategy"
+)
+
+const (
+	defaultMusicTemplate       = "{artist}/{album} ({year})/{disc}-{track} - {title}{ext}"
+	defaultMusicVariousArtists = "Various Artists"
+	defaultMusicUnknownArtist  = "Unknown Artist"
+	defaultMusicUnknownAlbum   = "Unknown Album"
+)
+
+// musicVariables are the tag values given to the template, taking precedence
+// over the built-in variables: {year} is the year of the album.
+var musicVariables = []string{
+	"artist", "album_artist", "track_artist", "album", "title", "genre", "format",
+	"year", "track", "track_total", "disc", "disc_total",
+}
+
+// MusicStrategy organizes audio files from their tags, as in
+// "Artist/Album (Year)/01 - Title.flac".
+type MusicStrategy struct {
+	Template       string `yaml:"template"`
+	VariousArtists string `yaml:"various_artists"`
+	UnknownArtist  string `yaml:"unknown_artist"`
+	UnknownAlbum   string `yaml:"unknown_album"`
+	// Fallback is the directory of the files that are not audio files
+	Fallback string `yaml:"fallback"`
+	// Sanitize configures how tag values and names are made safe, as the sanitize strategy
+	Sanitize map[string]interface{} `yaml:"sanitize"`
+
+	template   *pathTemplate
+	appendName bool
+	sanitizer  *SanitizeStrategy
+}
+
+func (s *MusicStrategy) Selector() string {
+	return "music"
+}
+
+func (s *MusicStrategy) LoadConfig(config map[string]interface{}) error {
+	var cfg MusicStrategy
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(cfg.Template) == "" {
+		cfg.Template = defaultMusicTemplate
+	}
+	tmpl, err := newPathTemplate(cfg.Template, musicVariables...)
+	if err != nil {
+		return fmt.Errorf("invalid template %q: %w", cfg.Template, err)
+	}
+	// Missing tags do not leave "()" or "- -" behind
+	tmpl.compact = true
+	cfg.template = tmpl
+	cfg.appendName = strings.HasSuffix(cfg.Template, "/")
+
+	for _, name := range []*string{&cfg.VariousArtists, &cfg.UnknownArtist, &cfg.UnknownAlbum} {
+		*name = strings.TrimSpace(*name)
+	}
+	cfg.VariousArtists = cmp.Or(cfg.VariousArtists, defaultMusicVariousArtists)
+	cfg.UnknownArtist = cmp.Or(cfg.UnknownArtist, defaultMusicUnknownArtist)
+	cfg.UnknownAlbum = cmp.Or(cfg.UnknownAlbum, defaultMusicUnknownAlbum)
+
+	if cfg.Fallback != "" {
+		if err := checkRelativeDir(cfg.Fallback); err != nil {
+			return fmt.Errorf("invalid fallback %q: %w", cfg.Fallback, err)
+		}
+	}
+
+	cfg.sanitizer = &SanitizeStrategy{}
+	if err := cfg.sanitizer.LoadConfig(cfg.Sanitize); err != nil {
+		return fmt.Errorf("invalid sanitize config: %w", err)
+	}
+
+	*s = cfg
+
+	slog.Debug("Loading music was successful", "config", config)
+	return nil
+}
+
+func (s *MusicStrategy) FinalDirPath(ctx strategy.Context) (string, error) {
+	if ctx.Info().IsDir() {
+		return "", fmt.Errorf("filePath %s is a directory, expected a file", ctx.PathFromSource())
+	}
+
+	var tags *metadata.AudioTags
+	err := ctx.WithInput(func(r io.Reader) error {
+		var err error
+		tags, err = metadata.ReadAudioTags(r, ctx.Info().Size())
+		return err
+	})
+	if errors.Is(err, metadata.ErrNotAudio) {
+		if s.Fallback == "" {
+			return "", fmt.Errorf("%q is not a supported audio file", ctx.PathFromSource())
+		}
+		slog.Debug("Not an audio file, using fallback", "path", ctx.PathFromSource(), "fallback", s.Fallback)
+		return filepath.Join(ctx.DstDir(), s.Fallback, inputName(ctx)), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot read audio tags of %q: %w", ctx.PathFromSource(), err)
+	}
+
+	rendered, err := s.template.render(ctx, s.values(tags, inputName(ctx)))
+	if err != nil {
+		return "", fmt.Errorf("template %q for %q: %w", s.Template, ctx.PathFromSource(), err)
+	}
+	if s.appendName {
+		rendered = append(rendered, inputName(ctx))
+	}
+
+	segments := []string{ctx.DstDir()}
+	for _, segment := range rendered {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			segments = append(segments, s.sanitizer.sanitize(segment))
+		}
+	}
+	if len(segments) == 1 {
+		return "", fmt.Errorf("template %q for %q: rendered path is empty", s.Template, ctx.PathFromSource())
+	}
+	return filepath.Join(segments...), nil
+}
+
+// values returns the template variables of a file, with the fallbacks of
+// missing tags. Each value is sanitized so that it stays a single segment.
+func (s *MusicStrategy) values(tags *metadata.AudioTags, name string) map[string]string {
+	stem := name
+	if ext := path.Ext(name); ext != name {
+		stem = strings.TrimSuffix(name, ext)
+	}
+
+	artist := tags.AlbumArtist
+	if tags.Compilation || strings.EqualFold(artist, s.VariousArtists) {
+		artist = s.VariousArtists
+	}
+	values := map[string]string{
+		"artist":       cmp.Or(artist, tags.Artist, s.UnknownArtist),
+		"album_artist": tags.AlbumArtist,
+		"track_artist": cmp.Or(tags.Artist, tags.AlbumArtist, s.UnknownArtist),
+		"album":        cmp.Or(tags.Album, s.UnknownAlbum),
+		"title":        cmp.Or(tags.Title, stem),
+		"genre":        tags.Genre,
+		"format":       tags.Format,
+	}
+	if tags.Year > 0 {
+		values["year"] = strconv.Itoa(tags.Year)
+	}
+	if tags.Track > 0 {
+		values["track"] = fmt.Sprintf("%02d", tags.Track)
+	}
+	if tags.TrackTotal > 0 {
+		values["track_total"] = strconv.Itoa(tags.TrackTotal)
+	}
+	// The disc number only matters for albums with several discs
+	if tags.Disc > 1 || tags.DiscTotal > 1 {
+		values["disc"] = strconv.Itoa(tags.Disc)
+		values["disc_total"] = strconv.Itoa(tags.DiscTotal)
+	}
+
+	for key, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			value = s.sanitizer.sanitize(value)
+		}
+		values[key] = value
+	}
+	return values
+}
+
+func init() {
+	strategy.RegisterStrategy("music", func() strategy.Strategy {
+		slog.Debug("Create a strategy", "name", "music")
+		return &MusicStrategy{}
+	})
+}
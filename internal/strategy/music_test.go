@@ -0,0 +1,145 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package strategy
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/polocto/FolderFlow/internal/metadata/metadatatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func musicPath(t *testing.T, s *MusicStrategy, name string, content []byte) (string, error) {
+	t.Helper()
+	return relFinalPath(t, s, &mockContext{
+		pathFromSource:       filepath.Join("inbox", name),
+		destinationDirectory: "dst",
+		info:                 mockFileInfo{name: name, size: int64(len(content)), mode: 0o644},
+		content:              content,
+	})
+}
+
+func TestMusicStrategy_FinalDirPath(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   map[string]interface{}
+		file     string
+		content  []byte
+		expected string
+	}{
+		{
+			"album", nil, "03.flac",
+			metadatatest.FLAC("ARTIST=Daft Punk", "ALBUM=Discovery", "DATE=2001-03-12", "TRACKNUMBER=3", "TITLE=Digital Love"),
+			"Daft Punk/Discovery (2001)/03 - Digital Love.flac",
+		},
+		{
+			"album artist and several discs", nil, "a.flac",
+			metadatatest.FLAC("ARTIST=Jay-Z", "ALBUMARTIST=Linkin Park", "ALBUM=Collision Course", "DATE=2004",
+				"TRACKNUMBER=2/6", "DISCNUMBER=2/2", "TITLE=Numb/Encore"),
+			"Linkin Park/Collision Course (2004)/2-02 - Numb_Encore.flac",
+		},
+		{
+			"compilation", nil, "a.flac",
+			metadatatest.FLAC("ARTIST=Moby", "ALBUM=Now 50", "DATE=2001", "TRACKNUMBER=7", "TITLE=Porcelain", "COMPILATION=1"),
+			"Various Artists/Now 50 (2001)/07 - Porcelain.flac",
+		},
+		{
+			"missing tags", nil, "Track 5.flac", metadatatest.FLAC(),
+			"Unknown Artist/Unknown Album/Track 5.flac",
+		},
+		{
+			"missing year and track", nil, "a.flac", metadatatest.FLAC("ARTIST=AC/DC", "ALBUM=Live", "TITLE=T.N.T."),
+			"AC_DC/Live/T.N.T.flac",
+		},
+		{
+			"custom template", map[string]interface{}{
+				"template":        "{genre|lower|default:misc}/{track_artist} - {title}{ext|upper}",
+				"unknown_artist":  "Anonymous",
+				"various_artists": "VA",
+				"sanitize":        map[string]interface{}{"profile": "posix"},
+			},
+			"a.flac", metadatatest.FLAC("TITLE=Why?"),
+			"misc/Anonymous - Why?.FLAC",
+		},
+		{
+			"keep name", map[string]interface{}{"template": "{artist}/"}, "a.flac", metadatatest.FLAC("ARTIST=Air"),
+			"Air/a.flac",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &MusicStrategy{}
+			require.NoError(t, s.LoadConfig(tc.config))
+
+			path, err := musicPath(t, s, tc.file, tc.content)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, path)
+		})
+	}
+}
+
+func TestMusicStrategy_NotAudio(t *testing.T) {
+	s := &MusicStrategy{}
+	require.NoError(t, s.LoadConfig(nil))
+	_, err := musicPath(t, s, "cover.jpg", []byte("not audio at all"))
+	assert.Error(t, err)
+
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"fallback": "Unsorted"}))
+	path, err := musicPath(t, s, "cover.jpg", []byte("not audio at all"))
+	require.NoError(t, err)
+	assert.Equal(t, "Unsorted/cover.jpg", path)
+}
+
+func TestMusicStrategy_MissingTagSeparators(t *testing.T) {
+	testCases := []struct {
+		name     string
+		comments []string
+		expected string
+	}{
+		{"no disc", []string{"ALBUM=Live", "DATE=1999", "TRACKNUMBER=3", "TITLE=Intro"}, "Live (1999)/03 - Intro.flac"},
+		{"no track", []string{"ALBUM=Live", "DISCNUMBER=2/2", "TITLE=Intro"}, "Live/2 - Intro.flac"},
+		{"no disc nor track", []string{"ALBUM=Live", "TITLE=Intro"}, "Live/Intro.flac"},
+		// Only the separators of the template are removed, never tag values
+		{"dashes in title", []string{"ALBUM=Live", "TITLE=Intro - - Outro"}, "Live/Intro - - Outro.flac"},
+		{"brackets in title", []string{"ALBUM=Live", "TRACKNUMBER=1", "TITLE=Song ()"}, "Live/01 - Song ().flac"},
+		{"brackets in album", []string{"ALBUM=Remixes [ ]", "TITLE=Intro"}, "Remixes [ ]/Intro.flac"},
+	}
+
+	s := &MusicStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"template": "{album} ({year})/{disc}-{track} - {title}{ext}"}))
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path, err := musicPath(t, s, "a.flac", metadatatest.FLAC(tc.comments...))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, path)
+		})
+	}
+}
+
+func TestMusicStrategy_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"template": "/abs/{title}"},
+		{"template": "{composer}"},
+		{"fallback": "../out"},
+		{"sanitize": map[string]interface{}{"profile": "fat"}},
+		{"layout": "x"},
+	}
+	for _, config := range invalid {
+		s := &MusicStrategy{}
+		assert.Error(t, s.LoadConfig(config), "config %v", config)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package strategy
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/polocto/FolderFlow/pkg/ffplugin/strategy"
+)
+
+// RegexStrategy routes files on the named groups a regular expression
+// captures in their name or relative path, as in "INV-ACME-2024-0012.pdf"
+// going to "ACME/2024/".
+type RegexStrategy struct {
+	Pattern string `yaml:"pattern"`
+	// Match is "name" (the default) or "path", the path relative to the source directory
+	Match       string `yaml:"match"`
+	Destination string `yaml:"destination"`
+	Fallback    string `yaml:"fallback"`
+
+	re          *regexp.Regexp
+	destination *pathTemplate
+}
+
+func (s *RegexStrategy) Selector() string {
+	return "regex"
+}
+
+func (s *RegexStrategy) LoadConfig(config map[string]interface{}) error {
+	var cfg RegexStrategy
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	if cfg.Pattern == "" {
+		return fmt.Errorf("'pattern' config cannot be empty")
+	}
+	re, err := regexp.Compile(cfg.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", cfg.Pattern, err)
+	}
+	cfg.re = re
+
+	switch cfg.Match = strings.ToLower(cfg.Match); cfg.Match {
+	case "":
+		cfg.Match = "name"
+	case "name", "path":
+	default:
+		return fmt.Errorf("invalid match %q, must be name or path", cfg.Match)
+	}
+
+	if strings.TrimSpace(cfg.Destination) == "" {
+		return fmt.Errorf("'destination' config cannot be empty")
+	}
+	var groups []string
+	for _, name := range re.SubexpNames() {
+		if name != "" {
+			groups = append(groups, name)
+		}
+	}
+	tmpl, err := newPathTemplate(cfg.Destination, groups...)
+	if err != nil {
+		return fmt.Errorf("invalid destination %q: %w", cfg.Destination, err)
+	}
+	cfg.destination = tmpl
+
+	if cfg.Fallback != "" {
+		if err := checkRelativeDir(cfg.Fallback); err != nil {
+			return fmt.Errorf("invalid fallback %q: %w", cfg.Fallback, err)
+		}
+	}
+
+	*s = cfg
+
+	slog.Debug("Loading regex was successful", "config", config)
+	return nil
+}
+
+func (s *RegexStrategy) FinalDirPath(ctx strategy.Context) (string, error) {
+	if ctx.Info().IsDir() {
+		return "", fmt.Errorf("filePath %s is a directory, expected a file", ctx.PathFromSource())
+	}
+
+	subject := inputName(ctx)
+	if s.Match == "path" {
+		subject = filepath.ToSlash(inputPath(ctx))
+	}
+
+	m := s.re.FindStringSubmatch(subject)
+	if m == nil {
+		if s.Fallback == "" {
+			return "", fmt.Errorf("pattern %q does not match %q", s.Pattern, subject)
+		}
+		slog.Debug("Pattern does not match, using fallback", "path", ctx.PathFromSource(), "fallback", s.Fallback)
+		return filepath.Join(ctx.DstDir(), s.Fallback, inputName(ctx)), nil
+	}
+
+	groups := make(map[string]string)
+	for i, name := range s.re.SubexpNames() {
+		// With duplicate names, the first group that participated wins
+		if _, ok := groups[name]; name != "" && (!ok || groups[name] == "") {
+			groups[name] = m[i]
+		}
+	}
+
+	segments, err := s.destination.render(ctx, groups)
+	if err != nil {
+		return "", fmt.Errorf("destination %q for %q: %w", s.Destination, ctx.PathFromSource(), err)
+	}
+	segments = append(append([]string{ctx.DstDir()}, segments...), inputName(ctx))
+	return filepath.Join(segments...), nil
+}
+
+func init() {
+	strategy.RegisterStrategy("regex", func() strategy.Strategy {
+		slog.Debug("Create a strategy", "name", "regex")
+		return &RegexStrategy{}
+	})
+}
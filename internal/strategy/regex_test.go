@@ -0,0 +1,115 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package strategy
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegexStrategy_FinalDirPath(t *testing.T) {
+	invoice := `^(?P<kind>[A-Z]+)-(?P<client>[A-Za-z]+)-(?P<year>\d{4})-\d+`
+	modTime := time.Date(2025, time.January, 5, 0, 0, 0, 0, time.Local)
+
+	testCases := []struct {
+		name     string
+		config   map[string]interface{}
+		relPath  string
+		expected string
+	}{
+		{
+			"groups", map[string]interface{}{"pattern": invoice, "destination": "{client}/{year}"},
+			"inbox/INV-ACME-2024-0012.pdf", "ACME/2024/INV-ACME-2024-0012.pdf",
+		},
+		{
+			"filters and builtin variables",
+			map[string]interface{}{"pattern": invoice, "destination": "{kind|lower}/{client|lower}/{ext|lower}"},
+			"INV-ACME-2024-0012.PDF", "inv/acme/.pdf/INV-ACME-2024-0012.PDF",
+		},
+		{
+			"groups take precedence", map[string]interface{}{"pattern": invoice, "destination": "{year}"},
+			"INV-ACME-2024-0012.pdf", "2024/INV-ACME-2024-0012.pdf",
+		},
+		{
+			"builtin year without group", map[string]interface{}{"pattern": `^(?P<client>\w+)_`, "destination": "{client}/{year}"},
+			"acme_report.pdf", "acme/2025/acme_report.pdf",
+		},
+		{
+			"match path",
+			map[string]interface{}{"pattern": `^clients/(?P<client>[^/]+)/`, "match": "path", "destination": "{client}"},
+			"clients/acme/2024/report.pdf", "acme/report.pdf",
+		},
+		{
+			"optional group", map[string]interface{}{"pattern": `^(?P<client>[a-z]+)(?:-(?P<project>[a-z]+))?\.`, "destination": "{client}/{project}"},
+			"acme.pdf", "acme/acme.pdf",
+		},
+		{
+			"fallback", map[string]interface{}{"pattern": invoice, "destination": "{client}", "fallback": "unsorted"},
+			"notes.txt", "unsorted/notes.txt",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &RegexStrategy{}
+			require.NoError(t, s.LoadConfig(tc.config))
+
+			path, err := s.FinalDirPath(&mockContext{
+				pathFromSource:       filepath.FromSlash(tc.relPath),
+				destinationDirectory: "dst",
+				info:                 mockFileInfo{name: filepath.Base(tc.relPath), modTime: modTime},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join("dst", filepath.FromSlash(tc.expected)), path)
+		})
+	}
+}
+
+func TestRegexStrategy_Errors(t *testing.T) {
+	s := &RegexStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"pattern": `^(?P<client>\w+)-`, "destination": "{client}"}))
+
+	// No match and no fallback
+	_, err := s.FinalDirPath(&mockContext{pathFromSource: "notes.txt", destinationDirectory: "dst", info: mockFileInfo{name: "notes.txt"}})
+	assert.Error(t, err)
+
+	// A capture cannot leave the destination directory
+	require.NoError(t, s.LoadConfig(map[string]interface{}{
+		"pattern": `^(?P<up>[^/]+)/`, "match": "path", "destination": "{up}",
+	}))
+	_, err = s.FinalDirPath(&mockContext{
+		pathFromSource: filepath.Join("..", "a.txt"), destinationDirectory: "dst", info: mockFileInfo{name: "a.txt"},
+	})
+	assert.Error(t, err)
+}
+
+func TestRegexStrategy_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"destination": "{year}"},
+		{"pattern": "(", "destination": "{year}"},
+		{"pattern": "(?P<client>a)"},
+		{"pattern": "(?P<client>a)", "destination": "{project}"},
+		{"pattern": "(?P<client>a)", "destination": "/{client}"},
+		{"pattern": "(?P<client>a)", "destination": "{client}", "match": "extension"},
+		{"pattern": "(?P<client>a)", "destination": "{client}", "fallback": "../unsorted"},
+	}
+	for _, config := range invalid {
+		s := &RegexStrategy{}
+		assert.Error(t, s.LoadConfig(config), "config %v", config)
+	}
+}
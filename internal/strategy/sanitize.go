@@ -0,0 +1,277 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package strategy
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/polocto/FolderFlow/pkg/ffplugin/strategy"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	defaultSanitizeProfile     = "windows"
+	defaultSanitizeReplacement = "_"
+)
+
+// SanitizeStrategy rewrites every segment of the path so that it can be
+// created on the filesystems of a profile, keeping the directories. Used as
+// a step of a chain, it cleans the names produced by the previous steps.
+type SanitizeStrategy struct {
+	// Profile is posix, windows (the default) or exfat
+	Profile string `yaml:"profile"`
+	// Normalize is the Unicode normalization form: nfc (the default), nfd or none
+	Normalize string            `yaml:"normalize"`
+	Replace   map[string]string `yaml:"replace"`
+	// Replacement replaces the characters the profile forbids
+	Replacement *string `yaml:"replacement"`
+	// Case is lower, upper or empty to keep the case
+	Case               string `yaml:"case"`
+	CollapseWhitespace *bool  `yaml:"collapse_whitespace"`
+	RemoveEmoji        bool   `yaml:"remove_emoji"`
+	MaxBytes           int    `yaml:"max_bytes"`
+
+	profile  sanitizeProfile
+	replacer *strings.Replacer
+}
+
+// sanitizeProfile describes what the filesystems of a profile reject.
+type sanitizeProfile struct {
+	// forbidden characters, in addition to control characters when control is set
+	forbidden string
+	control   bool
+	// trimTrailing removes the trailing dots and spaces Windows drops silently
+	trimTrailing bool
+	// reserved device names such as CON or LPT1, with or without extension
+	reserved bool
+	maxBytes int
+}
+
+var sanitizeProfiles = map[string]sanitizeProfile{
+	"posix":   {forbidden: "/\x00", maxBytes: defaultMaxNameLength},
+	"windows": {forbidden: `<>:"/\|?*`, control: true, trimTrailing: true, reserved: true, maxBytes: defaultMaxNameLength},
+	// exFAT has the character rules of Windows, device names are only reserved by Windows itself
+	"exfat": {forbidden: `<>:"/\|?*`, control: true, trimTrailing: true, maxBytes: defaultMaxNameLength},
+}
+
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+func (s *SanitizeStrategy) Selector() string {
+	return "sanitize"
+}
+
+func (s *SanitizeStrategy) LoadConfig(config map[string]interface{}) error {
+	var cfg SanitizeStrategy
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	if cfg.Profile = strings.ToLower(cfg.Profile); cfg.Profile == "" {
+		cfg.Profile = defaultSanitizeProfile
+	}
+	profile, ok := sanitizeProfiles[cfg.Profile]
+	if !ok {
+		return fmt.Errorf("invalid profile %q, must be posix, windows or exfat", cfg.Profile)
+	}
+	cfg.profile = profile
+
+	switch cfg.Normalize = strings.ToLower(cfg.Normalize); cfg.Normalize {
+	case "":
+		cfg.Normalize = "nfc"
+	case "nfc", "nfd", "none":
+	default:
+		return fmt.Errorf("invalid normalize %q, must be nfc, nfd or none", cfg.Normalize)
+	}
+
+	switch cfg.Case = strings.ToLower(cfg.Case); cfg.Case {
+	case "", "lower", "upper":
+	default:
+		return fmt.Errorf("invalid case %q, must be lower or upper", cfg.Case)
+	}
+
+	if cfg.Replacement == nil {
+		replacement := defaultSanitizeReplacement
+		cfg.Replacement = &replacement
+	}
+	if cfg.profile.rejects(*cfg.Replacement) {
+		return fmt.Errorf("invalid replacement %q: contains characters forbidden by profile %q", *cfg.Replacement, cfg.Profile)
+	}
+
+	// Longest keys first, so that "..." is replaced before "."
+	keys := make([]string, 0, len(cfg.Replace))
+	for key, value := range cfg.Replace {
+		if key == "" {
+			return fmt.Errorf("replace keys cannot be empty")
+		}
+		if strings.ContainsAny(value, "/\\\x00") {
+			return fmt.Errorf("invalid replacement %q for %q: cannot contain path separators", value, key)
+		}
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(b), len(a)), strings.Compare(a, b))
+	})
+	var pairs []string
+	for _, key := range keys {
+		pairs = append(pairs, cfg.normalize(key), cfg.Replace[key])
+	}
+	if len(pairs) > 0 {
+		cfg.replacer = strings.NewReplacer(pairs...)
+	}
+
+	switch {
+	case cfg.MaxBytes == 0:
+		cfg.MaxBytes = cfg.profile.maxBytes
+	case cfg.MaxBytes < minMaxNameLength:
+		return fmt.Errorf("'max_bytes' must be at least %d", minMaxNameLength)
+	}
+
+	*s = cfg
+
+	slog.Debug("Loading sanitize was successful", "config", config)
+	return nil
+}
+
+// rejects reports whether s contains a character forbidden by the profile.
+func (p sanitizeProfile) rejects(s string) bool {
+	return strings.IndexFunc(s, p.isForbidden) >= 0
+}
+
+func (p sanitizeProfile) isForbidden(r rune) bool {
+	return strings.ContainsRune(p.forbidden, r) || (p.control && r < 0x20)
+}
+
+func (s *SanitizeStrategy) normalize(name string) string {
+	switch s.Normalize {
+	case "nfc":
+		return norm.NFC.String(name)
+	case "nfd":
+		return norm.NFD.String(name)
+	default:
+		return name
+	}
+}
+
+func (s *SanitizeStrategy) collapseWhitespace() bool {
+	return s.CollapseWhitespace == nil || *s.CollapseWhitespace
+}
+
+// sanitize rewrites one segment of the path.
+func (s *SanitizeStrategy) sanitize(name string) string {
+	name = s.normalize(name)
+	if s.RemoveEmoji {
+		name = strings.Map(func(r rune) rune {
+			if isEmoji(r) {
+				return -1
+			}
+			return r
+		}, name)
+	}
+	if s.collapseWhitespace() {
+		name = strings.Join(strings.FieldsFunc(name, unicode.IsSpace), " ")
+		// "report .pdf" becomes "report.pdf"
+		if ext := path.Ext(name); ext != name {
+			name = strings.TrimRight(strings.TrimSuffix(name, ext), " ") + ext
+		}
+	}
+	if s.replacer != nil {
+		name = s.replacer.Replace(name)
+	}
+
+	var b strings.Builder
+	for _, r := range name {
+		if s.profile.isForbidden(r) {
+			b.WriteString(*s.Replacement)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	name = b.String()
+
+	switch s.Case {
+	case "lower":
+		name = strings.ToLower(name)
+	case "upper":
+		name = strings.ToUpper(name)
+	}
+	if s.profile.trimTrailing {
+		name = strings.TrimRight(name, ". ")
+	}
+	if name == "" || name == "." || name == ".." {
+		name = defaultSanitizeReplacement
+	}
+	if s.profile.reserved {
+		// "CON.txt" is as reserved as "CON"
+		stem, ext, hasExt := strings.Cut(name, ".")
+		if windowsReservedNames[strings.ToUpper(strings.TrimRight(stem, " "))] {
+			name = stem + defaultSanitizeReplacement
+			if hasExt {
+				name += "." + ext
+			}
+		}
+	}
+	return truncateName(name, s.MaxBytes)
+}
+
+// isEmoji reports whether r is a pictograph or one of the characters
+// composing emoji sequences (joiners, variation selectors, skin tones, flags).
+func isEmoji(r rune) bool {
+	switch {
+	case r == '\u200d', r == '\ufe0e', r == '\ufe0f', r == '\u20e3':
+		return true
+	case r >= 0x2600 && r <= 0x27bf, r >= 0x2b00 && r <= 0x2bff:
+		// Miscellaneous symbols, dingbats, symbols and arrows
+		return true
+	case r >= 0x1f000 && r <= 0x1faff, r >= 0xe0020 && r <= 0xe007f:
+		return true
+	}
+	return false
+}
+
+func (s *SanitizeStrategy) FinalDirPath(ctx strategy.Context) (string, error) {
+	if ctx.Info().IsDir() {
+		return "", fmt.Errorf("filePath %s is a directory, expected a file", ctx.PathFromSource())
+	}
+
+	segments := []string{ctx.DstDir()}
+	for _, segment := range strings.Split(filepath.ToSlash(inputPath(ctx)), "/") {
+		if segment != "" && segment != "." && segment != ".." {
+			segments = append(segments, s.sanitize(segment))
+		}
+	}
+	if len(segments) == 1 {
+		return "", fmt.Errorf("path %q has no file name", inputPath(ctx))
+	}
+	return filepath.Join(segments...), nil
+}
+
+func init() {
+	strategy.RegisterStrategy("sanitize", func() strategy.Strategy {
+		slog.Debug("Create a strategy", "name", "sanitize")
+		return &SanitizeStrategy{}
+	})
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package strategy
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeStrategy_FinalDirPath(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   map[string]interface{}
+		relPath  string
+		expected string
+	}{
+		{"nfd to nfc", map[string]interface{}{}, "E\u0301te\u0301/cafe\u0301.txt", filepath.Join("\u00c9t\u00e9", "caf\u00e9.txt")},
+		{"nfd kept", map[string]interface{}{"normalize": "none"}, "cafe\u0301.txt", "cafe\u0301.txt"},
+		{"windows characters", map[string]interface{}{}, `a/what? "yes": <no>.txt`, filepath.Join("a", "what_ _yes__ _no_.txt")},
+		{"posix characters", map[string]interface{}{"profile": "posix"}, `what? "yes".txt`, `what? "yes".txt`},
+		{"replacement", map[string]interface{}{"replacement": ""}, "a:b?.txt", "ab.txt"},
+		{"replace map", map[string]interface{}{"replace": map[string]interface{}{":": " -", "&": "and", "é": "e"}}, "Tom & Jerry: café.mkv", "Tom and Jerry - cafe.mkv"},
+		{"case", map[string]interface{}{"case": "lower"}, "Photos/IMG_0001.JPG", filepath.Join("photos", "img_0001.jpg")},
+		{"whitespace", map[string]interface{}{}, "  my\t\tfile   name .txt", "my file name.txt"},
+		{"whitespace kept", map[string]interface{}{"collapse_whitespace": false, "profile": "posix"}, "a  b.txt", "a  b.txt"},
+		{"trailing dots", map[string]interface{}{}, "notes. ./draft...", filepath.Join("notes", "draft")},
+		{"trailing dots on posix", map[string]interface{}{"profile": "posix"}, "draft...", "draft..."},
+		{"reserved name", map[string]interface{}{}, "con/CON.txt", filepath.Join("con_", "CON_.txt")},
+		{"reserved with extensions", map[string]interface{}{}, "lpt1.tar.gz", "lpt1_.tar.gz"},
+		{"not reserved", map[string]interface{}{}, "CONSOLE.txt", "CONSOLE.txt"},
+		{"reserved on exfat", map[string]interface{}{"profile": "exfat"}, "AUX.txt", "AUX.txt"},
+		{"emoji", map[string]interface{}{"remove_emoji": true}, "party 🎉🇫🇷 👍🏽 time ❤️.jpg", "party time.jpg"},
+		{"only emoji", map[string]interface{}{"remove_emoji": true}, "🎉/a.txt", filepath.Join("_", "a.txt")},
+		{"control characters", map[string]interface{}{"collapse_whitespace": false}, "a\x01b.txt", "a_b.txt"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &SanitizeStrategy{}
+			require.NoError(t, s.LoadConfig(tc.config))
+
+			path, err := s.FinalDirPath(&mockContext{
+				pathFromSource:       tc.relPath,
+				destinationDirectory: "dst",
+				info:                 mockFileInfo{name: filepath.Base(tc.relPath)},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join("dst", tc.expected), path)
+		})
+	}
+}
+
+func TestSanitizeStrategy_MaxBytes(t *testing.T) {
+	s := &SanitizeStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"max_bytes": 40}))
+
+	name := strings.Repeat("é", 100) + ".flac"
+	path, err := s.FinalDirPath(&mockContext{
+		pathFromSource:       name,
+		destinationDirectory: "dst",
+		info:                 mockFileInfo{name: name},
+	})
+	require.NoError(t, err)
+
+	base := filepath.Base(path)
+	assert.LessOrEqual(t, len(base), 40)
+	assert.True(t, utf8.ValidString(base))
+	assert.True(t, strings.HasSuffix(base, ".flac"), base)
+}
+
+func TestSanitizeStrategy_Candidate(t *testing.T) {
+	s := &SanitizeStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"case": "lower"}))
+
+	candidate := filepath.Join("2024", "03", "Photo: Été?.JPG")
+	path, err := s.FinalDirPath(&mockContext{
+		pathFromSource:       filepath.Join("Camera", "Photo: Été?.JPG"),
+		destinationDirectory: "dst",
+		info:                 mockFileInfo{name: "Photo: Été?.JPG"},
+		candidate:            &candidate,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("dst", "2024", "03", "photo_ été_.jpg"), path)
+}
+
+func TestSanitizeStrategy_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"profile": "fat32"},
+		{"normalize": "nfkc"},
+		{"case": "title"},
+		{"replacement": ":"},
+		{"replacement": "/"},
+		{"replace": map[string]interface{}{"": "x"}},
+		{"replace": map[string]interface{}{"a": "b/c"}},
+		{"max_bytes": 10},
+		{"strip": true},
+	}
+	for _, config := range invalid {
+		s := &SanitizeStrategy{}
+		assert.Error(t, s.LoadConfig(config), "config %v", config)
+	}
+}
@@ -0,0 +1,464 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package strategy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/polocto/FolderFlow/pkg/ffplugin/strategy"
+	"golang.org/x/text/unicode/norm"
+)
+
+// TemplateStrategy builds the destination from a pattern such as
+// "{year}/{month}/{parent}/{stem}_{hash8}{ext}". The pattern always uses '/'
+// as separator; a pattern ending with '/' keeps the original file name.
+type TemplateStrategy struct {
+	Pattern string `yaml:"pattern"`
+
+	template   *pathTemplate
+	appendName bool
+}
+
+// pathTemplate is a parsed pattern, shared by the strategies building paths from variables.
+type pathTemplate struct {
+	parts     []templatePart
+	needsHash bool
+	// compact drops the brackets and separators left around variables without value
+	compact bool
+}
+
+// templatePart is either a literal text or a variable with its filters.
+type templatePart struct {
+	literal  string
+	name     string
+	variable templateVariable
+	// n is the numeric suffix of variables such as hash8 or dir2
+	n       int
+	filters []templateFilter
+}
+
+// templateValues holds what the variables of one file are resolved from.
+type templateValues struct {
+	ctx  strategy.Context
+	dirs []string
+	// groups holds the named groups captured by the regex strategy
+	groups map[string]string
+	name   string
+	stem   string
+	ext    string
+	hash   string
+}
+
+type templateVariable func(v *templateValues, n int) string
+
+type templateFilter func(string) string
+
+var templateVariables = map[string]templateVariable{
+	"name":   func(v *templateValues, _ int) string { return v.name },
+	"stem":   func(v *templateValues, _ int) string { return v.stem },
+	"ext":    func(v *templateValues, _ int) string { return v.ext },
+	"parent": func(v *templateValues, _ int) string { return last(v.dirs) },
+	"dir": func(v *templateValues, n int) string {
+		if n == 0 {
+			return strings.Join(v.dirs, "/")
+		}
+		if n > len(v.dirs) {
+			return ""
+		}
+		return v.dirs[n-1]
+	},
+	"size":    func(v *templateValues, _ int) string { return strconv.FormatInt(v.ctx.Info().Size(), 10) },
+	"year":    func(v *templateValues, _ int) string { return v.ctx.Info().ModTime().Format("2006") },
+	"month":   func(v *templateValues, _ int) string { return v.ctx.Info().ModTime().Format("01") },
+	"day":     func(v *templateValues, _ int) string { return v.ctx.Info().ModTime().Format("02") },
+	"hour":    func(v *templateValues, _ int) string { return v.ctx.Info().ModTime().Format("15") },
+	"minute":  func(v *templateValues, _ int) string { return v.ctx.Info().ModTime().Format("04") },
+	"second":  func(v *templateValues, _ int) string { return v.ctx.Info().ModTime().Format("05") },
+	"weekday": func(v *templateValues, _ int) string { return v.ctx.Info().ModTime().Weekday().String() },
+	"hash": func(v *templateValues, n int) string {
+		if n == 0 {
+			return v.hash
+		}
+		return v.hash[:n]
+	},
+}
+
+// templateAttributePrefix introduces the variables reading the attributes set
+// by filters, as in "{attr.exif.make}".
+const templateAttributePrefix = "attr."
+
+// attributeSeparators keeps attribute values such as "AC/DC" in one segment.
+var attributeSeparators = strings.NewReplacer("/", "-", "\\", "-")
+
+// templateIndexed lists the variables accepting a numeric suffix, with its maximum.
+var templateIndexed = map[string]int{
+	"hash": 2 * sha256.Size,
+	"dir":  1 << 10,
+}
+
+func last(s []string) string {
+	if len(s) == 0 {
+		return ""
+	}
+	return s[len(s)-1]
+}
+
+func (s *TemplateStrategy) Selector() string {
+	return "template"
+}
+
+func (s *TemplateStrategy) LoadConfig(config map[string]interface{}) error {
+	var cfg TemplateStrategy
+	if err := decodeConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(cfg.Pattern) == "" {
+		return fmt.Errorf("'pattern' config cannot be empty")
+	}
+	tmpl, err := newPathTemplate(cfg.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", cfg.Pattern, err)
+	}
+	cfg.template = tmpl
+	cfg.appendName = strings.HasSuffix(cfg.Pattern, "/")
+
+	*s = cfg
+
+	slog.Debug("Loading template was successful", "config", config)
+	return nil
+}
+
+// newPathTemplate parses a pattern relative to the destination directory.
+// groups are the names of the extra variables given to render, which take
+// precedence over the built-in ones.
+func newPathTemplate(pattern string, groups ...string) (*pathTemplate, error) {
+	if err := checkRelativeDir(pattern); err != nil {
+		return nil, err
+	}
+	parts, err := parseTemplate(pattern, groups)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &pathTemplate{parts: parts}
+	for _, part := range parts {
+		t.needsHash = t.needsHash || part.name == "hash"
+	}
+	return t, nil
+}
+
+// parseTemplate splits a pattern into literals and variables.
+// "{{" and "}}" stand for literal braces.
+func parseTemplate(pattern string, groups []string) ([]templatePart, error) {
+	var parts []templatePart
+	var literal strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '{' && strings.HasPrefix(pattern[i:], "{{"), c == '}' && strings.HasPrefix(pattern[i:], "}}"):
+			literal.WriteByte(c)
+			i++
+		case c == '}':
+			return nil, fmt.Errorf("unexpected '}' at offset %d", i)
+		case c == '{':
+			end := strings.IndexAny(pattern[i+1:], "{}")
+			if end < 0 || pattern[i+1+end] != '}' {
+				return nil, fmt.Errorf("unclosed '{' at offset %d", i)
+			}
+			part, err := parseTemplateExpr(pattern[i+1:i+1+end], groups)
+			if err != nil {
+				return nil, err
+			}
+			if literal.Len() > 0 {
+				parts = append(parts, templatePart{literal: literal.String()})
+				literal.Reset()
+			}
+			parts = append(parts, part)
+			i += end + 1
+		default:
+			literal.WriteByte(c)
+		}
+	}
+	if literal.Len() > 0 {
+		parts = append(parts, templatePart{literal: literal.String()})
+	}
+	return parts, nil
+}
+
+// parseTemplateExpr parses "variable|filter|filter:arg".
+func parseTemplateExpr(expr string, groups []string) (templatePart, error) {
+	fields := strings.Split(expr, "|")
+	name := strings.TrimSpace(fields[0])
+
+	part := templatePart{name: name}
+	if slices.Contains(groups, name) {
+		part.name = ""
+		part.variable = func(v *templateValues, _ int) string { return v.groups[name] }
+	} else if attr, ok := strings.CutPrefix(name, templateAttributePrefix); ok {
+		if attr == "" {
+			return part, fmt.Errorf("variable %q lacks an attribute name", name)
+		}
+		part.name = ""
+		part.variable = func(v *templateValues, _ int) string {
+			value, _ := v.ctx.Attribute(attr)
+			// An attribute is a single segment, whatever it contains
+			return attributeSeparators.Replace(value)
+		}
+	} else if v, ok := templateVariables[name]; ok {
+		part.variable = v
+	} else {
+		base := strings.TrimRightFunc(name, unicode.IsDigit)
+		limit, indexed := templateIndexed[base]
+		if !indexed || base == name {
+			return part, fmt.Errorf("unknown variable %q", name)
+		}
+		n, err := strconv.Atoi(name[len(base):])
+		if err != nil || n < 1 || n > limit {
+			return part, fmt.Errorf("invalid variable %q: index must be between 1 and %d", name, limit)
+		}
+		part.name, part.variable, part.n = base, templateVariables[base], n
+	}
+
+	for _, spec := range fields[1:] {
+		f, err := parseTemplateFilter(strings.TrimSpace(spec))
+		if err != nil {
+			return part, err
+		}
+		part.filters = append(part.filters, f)
+	}
+	return part, nil
+}
+
+func parseTemplateFilter(spec string) (templateFilter, error) {
+	name, arg, hasArg := strings.Cut(spec, ":")
+	if hasArg != (name == "truncate" || name == "default") {
+		if hasArg {
+			return nil, fmt.Errorf("filter %q takes no argument", name)
+		}
+		return nil, fmt.Errorf("filter %q requires an argument", name)
+	}
+
+	switch name {
+	case "lower":
+		return strings.ToLower, nil
+	case "upper":
+		return strings.ToUpper, nil
+	case "slug":
+		return slugify, nil
+	case "truncate":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid truncate length %q", arg)
+		}
+		return func(v string) string { return truncateRunes(v, n) }, nil
+	case "default":
+		if arg == "" {
+			return nil, fmt.Errorf("filter \"default\" requires a value")
+		}
+		return func(v string) string {
+			if v == "" {
+				return arg
+			}
+			return v
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown filter %q, must be lower, upper, slug, truncate or default", name)
+	}
+}
+
+// slugify lowercases s, removes accents and replaces runs of other characters by '-'.
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range norm.NFD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Accents of decomposed letters
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			dash = true
+		}
+	}
+	// Recompose the scripts decomposed without marks, such as Hangul
+	return norm.NFC.String(b.String())
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+func (s *TemplateStrategy) FinalDirPath(ctx strategy.Context) (string, error) {
+	if ctx.Info().IsDir() {
+		return "", fmt.Errorf("filePath %s is a directory, expected a file", ctx.PathFromSource())
+	}
+
+	segments, err := s.template.render(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("pattern %q for %q: %w", s.Pattern, ctx.PathFromSource(), err)
+	}
+	if s.appendName {
+		segments = append(segments, inputName(ctx))
+	}
+	if len(segments) == 0 {
+		return "", fmt.Errorf("pattern %q for %q: rendered path is empty", s.Pattern, ctx.PathFromSource())
+	}
+	return filepath.Join(append([]string{ctx.DstDir()}, segments...)...), nil
+}
+
+// render returns the path segments produced for ctx. Empty segments, left by
+// variables without value, are dropped.
+func (t *pathTemplate) render(ctx strategy.Context, groups map[string]string) ([]string, error) {
+	values, err := newTemplateValues(ctx, t.needsHash)
+	if err != nil {
+		return nil, err
+	}
+	values.groups = groups
+
+	texts := make([]string, len(t.parts))
+	for i, part := range t.parts {
+		if part.variable == nil {
+			texts[i] = part.literal
+			continue
+		}
+		value := part.variable(values, part.n)
+		for _, f := range part.filters {
+			value = f(value)
+		}
+		texts[i] = value
+	}
+	if t.compact {
+		compactLiterals(t.parts, texts)
+	}
+
+	var b strings.Builder
+	for _, text := range texts {
+		b.WriteString(text)
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(filepath.ToSlash(b.String()), "/") {
+		switch segment {
+		case "", ".":
+			continue
+		case "..":
+			return nil, fmt.Errorf("rendered path %q contains a '..' segment", b.String())
+		}
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+// templateBrackets are removed around a variable without value, as in "Album ({year})".
+var templateBrackets = [][2]string{{"(", ")"}, {"[", "]"}}
+
+// templateSeparators are the characters of the literals removed next to a
+// variable without value, as in "{disc}-{track}".
+const templateSeparators = " -_.,;:~|+"
+
+// compactLiterals removes the literal text left around the variables rendered
+// empty: "{album} ({year})" gives "Album" and "{disc}-{track} - {title}" gives
+// "03 - Title" without disc number. Rendered values are never changed.
+func compactLiterals(parts []templatePart, texts []string) {
+	isEmptyVariable := func(i int) bool {
+		return i >= 0 && i < len(parts) && parts[i].variable != nil && texts[i] == ""
+	}
+
+	for i := 1; i+1 < len(parts); i++ {
+		if !isEmptyVariable(i) || parts[i-1].variable != nil || parts[i+1].variable != nil {
+			continue
+		}
+		before := strings.TrimRight(texts[i-1], " ")
+		for _, pair := range templateBrackets {
+			if strings.HasSuffix(before, pair[0]) && strings.HasPrefix(texts[i+1], pair[1]) {
+				texts[i-1] = strings.TrimRight(strings.TrimSuffix(before, pair[0]), " ")
+				texts[i+1] = strings.TrimPrefix(texts[i+1], pair[1])
+				break
+			}
+		}
+	}
+
+	// A separator is kept between two values of the same segment only
+	written := false
+	for i, part := range parts {
+		if part.variable != nil {
+			written = written || texts[i] != ""
+			continue
+		}
+		if j := strings.LastIndex(texts[i], "/"); j >= 0 {
+			written = strings.Trim(texts[i][j+1:], templateSeparators) != ""
+			continue
+		}
+		if texts[i] == "" {
+			continue
+		}
+		if strings.Trim(texts[i], templateSeparators) != "" {
+			written = true
+			continue
+		}
+		if (isEmptyVariable(i-1) && !written) || isEmptyVariable(i+1) {
+			texts[i] = ""
+		}
+	}
+}
+
+func newTemplateValues(ctx strategy.Context, withHash bool) (*templateValues, error) {
+	rel := filepath.ToSlash(inputPath(ctx))
+	dir, name := path.Split(rel)
+
+	v := &templateValues{ctx: ctx, name: name, stem: name}
+	for _, segment := range strings.Split(dir, "/") {
+		if segment != "" && segment != "." {
+			v.dirs = append(v.dirs, segment)
+		}
+	}
+	// A leading dot starts a hidden file name, not an extension
+	if ext := path.Ext(name); ext != name {
+		v.stem, v.ext = strings.TrimSuffix(name, ext), ext
+	}
+
+	if withHash {
+		sum, err := ctx.Hash()
+		if err != nil {
+			return nil, fmt.Errorf("cannot hash %q: %w", ctx.PathFromSource(), err)
+		}
+		v.hash = hex.EncodeToString(sum[:])
+	}
+	return v, nil
+}
+
+func init() {
+	strategy.RegisterStrategy("template", func() strategy.Strategy {
+		slog.Debug("Create a strategy", "name", "template")
+		return &TemplateStrategy{}
+	})
+}
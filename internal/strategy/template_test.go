@@ -0,0 +1,136 @@
+// Copyright (c) 2026 Paul Sade.
+//
+// This file is part of the FolderFlow project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License version 3,
+// as published by the Free Software Foundation (see the LICENSE file).
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package strategy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateStrategy_FinalDirPath(t *testing.T) {
+	content := []byte("holiday photo")
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	modTime := time.Date(2024, time.March, 2, 8, 5, 9, 0, time.Local)
+	dst := filepath.Join("srv", "backup")
+
+	testCases := []struct {
+		name     string
+		pattern  string
+		relPath  string
+		expected string
+	}{
+		{
+			"example", "{year}/{month}/{parent}/{stem}_{hash8}{ext}",
+			"Photos/Été 2024/IMG_0001.JPG",
+			filepath.Join("2024", "03", "Été 2024", "IMG_0001_"+hash[:8]+".JPG"),
+		},
+		{"keep name", "{year}-{month}-{day}/", "a/b/report.pdf", filepath.Join("2024-03-02", "report.pdf")},
+		{"time", "{hour}{minute}{second}_{weekday}{ext}", "a.txt", "080509_Saturday.txt"},
+		{"full dir", "{dir}/{name}", "a/b/c/report.pdf", filepath.Join("a", "b", "c", "report.pdf")},
+		{"dir segments", "{dir1}/{dir3}/{name}", "a/b/c/report.pdf", filepath.Join("a", "c", "report.pdf")},
+		{"missing segments dropped", "{parent}/{dir4}/{name}", "report.pdf", "report.pdf"},
+		{"default", "{parent|default:unsorted}/{name}", "report.pdf", filepath.Join("unsorted", "report.pdf")},
+		{"filters", "{parent|slug}/{stem|upper|truncate:3}{ext|lower}", "Été 2024!/report.PDF", filepath.Join("ete-2024", "REP.pdf")},
+		{"size and hash", "{size}/{hash}", "a.txt", filepath.Join("13", hash)},
+		{"hidden file", "{stem}/{ext|default:none}", ".bashrc", filepath.Join(".bashrc", "none")},
+		{"escaped braces", "{{x}}/{name}", "a.txt", filepath.Join("{x}", "a.txt")},
+		{"spaces in expression", "{ stem | upper }{ext}", "a.txt", "A.txt"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &TemplateStrategy{}
+			require.NoError(t, s.LoadConfig(map[string]interface{}{"pattern": tc.pattern}))
+
+			ctx := &mockContext{
+				pathFromSource:       filepath.FromSlash(tc.relPath),
+				destinationDirectory: dst,
+				info:                 mockFileInfo{name: filepath.Base(tc.relPath), size: int64(len(content)), modTime: modTime},
+				content:              content,
+			}
+			path, err := s.FinalDirPath(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(dst, tc.expected), path)
+		})
+	}
+}
+
+func TestTemplateStrategy_Errors(t *testing.T) {
+	s := &TemplateStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"pattern": "{dir}/{name}"}))
+
+	_, err := s.FinalDirPath(&mockContext{
+		pathFromSource:       filepath.Join("..", "outside", "a.txt"),
+		destinationDirectory: "dst",
+		info:                 mockFileInfo{name: "a.txt"},
+	})
+	assert.Error(t, err)
+
+	_, err = s.FinalDirPath(&mockContext{pathFromSource: "dir", destinationDirectory: "dst", info: mockFileInfo{isDir: true}})
+	assert.Error(t, err)
+
+	require.NoError(t, s.LoadConfig(map[string]interface{}{"pattern": "{parent}"}))
+	_, err = s.FinalDirPath(&mockContext{pathFromSource: "a.txt", destinationDirectory: "dst", info: mockFileInfo{name: "a.txt"}})
+	assert.Error(t, err, "empty rendered path")
+}
+
+func TestTemplateStrategy_Attributes(t *testing.T) {
+	s := &TemplateStrategy{}
+	require.NoError(t, s.LoadConfig(map[string]interface{}{
+		"pattern": "{attr.audio_tags.artist|default:Unknown}/{attr.exif.taken.year|default:undated}/{name}",
+	}))
+
+	ctx := &mockContext{
+		pathFromSource:       "song.mp3",
+		destinationDirectory: "dst",
+		info:                 mockFileInfo{name: "song.mp3"},
+		attributes:           map[string]string{"audio_tags.artist": "AC/DC"},
+	}
+	path, err := s.FinalDirPath(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("dst", "AC-DC", "undated", "song.mp3"), path)
+}
+
+func TestTemplateStrategy_LoadConfigErrors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{},
+		{"pattern": ""},
+		{"pattern": "/abs/{name}"},
+		{"pattern": "../{name}"},
+		{"pattern": "{unknown}"},
+		{"pattern": "{name"},
+		{"pattern": "name}"},
+		{"pattern": "{hash0}"},
+		{"pattern": "{hash65}"},
+		{"pattern": "{size2}"},
+		{"pattern": "{name|reverse}"},
+		{"pattern": "{name|truncate}"},
+		{"pattern": "{name|truncate:0}"},
+		{"pattern": "{name|lower:1}"},
+		{"pattern": "{name|default:}"},
+		{"pattern": "{attr.}"},
+		{"pattern": "{name}", "format": "2006"},
+	}
+	for _, config := range invalid {
+		s := &TemplateStrategy{}
+		assert.Error(t, s.LoadConfig(config), "config %v", config)
+	}
+}
@@ -14,6 +14,7 @@
 package filter
 
 import (
+	"crypto/sha256"
 	"io"
 	"io/fs"
 	"time"
@@ -29,4 +30,13 @@ type Context interface {
 	WithInput(fn func(r io.Reader) error) error
 	WithInputLimited(maxBytes int64, fn func(r io.Reader) error) error
 	ReadChunks(chunkSize int, fn func([]byte) error) error
+	// Hash returns the SHA-256 of the file content. It is computed at most
+	// once per file and shared with the rest of the run (duplicates, copies).
+	Hash() ([sha256.Size]byte, error)
+	// Xattr returns the value of an extended attribute such as "user.xdg.tags".
+	// The boolean is false when the attribute is not set.
+	Xattr(name string) ([]byte, bool, error)
+	// SetAttribute attaches a value learned while matching, such as a regex
+	// capture, to the file. Strategies read it through their own context.
+	SetAttribute(name, value string)
 }
@@ -14,11 +14,28 @@
 package strategy
 
 import (
+	"crypto/sha256"
+	"io"
 	"io/fs"
+	"time"
 )
 
 type Context interface {
 	PathFromSource() string
 	DstDir() string
 	Info() fs.FileInfo
+	// Hash returns the SHA-256 of the file, computed at most once per file.
+	Hash() ([sha256.Size]byte, error)
+	// WithInput and WithInputLimited open the file for reading, e.g. to read its metadata.
+	WithInput(fn func(r io.Reader) error) error
+	WithInputLimited(maxBytes int64, fn func(r io.Reader) error) error
+	// BirthTime returns the creation time of the file when the filesystem records it.
+	BirthTime() (time.Time, bool)
+	// Attribute returns a value set by the filters that matched the file,
+	// such as "exif.make" or "regex.artist".
+	Attribute(name string) (string, bool)
+	// Candidate returns the destination computed by the previous step of a
+	// chain, relative to DstDir. Strategies transform it instead of the source
+	// path when it is set; the boolean is false outside a chain.
+	Candidate() (string, bool)
 }